@@ -0,0 +1,17 @@
+package analyzer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Finisher is an optional interface for analyzers that need to know when there are no more interactions to analyze.
+// Monitors use it to decide what to do with a sequence that never saw its terminal interaction, as happens when a test
+// stops polling early (e.g. after a timeout assertion). They may collapse the sequence as if it had completed, or
+// deliberately keep it.
+type Finisher interface {
+	// Finish is called once, after the last interaction, on each analyzer still active at the end of the recording.
+	// Any interactions listed in Excluded are removed, and any in Retained kept. The analyzer is then discarded, so
+	// Finished is implied, and Spawn is ignored as there are no further interactions to analyze.
+	Finish(ctx context.Context, log *slog.Logger) (Result, error)
+}
@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"context"
 	"log/slog"
 
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
@@ -16,5 +17,6 @@ import (
 // possible.
 type Interface interface {
 	// Analyze processes another in a series of interactions.
-	Analyze(log *slog.Logger, i interaction.Interface) (Result, error)
+	// Analyzers doing anything expensive should honour cancellation of ctx, returning its error.
+	Analyze(ctx context.Context, log *slog.Logger, i interaction.Interface) (Result, error)
 }
@@ -0,0 +1,25 @@
+package analyzer
+
+// Provenance records which analyzer excluded (or retained) an interaction, and why.
+type Provenance struct {
+	// Analyzer is the name of the analyzer that excluded the interaction, e.g. "azure.MonitorAzureLongRunningOperation".
+	// If left empty by the analyzer, the cleaner fills it in.
+	Analyzer string
+	// Strategy is the cleaning strategy responsible, e.g. "azure-long-running-operation".
+	// If left empty by the analyzer, the cleaner fills it in from the strategy that introduced the analyzer.
+	Strategy string
+	// URL is the URL being monitored by the analyzer.
+	URL string
+	// Reason is a human-readable explanation of why the interaction was excluded.
+	Reason string
+}
+
+// Because creates a Provenance for exclusions made while monitoring the specified URL.
+// url is the URL being monitored.
+// reason is a human-readable explanation of why interactions are being excluded.
+func Because(url string, reason string) Provenance {
+	return Provenance{
+		URL:    url,
+		Reason: reason,
+	}
+}
@@ -1,6 +1,10 @@
 package analyzer
 
-import "github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+import (
+	"slices"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+)
 
 // Result is returned by an analyzer after processing an interaction.
 type Result struct {
@@ -10,6 +14,14 @@ type Result struct {
 	Spawn []Interface
 	// Excluded lists interactions that should be excluded from the final output.
 	Excluded []interaction.Interface
+	// Retained lists interactions that must be kept in the final output, even if excluded by another analyzer.
+	// Retention takes precedence over exclusion, regardless of the order in which they happen.
+	Retained []interaction.Interface
+	// Provenance explains why the interactions in Excluded were excluded, or those in Retained retained.
+	Provenance Provenance
+	// RetainedProvenance explains why the interactions in Retained were retained, when the result also excludes
+	// interactions for a different reason. If left empty, Provenance is used.
+	RetainedProvenance Provenance
 }
 
 // Spawn creates a Result that spawns one or more new analyzers.
@@ -26,10 +38,51 @@ func Finished() Result {
 	}
 }
 
+// Retain creates a Result protecting one or more interactions from exclusion by any analyzer.
+// provenance explains why the interactions must be kept.
+// retained lists the interactions to keep.
+func Retain(
+	provenance Provenance,
+	retained ...interaction.Interface,
+) Result {
+	return Result{
+		Retained:   retained,
+		Provenance: provenance,
+	}
+}
+
 // FinishedWithExclusions creates a Result indicating the analyzer is finished and listing interactions to exclude.
-func FinishedWithExclusions(excluded ...interaction.Interface) Result {
+// provenance explains why the interactions are being excluded.
+// excluded lists the interactions to exclude.
+func FinishedWithExclusions(
+	provenance Provenance,
+	excluded ...interaction.Interface,
+) Result {
 	return Result{
-		Finished: true,
-		Excluded: excluded,
+		Finished:   true,
+		Excluded:   excluded,
+		Provenance: provenance,
 	}
 }
+
+// AlsoRetain returns a copy of the Result that also protects one or more interactions from exclusion by any analyzer.
+// provenance explains why the interactions must be kept.
+// retained lists the interactions to keep.
+func (r Result) AlsoRetain(
+	provenance Provenance,
+	retained ...interaction.Interface,
+) Result {
+	r.Retained = append(slices.Clone(r.Retained), retained...)
+	r.RetainedProvenance = provenance
+
+	return r
+}
+
+// RetentionProvenance returns the provenance explaining why the interactions in Retained were retained.
+func (r Result) RetentionProvenance() Provenance {
+	if r.RetainedProvenance == (Provenance{}) {
+		return r.Provenance
+	}
+
+	return r.RetainedProvenance
+}
@@ -0,0 +1,57 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/rotisserie/eris"
+)
+
+// defaultPermissions are used when writing a file that doesn't already exist. Cassettes are committed alongside the
+// tests that replay them, so are readable by everyone like any other source file.
+const defaultPermissions = 0o644
+
+// WriteFile writes data to a temporary file alongside path, then renames it into place, so that readers (and anyone
+// interrupting the write) see either the previous content or the new content, never a partially written file.
+// The permissions of any existing file are preserved.
+func WriteFile(path string, data []byte) error {
+	permissions := os.FileMode(defaultPermissions)
+	if info, err := os.Stat(path); err == nil {
+		permissions = info.Mode().Perm()
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return eris.Wrapf(err, "creating temporary file for %s", path)
+	}
+
+	committed := false
+
+	defer func() {
+		if !committed {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+		}
+	}()
+
+	if _, err := file.Write(data); err != nil {
+		return eris.Wrapf(err, "writing %s", file.Name())
+	}
+
+	if err := file.Close(); err != nil {
+		return eris.Wrapf(err, "closing %s", file.Name())
+	}
+
+	//nolint:gosec // New files are readable by everyone, like any other source file
+	if err := os.Chmod(file.Name(), permissions); err != nil {
+		return eris.Wrapf(err, "setting permissions of %s", file.Name())
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return eris.Wrapf(err, "replacing %s", path)
+	}
+
+	committed = true
+
+	return nil
+}
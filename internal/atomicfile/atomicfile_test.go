@@ -0,0 +1,81 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestWriteFile_NewFile_WritesContent(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+
+	err := WriteFile(path, []byte("content"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	content, err := os.ReadFile(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(content)).To(Equal("content"))
+}
+
+func TestWriteFile_NewFile_IsReadableByEveryone(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+
+	err := WriteFile(path, []byte("content"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	info, err := os.Stat(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o644)))
+}
+
+func TestWriteFile_ExistingFile_PreservesPermissions(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	g.Expect(os.WriteFile(path, []byte("original"), 0o640)).To(Succeed())
+
+	err := WriteFile(path, []byte("replaced"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	info, err := os.Stat(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o640)))
+
+	content, err := os.ReadFile(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(content)).To(Equal("replaced"))
+}
+
+func TestWriteFile_Success_LeavesNoTemporaryFiles(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cassette.yaml")
+
+	err := WriteFile(path, []byte("content"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	entries, err := os.ReadDir(dir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(entries).To(HaveLen(1))
+}
+
+func TestWriteFile_MissingDirectory_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "missing", "cassette.yaml")
+
+	err := WriteFile(path, []byte("content"))
+	g.Expect(err).To(HaveOccurred())
+}
@@ -1,6 +1,7 @@
 package azure
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -26,6 +27,7 @@ func NewDetectAzureAsynchronousOperation() *DetectAzureAsynchronousOperation {
 // Analyze processes another interaction in the sequence.
 // interaction is the interaction to analyze.
 func (*DetectAzureAsynchronousOperation) Analyze(
+	_ context.Context,
 	log *slog.Logger,
 	i interaction.Interface,
 ) (analyzer.Result, error) {
@@ -59,7 +61,7 @@ func (*DetectAzureAsynchronousOperation) Analyze(
 		"Found Azure asynchronous operation",
 		"url", urltool.BaseURL(operationURL).String())
 
-	monitor := NewMonitorAzureAsynchronousOperation(operationURL)
+	monitor := NewMonitorAzureAsynchronousOperation(i, operationURL)
 
 	return analyzer.Spawn(monitor), nil
 }
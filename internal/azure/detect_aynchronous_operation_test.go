@@ -38,7 +38,7 @@ func TestDetectAzureAsynchronousOperation_SuccessfulRequestsWith202AndLocationHe
 			interaction := fake.Interaction(baseURL, c.method, 202)
 			interaction.SetResponseHeader(azureLocationHeader, locationURL)
 
-			result, err := detector.Analyze(log, interaction)
+			result, err := detector.Analyze(t.Context(), log, interaction)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result.Finished).To(BeFalse())
@@ -73,7 +73,7 @@ func TestDetectAzureAsynchronousOperation_OtherMethods_DoesNotSpawn(t *testing.T
 			interaction := fake.Interaction(baseURL, c.method, 202)
 			interaction.SetResponseHeader(azureLocationHeader, locationURL)
 
-			result, err := detector.Analyze(log, interaction)
+			result, err := detector.Analyze(t.Context(), log, interaction)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result).To(Equal(analyzer.Result{}))
@@ -108,7 +108,7 @@ func TestDetectAzureAsynchronousOperation_Non202StatusCode_DoesNotSpawn(t *testi
 			interaction := fake.Interaction(baseURL, "PUT", c.statusCode)
 			interaction.SetResponseHeader(azureLocationHeader, locationURL)
 
-			result, err := detector.Analyze(log, interaction)
+			result, err := detector.Analyze(t.Context(), log, interaction)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result).To(Equal(analyzer.Result{}))
@@ -126,7 +126,7 @@ func TestDetectAzureAsynchronousOperation_MissingLocationHeader_DoesNotSpawn(t *
 
 	interaction := fake.Interaction(baseURL, "PUT", 202)
 
-	result, err := detector.Analyze(log, interaction)
+	result, err := detector.Analyze(t.Context(), log, interaction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result).To(Equal(analyzer.Result{}))
@@ -143,7 +143,7 @@ func TestDetectAzureAsynchronousOperation_EmptyLocationHeader_DoesNotSpawn(t *te
 	interaction := fake.Interaction(baseURL, "PUT", 202)
 	interaction.SetResponseHeader(azureLocationHeader, "")
 
-	result, err := detector.Analyze(log, interaction)
+	result, err := detector.Analyze(t.Context(), log, interaction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result).To(Equal(analyzer.Result{}))
@@ -160,7 +160,7 @@ func TestDetectAzureAsynchronousOperation_InvalidLocationURL_DoesNotSpawn(t *tes
 	interaction := fake.Interaction(baseURL, "PUT", 202)
 	interaction.SetResponseHeader(azureLocationHeader, "://invalid")
 
-	result, err := detector.Analyze(log, interaction)
+	result, err := detector.Analyze(t.Context(), log, interaction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result).To(Equal(analyzer.Result{}))
@@ -179,7 +179,7 @@ func TestDetectAzureAsynchronousOperation_ValidLocationURL_ParsesCorrectly(t *te
 	interaction := fake.Interaction(baseURL, "PUT", 202)
 	interaction.SetResponseHeader(azureLocationHeader, locationURL)
 
-	result, err := detector.Analyze(log, interaction)
+	result, err := detector.Analyze(t.Context(), log, interaction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Spawn).To(HaveLen(1))
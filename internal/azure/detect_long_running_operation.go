@@ -1,6 +1,7 @@
 package azure
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -28,6 +29,7 @@ func NewDetectAzureLongRunningOperation() *DetectAzureLongRunningOperation {
 // Analyze processes another interaction in the sequence.
 // interaction is the interaction to analyze.
 func (*DetectAzureLongRunningOperation) Analyze(
+	_ context.Context,
 	log *slog.Logger,
 	i interaction.Interface,
 ) (analyzer.Result, error) {
@@ -56,7 +58,7 @@ func (*DetectAzureLongRunningOperation) Analyze(
 		"Found Azure long running operation",
 		"url", urltool.BaseURL(operationURL).String())
 
-	monitor := NewMonitorAzureLongRunningOperation(operationURL)
+	monitor := NewMonitorAzureLongRunningOperation(i, operationURL)
 
 	return analyzer.Spawn(monitor), nil
 }
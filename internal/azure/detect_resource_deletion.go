@@ -1,7 +1,7 @@
 package azure
 
 import (
-	"encoding/json"
+	"context"
 	"log/slog"
 	"net/http"
 
@@ -23,6 +23,7 @@ func NewDetectResourceDeletion() *DetectResourceDeletion {
 
 // Analyze processes another interaction in the sequence.
 func (*DetectResourceDeletion) Analyze(
+	_ context.Context,
 	log *slog.Logger,
 	i interaction.Interface,
 ) (analyzer.Result, error) {
@@ -32,17 +33,9 @@ func (*DetectResourceDeletion) Analyze(
 	}
 
 	// Check if response contains provisioningState indicating deletion
-	var response ResourceResponse
-
-	err := json.Unmarshal(i.Response().Body(), &response)
-	if err != nil {
-		// Not a valid Azure resource response; ignore
-		//nolint:nilerr // Just ignore invalid responses
-		return analyzer.Result{}, nil
-	}
-
 	// Only spawn monitor if we have a provisioningState
-	if response.Properties.ProvisioningState == "" {
+	provisioningState, ok := i.Response().JSON().Text(ProvisioningStatePath)
+	if !ok || provisioningState == "" {
 		return analyzer.Result{}, nil
 	}
 
@@ -51,10 +44,10 @@ func (*DetectResourceDeletion) Analyze(
 	log.Debug(
 		"Found resource deletion to monitor",
 		"url", reqURL.String(),
-		"provisioningState", response.Properties.ProvisioningState,
+		"provisioningState", provisioningState,
 	)
 
-	monitor := NewMonitorProvisioningState(reqURL, "Deleting")
+	monitor := NewMonitorProvisioningState(i, "Deleting")
 
 	return analyzer.Spawn(monitor), nil
 }
@@ -22,7 +22,7 @@ func TestDetectResourceDeletion_SuccessfulDELETE_SpawnsMonitor(t *testing.T) {
 	log := slogt.New(t)
 
 	deleteInteraction := createAzureResourceInteraction(baseURL, http.MethodDelete, 200, "Deleting")
-	result, err := detector.Analyze(log, deleteInteraction)
+	result, err := detector.Analyze(t.Context(), log, deleteInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeFalse())
@@ -52,7 +52,7 @@ func TestDetectResourceDeletion_Various2xxStatusCodes_SpawnsMonitor(t *testing.T
 			log := slogt.New(t)
 
 			deleteInteraction := createAzureResourceInteraction(baseURL, http.MethodDelete, c.statusCode, "Deleting")
-			result, err := detector.Analyze(log, deleteInteraction)
+			result, err := detector.Analyze(t.Context(), log, deleteInteraction)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result.Spawn).To(HaveLen(1))
@@ -81,7 +81,7 @@ func TestDetectResourceDeletion_FailedDELETE_DoesNotSpawn(t *testing.T) {
 			log := slogt.New(t)
 
 			deleteInteraction := createAzureResourceInteraction(baseURL, http.MethodDelete, c.statusCode, "Deleting")
-			result, err := detector.Analyze(log, deleteInteraction)
+			result, err := detector.Analyze(t.Context(), log, deleteInteraction)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result).To(Equal(analyzer.Result{}))
@@ -112,7 +112,7 @@ func TestDetectResourceDeletion_OtherMethods_DoesNotSpawn(t *testing.T) {
 			log := slogt.New(t)
 
 			interaction := createAzureResourceInteraction(baseURL, c.method, 200, "Deleting")
-			result, err := detector.Analyze(log, interaction)
+			result, err := detector.Analyze(t.Context(), log, interaction)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result).To(Equal(analyzer.Result{}))
@@ -130,7 +130,7 @@ func TestDetectResourceDeletion_InvalidJSON_DoesNotSpawn(t *testing.T) {
 
 	deleteInteraction := fake.Interaction(baseURL, http.MethodDelete, 200)
 
-	result, err := detector.Analyze(log, deleteInteraction)
+	result, err := detector.Analyze(t.Context(), log, deleteInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result).To(Equal(analyzer.Result{}))
@@ -146,7 +146,7 @@ func TestDetectResourceDeletion_MissingProvisioningState_DoesNotSpawn(t *testing
 
 	deleteInteraction := createInteractionWithJSON(baseURL, http.MethodDelete, 200, `{"properties": {}}`)
 
-	result, err := detector.Analyze(log, deleteInteraction)
+	result, err := detector.Analyze(t.Context(), log, deleteInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result).To(Equal(analyzer.Result{}))
@@ -168,7 +168,7 @@ func TestDetectResourceDeletion_NeverFinishes(t *testing.T) {
 	}
 
 	for _, inter := range interactions {
-		result, err := detector.Analyze(log, inter)
+		result, err := detector.Analyze(t.Context(), log, inter)
 		g.Expect(err).ToNot(HaveOccurred())
 		g.Expect(result.Finished).To(BeFalse(), "Detector should never finish")
 	}
@@ -183,7 +183,7 @@ func TestDetectResourceDeletion_SpawnedMonitorHasCorrectState(t *testing.T) {
 	log := slogt.New(t)
 
 	deleteInteraction := createAzureResourceInteraction(baseURL, http.MethodDelete, 200, "Deleting")
-	result, err := detector.Analyze(log, deleteInteraction)
+	result, err := detector.Analyze(t.Context(), log, deleteInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Spawn).To(HaveLen(1))
@@ -208,11 +208,11 @@ func TestDetectResourceDeletion_MultipleDELETEs_SpawnsMultipleMonitors(t *testin
 	delete1 := createAzureResourceInteraction(url1, http.MethodDelete, 200, "Deleting")
 	delete2 := createAzureResourceInteraction(url2, http.MethodDelete, 202, "Deleting")
 
-	result1, err := detector.Analyze(log, delete1)
+	result1, err := detector.Analyze(t.Context(), log, delete1)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result1.Spawn).To(HaveLen(1))
 
-	result2, err := detector.Analyze(log, delete2)
+	result2, err := detector.Analyze(t.Context(), log, delete2)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result2.Spawn).To(HaveLen(1))
 
@@ -229,7 +229,7 @@ func TestDetectResourceDeletion_EmptyResult_WhenNoAction(t *testing.T) {
 	log := slogt.New(t)
 
 	getInteraction := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Succeeded")
-	result, err := detector.Analyze(log, getInteraction)
+	result, err := detector.Analyze(t.Context(), log, getInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result).To(Equal(analyzer.Result{}))
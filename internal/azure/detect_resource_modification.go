@@ -1,7 +1,7 @@
 package azure
 
 import (
-	"encoding/json"
+	"context"
 	"log/slog"
 	"net/http"
 
@@ -23,6 +23,7 @@ func NewDetectResourceModification() *DetectResourceModification {
 
 // Analyze processes another interaction in the sequence.
 func (*DetectResourceModification) Analyze(
+	_ context.Context,
 	log *slog.Logger,
 	i interaction.Interface,
 ) (analyzer.Result, error) {
@@ -32,17 +33,9 @@ func (*DetectResourceModification) Analyze(
 	}
 
 	// Check if response contains provisioningState indicating a transient state
-	var response ResourceResponse
-
-	err := json.Unmarshal(i.Response().Body(), &response)
-	if err != nil {
-		// Not a valid Azure resource response; ignore
-		//nolint:nilerr // Just ignore invalid responses
-		return analyzer.Result{}, nil
-	}
-
 	// Only spawn monitor if we have a provisioningState
-	if response.Properties.ProvisioningState == "" {
+	provisioningState, ok := i.Response().JSON().Text(ProvisioningStatePath)
+	if !ok || provisioningState == "" {
 		return analyzer.Result{}, nil
 	}
 
@@ -52,11 +45,11 @@ func (*DetectResourceModification) Analyze(
 		"Found resource modification to monitor",
 		"url", reqURL.String(),
 		"method", i.Request().Method(),
-		"provisioningState", response.Properties.ProvisioningState,
+		"provisioningState", provisioningState,
 	)
 
-	monitorCreating := NewMonitorProvisioningState(reqURL, "Creating")
-	monitorUpdating := NewMonitorProvisioningState(reqURL, "Updating")
+	monitorCreating := NewMonitorProvisioningState(i, "Creating")
+	monitorUpdating := NewMonitorProvisioningState(i, "Updating")
 
 	return analyzer.Spawn(monitorCreating, monitorUpdating), nil
 }
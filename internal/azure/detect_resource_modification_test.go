@@ -22,7 +22,7 @@ func TestDetectResourceModification_SuccessfulPUT_SpawnsMonitor(t *testing.T) {
 	log := slogt.New(t)
 
 	putInteraction := createAzureResourceInteraction(baseURL, http.MethodPut, 200, "Creating")
-	result, err := detector.Analyze(log, putInteraction)
+	result, err := detector.Analyze(t.Context(), log, putInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeFalse())
@@ -41,7 +41,7 @@ func TestDetectResourceModification_SuccessfulPATCH_SpawnsMonitor(t *testing.T)
 	log := slogt.New(t)
 
 	patchInteraction := createAzureResourceInteraction(baseURL, http.MethodPatch, 200, "Updating")
-	result, err := detector.Analyze(log, patchInteraction)
+	result, err := detector.Analyze(t.Context(), log, patchInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeFalse())
@@ -72,7 +72,7 @@ func TestDetectResourceModification_Various2xxStatusCodes_SpawnsMonitor(t *testi
 			log := slogt.New(t)
 
 			putInteraction := createAzureResourceInteraction(baseURL, http.MethodPut, c.statusCode, "Creating")
-			result, err := detector.Analyze(log, putInteraction)
+			result, err := detector.Analyze(t.Context(), log, putInteraction)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result.Spawn).To(HaveLen(2), "Should spawn two monitors")
@@ -101,7 +101,7 @@ func TestDetectResourceModification_FailedRequest_DoesNotSpawn(t *testing.T) {
 			log := slogt.New(t)
 
 			putInteraction := createAzureResourceInteraction(baseURL, http.MethodPut, c.statusCode, "Creating")
-			result, err := detector.Analyze(log, putInteraction)
+			result, err := detector.Analyze(t.Context(), log, putInteraction)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result).To(Equal(analyzer.Result{}))
@@ -132,7 +132,7 @@ func TestDetectResourceModification_OtherMethods_DoesNotSpawn(t *testing.T) {
 			log := slogt.New(t)
 
 			interaction := createAzureResourceInteraction(baseURL, c.method, 200, "Creating")
-			result, err := detector.Analyze(log, interaction)
+			result, err := detector.Analyze(t.Context(), log, interaction)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result).To(Equal(analyzer.Result{}))
@@ -150,7 +150,7 @@ func TestDetectResourceModification_InvalidJSON_DoesNotSpawn(t *testing.T) {
 
 	putInteraction := fake.Interaction(baseURL, http.MethodPut, 200)
 
-	result, err := detector.Analyze(log, putInteraction)
+	result, err := detector.Analyze(t.Context(), log, putInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result).To(Equal(analyzer.Result{}))
@@ -166,7 +166,7 @@ func TestDetectResourceModification_MissingProvisioningState_DoesNotSpawn(t *tes
 
 	putInteraction := createInteractionWithJSON(baseURL, http.MethodPut, 200, `{"properties": {}}`)
 
-	result, err := detector.Analyze(log, putInteraction)
+	result, err := detector.Analyze(t.Context(), log, putInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result).To(Equal(analyzer.Result{}))
@@ -189,7 +189,7 @@ func TestDetectResourceModification_NeverFinishes(t *testing.T) {
 	}
 
 	for _, inter := range interactions {
-		result, err := detector.Analyze(log, inter)
+		result, err := detector.Analyze(t.Context(), log, inter)
 		g.Expect(err).ToNot(HaveOccurred())
 		g.Expect(result.Finished).To(BeFalse(), "Detector should never finish")
 	}
@@ -204,7 +204,7 @@ func TestDetectResourceModification_SpawnsTwoMonitors(t *testing.T) {
 	log := slogt.New(t)
 
 	putInteraction := createAzureResourceInteraction(baseURL, http.MethodPut, 200, "Creating")
-	result, err := detector.Analyze(log, putInteraction)
+	result, err := detector.Analyze(t.Context(), log, putInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Spawn).To(HaveLen(2), "Should spawn two monitors, one for Creating and one for Updating")
@@ -246,11 +246,11 @@ func TestDetectResourceModification_MultipleRequests_SpawnsMultipleMonitors(t *t
 	put1 := createAzureResourceInteraction(url1, http.MethodPut, 200, "Creating")
 	put2 := createAzureResourceInteraction(url2, http.MethodPut, 201, "Creating")
 
-	result1, err := detector.Analyze(log, put1)
+	result1, err := detector.Analyze(t.Context(), log, put1)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result1.Spawn).To(HaveLen(2), "Should spawn two monitors")
 
-	result2, err := detector.Analyze(log, put2)
+	result2, err := detector.Analyze(t.Context(), log, put2)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result2.Spawn).To(HaveLen(2), "Should spawn two monitors")
 
@@ -268,7 +268,7 @@ func TestDetectResourceModification_EmptyResult_WhenNoAction(t *testing.T) {
 	log := slogt.New(t)
 
 	getInteraction := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Succeeded")
-	result, err := detector.Analyze(log, getInteraction)
+	result, err := detector.Analyze(t.Context(), log, getInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result).To(Equal(analyzer.Result{}))
@@ -38,7 +38,7 @@ func runAnalyzer(
 
 	limit := len(interactions) - 1
 	for index, inter := range interactions {
-		result, err = a.Analyze(log, inter)
+		result, err = a.Analyze(t.Context(), log, inter)
 		g.Expect(err).ToNot(HaveOccurred())
 
 		if index < limit {
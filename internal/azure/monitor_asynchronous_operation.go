@@ -1,12 +1,15 @@
 package azure
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"net/url"
 
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/delays"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/retention"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/urltool"
 )
 
@@ -14,23 +17,45 @@ import (
 // After detecting an asynchronous operation via DetectAzureAsynchronousOperation, an instance of this is spawned to
 // track the operation until completion.
 // It watches for GET operations to the same base URL.
+// If the recording ends while the operation is still in progress, the polls seen are collapsed as if it had completed.
 type MonitorAzureAsynchronousOperation struct {
+	trigger      interaction.Interface   // Interaction that started the operation
 	operationURL *url.URL                // Base URL of the asynchronous operation to monitor
 	interactions []interaction.Interface // an ordered list of interactions related to this operation
+	retention    retention.Policy        // Policy for which interactions to retain
+	zeroDelays   bool                    // Whether to rewrite delay hints on the operation to zero
 }
 
-var _ analyzer.Interface = &MonitorAzureAsynchronousOperation{}
+var (
+	_ analyzer.Interface = &MonitorAzureAsynchronousOperation{}
+	_ analyzer.Finisher  = &MonitorAzureAsynchronousOperation{}
+)
 
 func NewMonitorAzureAsynchronousOperation(
+	trigger interaction.Interface,
 	operationURL *url.URL,
 ) *MonitorAzureAsynchronousOperation {
 	return &MonitorAzureAsynchronousOperation{
+		trigger:      trigger,
 		operationURL: urltool.BaseURL(operationURL),
+		retention:    retention.DefaultPolicy(),
 	}
 }
 
+// SetRetention changes the retention policy used when the operation completes.
+func (m *MonitorAzureAsynchronousOperation) SetRetention(policy retention.Policy) {
+	m.retention = policy
+}
+
+// SetZeroDelays controls whether delay hints on the interaction starting the operation, the polls retained and the
+// final poll are rewritten to zero.
+func (m *MonitorAzureAsynchronousOperation) SetZeroDelays(enabled bool) {
+	m.zeroDelays = enabled
+}
+
 // Analyze processes another interaction in the sequence.
 func (m *MonitorAzureAsynchronousOperation) Analyze(
+	_ context.Context,
 	log *slog.Logger,
 	i interaction.Interface,
 ) (analyzer.Result, error) {
@@ -54,7 +79,33 @@ func (m *MonitorAzureAsynchronousOperation) Analyze(
 	}
 
 	// Operation is complete, check whether we have any interactions to exclude
-	if len(m.interactions) <= 2 {
+	return m.operationCompleted(log, i)
+}
+
+// Finish handles the end of the recording while the operation was still in progress.
+func (m *MonitorAzureAsynchronousOperation) Finish(_ context.Context, log *slog.Logger) (analyzer.Result, error) {
+	log.Debug(
+		"Recording ended before asynchronous operation finished",
+		"url", m.operationURL,
+	)
+
+	return m.operationCompleted(log, nil)
+}
+
+// operationCompleted excludes the polls not kept by the retention policy, once the operation is complete.
+// The polls kept are retained, protecting them from exclusion by any other strategy.
+// final is the poll reporting completion, or nil if the recording ended first.
+func (m *MonitorAzureAsynchronousOperation) operationCompleted(
+	log *slog.Logger,
+	final interaction.Interface,
+) (analyzer.Result, error) {
+	retained, excluded := m.retention.Apply(m.interactions)
+	if m.zeroDelays {
+		delays.Zero(m.trigger, final)
+		delays.Zero(retained...)
+	}
+
+	if len(excluded) == 0 {
 		// No intermediate interactions to exclude.
 		log.Debug(
 			"Asynchronous operation finished quickly, nothing to exclude",
@@ -64,19 +115,23 @@ func (m *MonitorAzureAsynchronousOperation) Analyze(
 		return analyzer.Finished(), nil
 	}
 
-	retained := m.interactions[:headerLength]
-	retained = append(retained, m.interactions[len(m.interactions)-footerLength:]...)
-
 	// Ensure Location headers are linked correctly
 	relinkLocationHeaders(retained)
 
-	excluded := m.interactions[headerLength : len(m.interactions)-footerLength]
-
 	log.Debug(
 		"Asynchronous operation finished, excluding intermediate GETs",
 		"url", m.operationURL,
 		"removed", len(excluded),
 	)
 
-	return analyzer.FinishedWithExclusions(excluded...), nil
+	provenance := analyzer.Because(
+		m.operationURL.String(),
+		"intermediate poll of an asynchronous operation still in progress")
+
+	// The retained polls now form a chain of Location headers, so other strategies mustn't remove any of them
+	chained := analyzer.Because(
+		m.operationURL.String(),
+		"poll summarising an asynchronous operation, linked by Location headers")
+
+	return analyzer.FinishedWithExclusions(provenance, excluded...).AlsoRetain(chained, retained...), nil
 }
@@ -0,0 +1,49 @@
+package azure
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/fake"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/must"
+)
+
+func TestMonitorAzureAsynchronousOperation_OperationCompletes_ExcludesOnlyIntermediatePolls(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	operationURL := must.ParseURL(t, "https://management.azure.com/providers/Microsoft.Storage/asyncoperations/op1")
+	trigger := fake.Interaction(operationURL, http.MethodPost, http.StatusAccepted)
+	monitor := NewMonitorAzureAsynchronousOperation(trigger, operationURL)
+	log := slogt.New(t)
+
+	polls := make([]*fake.TestInteraction, 5)
+	for index := range polls {
+		polls[index] = fake.Interaction(operationURL, http.MethodGet, 202)
+		polls[index].SetResponseHeader("Location", operationURL.String())
+	}
+
+	completed := fake.Interaction(operationURL, http.MethodGet, 200)
+
+	result := runAnalyzer(t, log, monitor, polls[0], polls[1], polls[2], polls[3], polls[4], completed)
+
+	// The first and last polls are retained, rather than the last overwriting the second while being excluded
+	g.Expect(result.Finished).To(BeTrue())
+	g.Expect(result.Excluded).To(HaveExactElements(
+		BeIdenticalTo(polls[1]),
+		BeIdenticalTo(polls[2]),
+		BeIdenticalTo(polls[3])))
+	g.Expect(result.Retained).To(HaveExactElements(
+		BeIdenticalTo(polls[0]),
+		BeIdenticalTo(polls[4])))
+	g.Expect(monitor.interactions).To(HaveExactElements(
+		BeIdenticalTo(polls[0]),
+		BeIdenticalTo(polls[1]),
+		BeIdenticalTo(polls[2]),
+		BeIdenticalTo(polls[3]),
+		BeIdenticalTo(polls[4])))
+}
@@ -1,13 +1,15 @@
 package azure
 
 import (
-	"encoding/json"
+	"context"
 	"log/slog"
 	"net/http"
 	"net/url"
 
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/delays"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/retention"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/urltool"
 )
 
@@ -15,30 +17,45 @@ import (
 // After detecting a long-running operation via DetectAzureLongRunningOperation, an instance of this is spawned to track
 // the operation until completion.
 // It watches for GET operations to the same base URL (ignoring changes to the `t` and `c` parameters).
+// If the recording ends while the operation is still in progress, the polls seen are collapsed as if it had completed.
 type MonitorAzureLongRunningOperation struct {
+	trigger      interaction.Interface   // Interaction that started the operation
 	operationURL *url.URL                // Base URL of the long-running operation to monitor
 	interactions []interaction.Interface // an ordered list of interactions related to this operation
+	retention    retention.Policy        // Policy for which interactions to retain
+	zeroDelays   bool                    // Whether to rewrite delay hints on the operation to zero
 }
 
-var _ analyzer.Interface = &MonitorAzureLongRunningOperation{}
+var (
+	_ analyzer.Interface = &MonitorAzureLongRunningOperation{}
+	_ analyzer.Finisher  = &MonitorAzureLongRunningOperation{}
+)
 
 func NewMonitorAzureLongRunningOperation(
+	trigger interaction.Interface,
 	operationURL *url.URL,
 ) *MonitorAzureLongRunningOperation {
 	return &MonitorAzureLongRunningOperation{
+		trigger:      trigger,
 		operationURL: urltool.BaseURL(operationURL),
+		retention:    retention.DefaultPolicy(),
 	}
 }
 
-const (
-	// headerLength is the number of retained interactions at the start of the operation.
-	headerLength = 1
-	// footerLength is the number of retained interactions at the end of the operation.
-	footerLength = 1
-)
+// SetRetention changes the retention policy used when the operation completes.
+func (m *MonitorAzureLongRunningOperation) SetRetention(policy retention.Policy) {
+	m.retention = policy
+}
+
+// SetZeroDelays controls whether delay hints on the interaction starting the operation, the polls retained and the
+// final poll are rewritten to zero.
+func (m *MonitorAzureLongRunningOperation) SetZeroDelays(enabled bool) {
+	m.zeroDelays = enabled
+}
 
 // Analyze processes another interaction in the sequence.
 func (m *MonitorAzureLongRunningOperation) Analyze(
+	_ context.Context,
 	log *slog.Logger,
 	i interaction.Interface,
 ) (analyzer.Result, error) {
@@ -47,16 +64,13 @@ func (m *MonitorAzureLongRunningOperation) Analyze(
 	}
 
 	// Check the status of the operation
-	var operation Operation
-
-	err := json.Unmarshal(i.Response().Body(), &operation)
-	if err != nil {
+	document := i.Response().JSON()
+	if !document.Valid() {
 		// Not a valid operation response; ignore
-		//nolint:nilerr // Just ignore invalid responses
 		return analyzer.Result{}, nil
 	}
 
-	if operation.Status == "InProgress" {
+	if status, _ := document.Text(OperationStatusPath); status == "InProgress" {
 		// Record the interaction and continue
 		m.interactions = append(m.interactions, i)
 
@@ -64,7 +78,33 @@ func (m *MonitorAzureLongRunningOperation) Analyze(
 	}
 
 	// Operation is complete, check whether we have any interactions to exclude
-	if len(m.interactions) <= headerLength+footerLength {
+	return m.operationCompleted(log, i)
+}
+
+// Finish handles the end of the recording while the operation was still in progress.
+func (m *MonitorAzureLongRunningOperation) Finish(_ context.Context, log *slog.Logger) (analyzer.Result, error) {
+	log.Debug(
+		"Recording ended before long running operation finished",
+		"url", m.operationURL,
+	)
+
+	return m.operationCompleted(log, nil)
+}
+
+// operationCompleted excludes the polls not kept by the retention policy, once the operation is complete.
+// The polls kept are retained, protecting them from exclusion by any other strategy.
+// final is the poll reporting completion, or nil if the recording ended first.
+func (m *MonitorAzureLongRunningOperation) operationCompleted(
+	log *slog.Logger,
+	final interaction.Interface,
+) (analyzer.Result, error) {
+	retained, excluded := m.retention.Apply(m.interactions)
+	if m.zeroDelays {
+		delays.Zero(m.trigger, final)
+		delays.Zero(retained...)
+	}
+
+	if len(excluded) == 0 {
 		// No intermediate interactions to exclude.
 		log.Debug(
 			"Long running operation finished quickly, nothing to exclude",
@@ -74,21 +114,25 @@ func (m *MonitorAzureLongRunningOperation) Analyze(
 		return analyzer.Finished(), nil
 	}
 
-	retained := m.interactions[:headerLength]
-	retained = append(retained, m.interactions[len(m.interactions)-footerLength:]...)
-
 	// Ensure Location headers are linked correctly
 	relinkLocationHeaders(retained)
 
-	excluded := m.interactions[headerLength : len(m.interactions)-footerLength]
-
 	log.Debug(
 		"Long running operation finished, excluding intermediate GETs",
 		"url", m.operationURL,
 		"removed", len(excluded),
 	)
 
-	return analyzer.FinishedWithExclusions(excluded...), nil
+	provenance := analyzer.Because(
+		m.operationURL.String(),
+		"intermediate poll of a long running operation still in progress")
+
+	// The retained polls now form a chain of Location headers, so other strategies mustn't remove any of them
+	chained := analyzer.Because(
+		m.operationURL.String(),
+		"poll summarising a long running operation, linked by Location headers")
+
+	return analyzer.FinishedWithExclusions(provenance, excluded...).AlsoRetain(chained, retained...), nil
 }
 
 // isRelevantGet checks whether the interaction is a GET to the operation URL.
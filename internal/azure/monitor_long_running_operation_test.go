@@ -0,0 +1,83 @@
+package azure
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/fake"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/must"
+)
+
+func TestMonitorAzureLongRunningOperation_OperationSucceeds_ExcludesOnlyIntermediatePolls(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	operationURL := must.ParseURL(t, "https://management.azure.com/providers/Microsoft.Sql/operations/op1")
+	trigger := fake.Interaction(operationURL, http.MethodPut, http.StatusCreated)
+	monitor := NewMonitorAzureLongRunningOperation(trigger, operationURL)
+	log := slogt.New(t)
+
+	polls := make([]*fake.TestInteraction, 5)
+	for index := range polls {
+		polls[index] = createInteractionWithJSON(operationURL, http.MethodGet, 200, `{"status": "InProgress"}`)
+	}
+
+	succeeded := createInteractionWithJSON(operationURL, http.MethodGet, 200, `{"status": "Succeeded"}`)
+
+	result := runAnalyzer(t, log, monitor, polls[0], polls[1], polls[2], polls[3], polls[4], succeeded)
+
+	// The first and last polls are retained, rather than the last overwriting the second while being excluded
+	g.Expect(result.Finished).To(BeTrue())
+	g.Expect(result.Excluded).To(HaveExactElements(
+		BeIdenticalTo(polls[1]),
+		BeIdenticalTo(polls[2]),
+		BeIdenticalTo(polls[3])))
+	g.Expect(result.Retained).To(HaveExactElements(
+		BeIdenticalTo(polls[0]),
+		BeIdenticalTo(polls[4])))
+	g.Expect(monitor.interactions).To(HaveExactElements(
+		BeIdenticalTo(polls[0]),
+		BeIdenticalTo(polls[1]),
+		BeIdenticalTo(polls[2]),
+		BeIdenticalTo(polls[3]),
+		BeIdenticalTo(polls[4])))
+}
+
+func TestMonitorAzureLongRunningOperation_WithZeroDelays_RewritesRetryAfterAcrossSequence(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	resourceURL := must.ParseURL(t, "https://management.azure.com/subscriptions/sub1/resourceGroups/rg1")
+	operationURL := must.ParseURL(t, "https://management.azure.com/providers/Microsoft.Sql/operations/op1")
+	trigger := fake.Interaction(resourceURL, http.MethodPut, http.StatusAccepted)
+	trigger.SetResponseHeader(azureLROHeader, operationURL.String())
+
+	monitor := NewMonitorAzureLongRunningOperation(trigger, operationURL)
+	monitor.SetZeroDelays(true)
+	log := slogt.New(t)
+
+	polls := make([]*fake.TestInteraction, 3)
+	for index := range polls {
+		polls[index] = createInteractionWithJSON(operationURL, http.MethodGet, 200, `{"status": "InProgress"}`)
+	}
+
+	succeeded := createInteractionWithJSON(operationURL, http.MethodGet, 200, `{"status": "Succeeded"}`)
+
+	for _, i := range []*fake.TestInteraction{trigger, polls[0], polls[1], polls[2], succeeded} {
+		i.SetResponseHeader("Retry-After", "10")
+	}
+
+	result := runAnalyzer(t, log, monitor, polls[0], polls[1], polls[2], succeeded)
+	g.Expect(result.Excluded).To(ConsistOf(polls[1]))
+
+	// Every interaction left in the sequence replays without a delay, including the PUT and the final poll
+	for _, i := range []*fake.TestInteraction{trigger, polls[0], polls[2], succeeded} {
+		retryAfter, ok := i.Response().Header("Retry-After")
+		g.Expect(ok).To(BeTrue())
+		g.Expect(retryAfter).To(Equal("0"), i.String())
+	}
+}
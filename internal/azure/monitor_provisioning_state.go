@@ -1,44 +1,70 @@
 package azure
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
 
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/delays"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/retention"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/urltool"
 )
 
 // MonitorProvisioningState is an analyzer for monitoring Azure resource provisioning states.
 // It watches for GET requests to a specific URL and tracks interactions where the provisioningState
 // matches the target state (case-insensitive). When the provisioningState transitions to a
-// different value, the monitor finishes and excludes all but the first and last accumulated interactions.
+// different value, the monitor finishes and excludes the accumulated interactions not kept by the retention policy
+// (by default, all but the first and last).
+// If the recording ends while the provisioningState still matches the target state, the accumulated interactions are
+// collapsed in the same way.
 type MonitorProvisioningState struct {
+	trigger      interaction.Interface   // PUT, PATCH or DELETE that started provisioning
 	baseURL      *url.URL                // Base URL of the resource to monitor
 	targetState  string                  // State to monitor (e.g., "Creating" or "Updating")
 	interactions []interaction.Interface // Accumulated interactions with matching provisioningState
+	retention    retention.Policy        // Policy for which accumulated interactions to retain
+	zeroDelays   bool                    // Whether to rewrite delay hints on the sequence to zero
 }
 
-var _ analyzer.Interface = (*MonitorProvisioningState)(nil)
+var (
+	_ analyzer.Interface = (*MonitorProvisioningState)(nil)
+	_ analyzer.Finisher  = (*MonitorProvisioningState)(nil)
+)
 
 // NewMonitorProvisioningState creates a new MonitorProvisioningState analyzer.
-// baseURL is the base URL of the resource to monitor.
+// trigger is the PUT, PATCH or DELETE of the resource to monitor.
 // targetState is the provisioningState value to watch for (case-insensitive).
 func NewMonitorProvisioningState(
-	baseURL *url.URL,
+	trigger interaction.Interface,
 	targetState string,
 ) *MonitorProvisioningState {
 	return &MonitorProvisioningState{
-		baseURL:     baseURL,
+		trigger:     trigger,
+		baseURL:     trigger.Request().BaseURL(),
 		targetState: targetState,
+		retention:   retention.DefaultPolicy(),
 	}
 }
 
+// SetRetention changes the retention policy used when the provisioning state transitions.
+func (m *MonitorProvisioningState) SetRetention(policy retention.Policy) {
+	m.retention = policy
+}
+
+// SetZeroDelays controls whether delay hints on the trigger, the polls retained and the poll showing the transition
+// are rewritten to zero.
+func (m *MonitorProvisioningState) SetZeroDelays(enabled bool) {
+	m.zeroDelays = enabled
+}
+
 // Analyze processes another interaction in the sequence.
 func (m *MonitorProvisioningState) Analyze(
+	_ context.Context,
 	log *slog.Logger,
 	i interaction.Interface,
 ) (analyzer.Result, error) {
@@ -82,10 +108,8 @@ func (m *MonitorProvisioningState) checkProvisioningState(
 	i interaction.Interface,
 ) (analyzer.Result, error) {
 	// Parse the response body to extract provisioningState
-	var response ResourceResponse
-
-	err := json.Unmarshal(i.Response().Body(), &response)
-	if err != nil {
+	document := i.Response().JSON()
+	if !document.Valid() {
 		// Not a valid Azure resource response; abandon monitoring
 		// This is not an error - just a condition this monitor isn't prepared to handle
 		log.Debug(
@@ -93,12 +117,11 @@ func (m *MonitorProvisioningState) checkProvisioningState(
 			"url", m.baseURL.String(),
 		)
 
-		//nolint:nilerr // Invalid JSON is not an error, just a condition we can't handle
 		return analyzer.Finished(), nil
 	}
 
-	currentState := response.Properties.ProvisioningState
-	if currentState == "" {
+	currentState, ok := document.Text(ProvisioningStatePath)
+	if !ok || currentState == "" {
 		// No provisioningState field; abandon monitoring
 		log.Debug(
 			"Abandoning provisioning state monitor, missing provisioningState",
@@ -117,14 +140,33 @@ func (m *MonitorProvisioningState) checkProvisioningState(
 	}
 
 	// State has transitioned to a non-target state
-	return m.stateTransitioned(log)
+	return m.stateTransitioned(log, i)
+}
+
+// Finish handles the end of the recording before the provisioningState transitioned.
+func (m *MonitorProvisioningState) Finish(_ context.Context, log *slog.Logger) (analyzer.Result, error) {
+	log.Debug(
+		"Recording ended before provisioning state transitioned",
+		"url", m.baseURL.String(),
+		"state", m.targetState,
+	)
+
+	return m.stateTransitioned(log, nil)
 }
 
 // stateTransitioned handles the case where provisioningState has moved to a final state.
+// transition is the poll showing the new state, or nil if the recording ended first.
 func (m *MonitorProvisioningState) stateTransitioned(
 	log *slog.Logger,
+	transition interaction.Interface,
 ) (analyzer.Result, error) {
-	if len(m.interactions) < 2 {
+	retained, excluded := m.retention.Apply(m.interactions)
+	if m.zeroDelays {
+		delays.Zero(m.trigger, transition)
+		delays.Zero(retained...)
+	}
+
+	if len(excluded) == 0 {
 		// No intermediate interactions to exclude.
 		log.Debug(
 			"Short provisioning state sequence, nothing to exclude",
@@ -137,10 +179,12 @@ func (m *MonitorProvisioningState) stateTransitioned(
 	log.Debug(
 		"Provisioning state sequence finished, excluding intermediate GETs",
 		"url", m.baseURL.String(),
-		"removed", len(m.interactions)-2,
+		"removed", len(excluded),
 	)
 
-	excluded := m.interactions[1 : len(m.interactions)-1]
+	provenance := analyzer.Because(
+		m.baseURL.String(),
+		fmt.Sprintf("intermediate GET while provisioningState remained %s", m.targetState))
 
-	return analyzer.FinishedWithExclusions(excluded...), nil
+	return analyzer.FinishedWithExclusions(provenance, excluded...), nil
 }
@@ -19,7 +19,7 @@ func TestMonitorProvisioningState_SingleState_AccumulatesAndExcludes(t *testing.
 	g := NewWithT(t)
 
 	baseURL := must.ParseURL(t, "https://management.azure.com/resource")
-	monitor := NewMonitorProvisioningState(baseURL, "Creating")
+	monitor := NewMonitorProvisioningState(fake.Interaction(baseURL, http.MethodPut, http.StatusOK), "Creating")
 	log := slogt.New(t)
 
 	// Create interactions with provisioningState in response
@@ -33,6 +33,8 @@ func TestMonitorProvisioningState_SingleState_AccumulatesAndExcludes(t *testing.
 	g.Expect(result.Finished).To(BeTrue())
 	g.Expect(result.Excluded).To(HaveLen(1))
 	g.Expect(result.Excluded).To(ContainElement(get2))
+	g.Expect(result.Provenance.URL).To(Equal(baseURL.String()))
+	g.Expect(result.Provenance.Reason).To(ContainSubstring("Creating"))
 }
 
 func TestMonitorProvisioningState_OnlyMatchesSpecificState(t *testing.T) {
@@ -40,20 +42,20 @@ func TestMonitorProvisioningState_OnlyMatchesSpecificState(t *testing.T) {
 	g := NewWithT(t)
 
 	baseURL := must.ParseURL(t, "https://management.azure.com/resource")
-	monitor := NewMonitorProvisioningState(baseURL, "Creating")
+	monitor := NewMonitorProvisioningState(fake.Interaction(baseURL, http.MethodPut, http.StatusOK), "Creating")
 	log := slogt.New(t)
 
 	// Monitor should only accumulate "Creating" states, not "Updating"
 	get1 := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Creating")
 	get2 := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Updating")
 
-	result1, err := monitor.Analyze(log, get1)
+	result1, err := monitor.Analyze(t.Context(), log, get1)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result1.Finished).To(BeFalse())
 	g.Expect(monitor.interactions).To(HaveLen(1))
 
 	// When state changes to "Updating", monitor should finish
-	result2, err := monitor.Analyze(log, get2)
+	result2, err := monitor.Analyze(t.Context(), log, get2)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result2.Finished).To(BeTrue())
 	// Only one "Creating" interaction, so nothing to exclude
@@ -65,7 +67,7 @@ func TestMonitorProvisioningState_CaseInsensitive_MatchesState(t *testing.T) {
 	g := NewWithT(t)
 
 	baseURL := must.ParseURL(t, "https://management.azure.com/resource")
-	monitor := NewMonitorProvisioningState(baseURL, "Creating")
+	monitor := NewMonitorProvisioningState(fake.Interaction(baseURL, http.MethodPut, http.StatusOK), "Creating")
 	log := slogt.New(t)
 
 	// Various case combinations
@@ -86,7 +88,7 @@ func TestMonitorProvisioningState_ShortSequence_NothingExcluded(t *testing.T) {
 	g := NewWithT(t)
 
 	baseURL := must.ParseURL(t, "https://management.azure.com/resource")
-	monitor := NewMonitorProvisioningState(baseURL, "Creating")
+	monitor := NewMonitorProvisioningState(fake.Interaction(baseURL, http.MethodPut, http.StatusOK), "Creating")
 	log := slogt.New(t)
 
 	// Only one Creating state before transition
@@ -104,13 +106,13 @@ func TestMonitorProvisioningState_ImmediateTransition_NothingExcluded(t *testing
 	g := NewWithT(t)
 
 	baseURL := must.ParseURL(t, "https://management.azure.com/resource")
-	monitor := NewMonitorProvisioningState(baseURL, "Creating")
+	monitor := NewMonitorProvisioningState(fake.Interaction(baseURL, http.MethodPut, http.StatusOK), "Creating")
 	log := slogt.New(t)
 
 	// Immediate transition without any Creating states
 	getFinal := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Succeeded")
 
-	result, err := monitor.Analyze(log, getFinal)
+	result, err := monitor.Analyze(t.Context(), log, getFinal)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeTrue())
@@ -123,12 +125,12 @@ func TestMonitorProvisioningState_DifferentURL_Ignored(t *testing.T) {
 
 	monitoredURL := must.ParseURL(t, "https://management.azure.com/resource/123")
 	differentURL := must.ParseURL(t, "https://management.azure.com/resource/456")
-	monitor := NewMonitorProvisioningState(monitoredURL, "Creating")
+	monitor := NewMonitorProvisioningState(fake.Interaction(monitoredURL, http.MethodPut, http.StatusOK), "Creating")
 	log := slogt.New(t)
 
 	i := createAzureResourceInteraction(differentURL, http.MethodGet, 200, "Creating")
 
-	result, err := monitor.Analyze(log, i)
+	result, err := monitor.Analyze(t.Context(), log, i)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeFalse())
@@ -153,7 +155,7 @@ func TestMonitorProvisioningState_NonGETMethod_AbandonsMonitoring(t *testing.T)
 			g := NewWithT(t)
 
 			baseURL := must.ParseURL(t, "https://management.azure.com/resource")
-			monitor := NewMonitorProvisioningState(baseURL, "Creating")
+			monitor := NewMonitorProvisioningState(fake.Interaction(baseURL, http.MethodPut, http.StatusOK), "Creating")
 			log := slogt.New(t)
 
 			// Accumulate some interactions first
@@ -185,7 +187,7 @@ func TestMonitorProvisioningState_UnexpectedStatusCode_AbandonsMonitoring(t *tes
 			g := NewWithT(t)
 
 			baseURL := must.ParseURL(t, "https://management.azure.com/resource")
-			monitor := NewMonitorProvisioningState(baseURL, "Creating")
+			monitor := NewMonitorProvisioningState(fake.Interaction(baseURL, http.MethodPut, http.StatusOK), "Creating")
 			log := slogt.New(t)
 
 			get1 := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Creating")
@@ -204,13 +206,13 @@ func TestMonitorProvisioningState_InvalidJSON_AbandonMonitoring(t *testing.T) {
 	g := NewWithT(t)
 
 	baseURL := must.ParseURL(t, "https://management.azure.com/resource")
-	monitor := NewMonitorProvisioningState(baseURL, "Creating")
+	monitor := NewMonitorProvisioningState(fake.Interaction(baseURL, http.MethodPut, http.StatusOK), "Creating")
 	log := slogt.New(t)
 
 	// Create interaction with invalid JSON
 	getInvalid := fake.Interaction(baseURL, http.MethodGet, 200)
 
-	result, err := monitor.Analyze(log, getInvalid)
+	result, err := monitor.Analyze(t.Context(), log, getInvalid)
 
 	g.Expect(err).ToNot(HaveOccurred(), "Invalid JSON should not be treated as an error")
 	g.Expect(result.Finished).To(BeTrue(), "Should abandon monitoring on invalid JSON")
@@ -222,13 +224,13 @@ func TestMonitorProvisioningState_MissingProvisioningState_AbandonMonitoring(t *
 	g := NewWithT(t)
 
 	baseURL := must.ParseURL(t, "https://management.azure.com/resource")
-	monitor := NewMonitorProvisioningState(baseURL, "Creating")
+	monitor := NewMonitorProvisioningState(fake.Interaction(baseURL, http.MethodPut, http.StatusOK), "Creating")
 	log := slogt.New(t)
 
 	// Create interaction with valid JSON but no provisioningState
 	getNoState := createInteractionWithJSON(baseURL, http.MethodGet, 200, `{"properties": {}}`)
 
-	result, err := monitor.Analyze(log, getNoState)
+	result, err := monitor.Analyze(t.Context(), log, getNoState)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeTrue(), "Should abandon monitoring when provisioningState is missing")
@@ -241,13 +243,13 @@ func TestMonitorProvisioningState_URLWithQueryParameters_MonitorsBaseURL(t *test
 
 	baseURL := must.ParseURL(t, "https://management.azure.com/resource")
 	urlWithParams := must.ParseURL(t, "https://management.azure.com/resource?api-version=2021-01-01")
-	monitor := NewMonitorProvisioningState(baseURL, "Creating")
+	monitor := NewMonitorProvisioningState(fake.Interaction(baseURL, http.MethodPut, http.StatusOK), "Creating")
 	log := slogt.New(t)
 
 	// Interaction with query parameters should match base URL
 	get1 := createAzureResourceInteraction(urlWithParams, http.MethodGet, 200, "Creating")
 
-	result, err := monitor.Analyze(log, get1)
+	result, err := monitor.Analyze(t.Context(), log, get1)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeFalse())
@@ -260,7 +262,7 @@ func TestMonitorProvisioningState_ManyMiddleInteractions_AllExcluded(t *testing.
 	g := NewWithT(t)
 
 	baseURL := must.ParseURL(t, "https://management.azure.com/resource")
-	monitor := NewMonitorProvisioningState(baseURL, "Creating")
+	monitor := NewMonitorProvisioningState(fake.Interaction(baseURL, http.MethodPut, http.StatusOK), "Creating")
 	log := slogt.New(t)
 
 	// Create many interactions
@@ -290,7 +292,7 @@ func TestMonitorProvisioningState_DeletingState_Works(t *testing.T) {
 	g := NewWithT(t)
 
 	baseURL := must.ParseURL(t, "https://management.azure.com/resource")
-	monitor := NewMonitorProvisioningState(baseURL, "Deleting")
+	monitor := NewMonitorProvisioningState(fake.Interaction(baseURL, http.MethodDelete, http.StatusOK), "Deleting")
 	log := slogt.New(t)
 
 	get1 := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Deleting")
@@ -311,12 +313,84 @@ func TestMonitorProvisioningState_EmptyResult_WhenIgnoringInteraction(t *testing
 
 	monitoredURL := must.ParseURL(t, "https://management.azure.com/resource/123")
 	differentURL := must.ParseURL(t, "https://management.azure.com/other")
-	monitor := NewMonitorProvisioningState(monitoredURL, "Creating")
+	monitor := NewMonitorProvisioningState(fake.Interaction(monitoredURL, http.MethodPut, http.StatusOK), "Creating")
 	log := slogt.New(t)
 
 	i := createAzureResourceInteraction(differentURL, http.MethodGet, 200, "Creating")
-	result, err := monitor.Analyze(log, i)
+	result, err := monitor.Analyze(t.Context(), log, i)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result).To(Equal(analyzer.Result{}))
 }
+
+func TestMonitorProvisioningState_WithZeroDelays_RewritesRetryAfterAcrossSequence(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	baseURL := must.ParseURL(t, "https://management.azure.com/resource")
+	put := createAzureResourceInteraction(baseURL, http.MethodPut, 201, "Creating")
+	monitor := NewMonitorProvisioningState(put, "Creating")
+	monitor.SetZeroDelays(true)
+	log := slogt.New(t)
+
+	get1 := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Creating")
+	get2 := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Creating")
+	get3 := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Creating")
+	getFinal := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Succeeded")
+
+	for _, i := range []*fake.TestInteraction{put, get1, get2, get3, getFinal} {
+		i.SetResponseHeader("Retry-After", "15")
+	}
+
+	result := runAnalyzer(t, log, monitor, get1, get2, get3, getFinal)
+
+	g.Expect(result.Excluded).To(ConsistOf(get2))
+
+	// Every interaction left in the sequence replays without a delay, including the PUT and the final GET
+	for _, i := range []*fake.TestInteraction{put, get1, get3, getFinal} {
+		retryAfter, ok := i.Response().Header("Retry-After")
+		g.Expect(ok).To(BeTrue())
+		g.Expect(retryAfter).To(Equal("0"), i.String())
+	}
+}
+
+func TestMonitorProvisioningState_WithoutZeroDelays_LeavesRetryAfterUnchanged(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	baseURL := must.ParseURL(t, "https://management.azure.com/resource")
+	monitor := NewMonitorProvisioningState(fake.Interaction(baseURL, http.MethodPut, http.StatusOK), "Creating")
+	log := slogt.New(t)
+
+	get1 := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Creating")
+	getFinal := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Succeeded")
+	get1.SetResponseHeader("Retry-After", "15")
+
+	runAnalyzer(t, log, monitor, get1, getFinal)
+
+	retryAfter, ok := get1.Response().Header("Retry-After")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(retryAfter).To(Equal("15"))
+}
+
+func TestMonitorProvisioningState_RecordingEndsInTargetState_ExcludesIntermediate(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	baseURL := must.ParseURL(t, "https://management.azure.com/resource")
+	monitor := NewMonitorProvisioningState(fake.Interaction(baseURL, http.MethodPut, http.StatusOK), "Creating")
+	log := slogt.New(t)
+
+	// The recording ends while the resource is still being created
+	get1 := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Creating")
+	get2 := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Creating")
+	get3 := createAzureResourceInteraction(baseURL, http.MethodGet, 200, "Creating")
+
+	result := runAnalyzer(t, log, monitor, get1, get2, get3)
+	g.Expect(result.Finished).To(BeFalse())
+
+	result, err := monitor.Finish(t.Context(), log)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.Excluded).To(ConsistOf(get2))
+	g.Expect(result.Provenance.Reason).To(ContainSubstring("Creating"))
+}
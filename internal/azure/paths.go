@@ -0,0 +1,8 @@
+package azure
+
+const (
+	// ProvisioningStatePath is the path to the provisioning state of an Azure resource.
+	ProvisioningStatePath = "properties.provisioningState"
+	// OperationStatusPath is the path to the status of an Azure long-running operation.
+	OperationStatusPath = "status"
+)
@@ -0,0 +1,93 @@
+package cassettefile
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// Format identifies the serialization used for a cassette file.
+type Format int
+
+const (
+	// YAML is the native go-vcr cassette format.
+	YAML Format = iota
+	// JSON uses the same structure as YAML, with the same field names, serialized as JSON.
+	JSON
+)
+
+// String returns the name of the format.
+func (f Format) String() string {
+	switch f {
+	case JSON:
+		return "json"
+	default:
+		return "yaml"
+	}
+}
+
+// Encoding describes how a cassette is stored on disk.
+type Encoding struct {
+	Format     Format // Serialization of the cassette
+	Compressed bool   // True if the serialized cassette is compressed with gzip
+}
+
+// String returns a readable description of the encoding, e.g. "yaml+gzip".
+func (e Encoding) String() string {
+	if e.Compressed {
+		return e.Format.String() + "+gzip"
+	}
+
+	return e.Format.String()
+}
+
+// gzipMagic are the first bytes of any gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Detect determines the encoding of a cassette file from its path and content.
+// The extension is used where it is recognized (.yaml, .yml or .json, optionally followed by .gz); otherwise the
+// content is sniffed. Content that can't be identified is assumed to be YAML.
+// content is the raw content of the file; after decompression, it's sniffed again to identify the format.
+func Detect(path string, content []byte) Encoding {
+	var result Encoding
+
+	name := strings.ToLower(filepath.Base(path))
+
+	if strings.HasSuffix(name, ".gz") {
+		result.Compressed = true
+		name = strings.TrimSuffix(name, ".gz")
+	} else {
+		result.Compressed = bytes.HasPrefix(content, gzipMagic)
+	}
+
+	switch filepath.Ext(name) {
+	case ".json":
+		result.Format = JSON
+	case ".yaml", ".yml":
+		result.Format = YAML
+	default:
+		result.Format = sniffFormat(content, result.Compressed)
+	}
+
+	return result
+}
+
+// sniffFormat identifies the format of the content by looking at the first significant character.
+// A JSON cassette is always an object, so it starts with '{'.
+func sniffFormat(content []byte, compressed bool) Format {
+	if compressed {
+		decompressed, err := decompress(content)
+		if err != nil {
+			return YAML
+		}
+
+		content = decompressed
+	}
+
+	trimmed := bytes.TrimLeft(content, " \t\r\n")
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return JSON
+	}
+
+	return YAML
+}
@@ -0,0 +1,74 @@
+package cassettefile
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	gzipped := []byte{0x1f, 0x8b, 0x08, 0x00}
+
+	cases := map[string]struct {
+		path     string
+		content  []byte
+		expected Encoding
+	}{
+		"YAMLExtension": {
+			path:     "recordings/widget.yaml",
+			content:  []byte("{}"),
+			expected: Encoding{Format: YAML},
+		},
+		"YMLExtension": {
+			path:     "recordings/widget.yml",
+			expected: Encoding{Format: YAML},
+		},
+		"JSONExtension": {
+			path:     "recordings/widget.JSON",
+			content:  []byte("---\nversion: 2\n"),
+			expected: Encoding{Format: JSON},
+		},
+		"CompressedYAMLExtension": {
+			path:     "recordings/widget.yaml.gz",
+			expected: Encoding{Format: YAML, Compressed: true},
+		},
+		"CompressedJSONExtension": {
+			path:     "recordings/widget.json.gz",
+			expected: Encoding{Format: JSON, Compressed: true},
+		},
+		"UnknownExtensionWithYAML": {
+			path:     "recordings/widget",
+			content:  []byte("---\nversion: 2\n"),
+			expected: Encoding{Format: YAML},
+		},
+		"UnknownExtensionWithJSON": {
+			path:     "recordings/widget",
+			content:  []byte("\n  {\"version\": 2}"),
+			expected: Encoding{Format: JSON},
+		},
+		"UnknownExtensionWithGzip": {
+			path:     "recordings/widget.yaml.bak",
+			content:  gzipped,
+			expected: Encoding{Format: YAML, Compressed: true},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			g.Expect(Detect(c.path, c.content)).To(Equal(c.expected))
+		})
+	}
+}
+
+func TestEncodingString(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	g.Expect(Encoding{Format: YAML}.String()).To(Equal("yaml"))
+	g.Expect(Encoding{Format: JSON, Compressed: true}.String()).To(Equal("json+gzip"))
+}
@@ -0,0 +1,88 @@
+package cassettefile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestLoad_GivenEncoding_ReadsInteractions(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		name     string
+		encoding Encoding
+	}{
+		"YAML":               {name: "widget.yaml", encoding: Encoding{Format: YAML}},
+		"JSON":               {name: "widget.json", encoding: Encoding{Format: JSON}},
+		"CompressedYAML":     {name: "widget.yaml.gz", encoding: Encoding{Format: YAML, Compressed: true}},
+		"CompressedJSON":     {name: "widget.json.gz", encoding: Encoding{Format: JSON, Compressed: true}},
+		"SniffedJSON":        {name: "widget.cassette", encoding: Encoding{Format: JSON}},
+		"SniffedCompression": {name: "widget.json.cassette", encoding: Encoding{Format: JSON, Compressed: true}},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			path := copyTestDataAs(t, g, "widget.yaml", c.name, c.encoding)
+
+			file, err := Load(path)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			g.Expect(file.Encoding).To(Equal(c.encoding))
+			g.Expect(file.Cassette.Interactions).To(HaveLen(2))
+			g.Expect(file.Cassette.Interactions[1].Response.Body).To(Equal(`{"name":"widget","tags":"<a&b>"}`))
+		})
+	}
+}
+
+func TestSave_AfterLoad_PreservesEncoding(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	encoding := Encoding{Format: JSON, Compressed: true}
+	path := copyTestDataAs(t, g, "widget.yaml", "widget.json.gz", encoding)
+
+	file, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	file.Cassette.Interactions[0].DiscardOnSave = true
+	g.Expect(file.Save()).To(Succeed())
+
+	reloaded, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(reloaded.Encoding).To(Equal(encoding))
+	g.Expect(reloaded.Cassette.Interactions).To(HaveLen(1))
+	g.Expect(reloaded.Cassette.Interactions[0].ID).To(Equal(0))
+}
+
+func TestSaveAs_WithJSON_WritesReadableJSON(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := copyTestDataAs(t, g, "widget.yaml", "widget.json", Encoding{Format: JSON})
+
+	content, err := os.ReadFile(path)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(string(content)).To(HavePrefix("{\n  \"version\": 2,\n  \"interactions\": ["))
+	g.Expect(string(content)).To(ContainSubstring(`"duration": "250ms"`))
+	g.Expect(string(content)).To(ContainSubstring(`"code": 200`))
+	g.Expect(string(content)).To(ContainSubstring(`<a&b>`))
+}
+
+func TestLoad_WithNonCassette_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	g.Expect(os.WriteFile(path, []byte(`{"version": 7}`), 0o600)).To(Succeed())
+
+	_, err := Load(path)
+
+	g.Expect(err).To(MatchError(ContainSubstring("loading cassette")))
+}
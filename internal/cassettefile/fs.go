@@ -0,0 +1,103 @@
+package cassettefile
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/rotisserie/eris"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/atomicfile"
+)
+
+// memoryFS is a read-only cassette.FS that supplies already loaded content, regardless of the name requested.
+type memoryFS struct {
+	content []byte
+}
+
+var _ cassette.FS = &memoryFS{}
+
+// ReadFile returns the content supplied.
+func (fs *memoryFS) ReadFile(string) ([]byte, error) {
+	return fs.content, nil
+}
+
+// WriteFile always fails, as memoryFS is only used for loading.
+func (*memoryFS) WriteFile(name string, _ []byte) error {
+	return eris.Errorf("cannot write %s to a read-only filesystem", name)
+}
+
+// IsFileExists always returns true, as the content has already been loaded.
+func (*memoryFS) IsFileExists(string) bool {
+	return true
+}
+
+// ReplayFS returns a read-only cassette.FS supplying the interactions of the cassette in the current format, regardless
+// of the name requested. This allows a go-vcr recorder to replay a cassette that's already in memory. Interactions
+// marked DiscardOnSave are omitted, as they wouldn't be present once the cassette was saved.
+func ReplayFS(cas *cassette.Cassette) (cassette.FS, error) {
+	interactions := make([]*cassette.Interaction, 0, len(cas.Interactions))
+	for _, i := range cas.Interactions {
+		if !i.DiscardOnSave {
+			interactions = append(interactions, i)
+		}
+	}
+
+	content, err := currentSchema{}.encode(interactions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &memoryFS{content: content}, nil
+}
+
+// encodingFS is a write-only cassette.FS that encodes the YAML written by go-vcr before saving it to disk.
+type encodingFS struct {
+	path     string
+	encoding Encoding
+}
+
+var _ cassette.FS = &encodingFS{}
+
+// ReadFile always fails, as encodingFS is only used for saving.
+func (*encodingFS) ReadFile(name string) ([]byte, error) {
+	return nil, eris.Errorf("cannot read %s from a write-only filesystem", name)
+}
+
+// WriteFile encodes the YAML content and writes it to the configured path, replacing any existing file atomically.
+func (fs *encodingFS) WriteFile(_ string, data []byte) error {
+	content := data
+
+	if fs.encoding.Format == JSON {
+		// go-vcr prefixes the document with a YAML marker we need to remove
+		converted, err := yamlToJSON(bytes.TrimPrefix(data, []byte("---\n")))
+		if err != nil {
+			return err
+		}
+
+		content = converted
+	}
+
+	if fs.encoding.Compressed {
+		compressed, err := compress(content)
+		if err != nil {
+			return err
+		}
+
+		content = compressed
+	}
+
+	err := atomicfile.WriteFile(fs.path, content)
+	if err != nil {
+		return eris.Wrapf(err, "writing %s", fs.path)
+	}
+
+	return nil
+}
+
+// IsFileExists checks whether the configured file exists.
+func (fs *encodingFS) IsFileExists(string) bool {
+	_, err := os.Stat(fs.path)
+
+	return err == nil
+}
@@ -0,0 +1,39 @@
+package cassettefile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// copyTestData copies the named file from testdata into a temporary directory, returning the path of the copy and its
+// original content.
+func copyTestData(t *testing.T, g Gomega, name string) (string, string) {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	path := filepath.Join(t.TempDir(), name)
+	g.Expect(os.WriteFile(path, content, 0o600)).To(Succeed())
+
+	return path, string(content)
+}
+
+// copyTestDataAs copies the named cassette from testdata into a temporary directory, saved with the specified target
+// name and encoding, returning the path of the copy.
+func copyTestDataAs(t *testing.T, g Gomega, name string, target string, encoding Encoding) string {
+	t.Helper()
+
+	source, _ := copyTestData(t, g, name)
+
+	file, err := Load(source)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	path := filepath.Join(filepath.Dir(source), target)
+	g.Expect(file.SaveAs(path, encoding)).To(Succeed())
+
+	return path
+}
@@ -0,0 +1,133 @@
+package cassettefile
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/rotisserie/eris"
+	"go.yaml.in/yaml/v3"
+)
+
+// yamlToJSON converts a YAML document to indented JSON, preserving the order of keys.
+func yamlToJSON(content []byte) ([]byte, error) {
+	var document yaml.Node
+
+	err := yaml.Unmarshal(content, &document)
+	if err != nil {
+		return nil, eris.Wrap(err, "parsing YAML for conversion to JSON")
+	}
+
+	var buffer bytes.Buffer
+
+	err = writeJSON(&buffer, &document)
+	if err != nil {
+		return nil, err
+	}
+
+	var result bytes.Buffer
+
+	err = json.Indent(&result, buffer.Bytes(), "", "  ")
+	if err != nil {
+		return nil, eris.Wrap(err, "formatting JSON")
+	}
+
+	result.WriteByte('\n')
+
+	return result.Bytes(), nil
+}
+
+// writeJSON writes the YAML node to the buffer as compact JSON.
+func writeJSON(buffer *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buffer.WriteString("null")
+
+			return nil
+		}
+
+		return writeJSON(buffer, node.Content[0])
+
+	case yaml.MappingNode:
+		buffer.WriteByte('{')
+
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			if index > 0 {
+				buffer.WriteByte(',')
+			}
+
+			writeString(buffer, node.Content[index].Value)
+			buffer.WriteByte(':')
+
+			err := writeJSON(buffer, node.Content[index+1])
+			if err != nil {
+				return err
+			}
+		}
+
+		buffer.WriteByte('}')
+
+	case yaml.SequenceNode:
+		buffer.WriteByte('[')
+
+		for index, item := range node.Content {
+			if index > 0 {
+				buffer.WriteByte(',')
+			}
+
+			err := writeJSON(buffer, item)
+			if err != nil {
+				return err
+			}
+		}
+
+		buffer.WriteByte(']')
+
+	case yaml.ScalarNode:
+		writeScalar(buffer, node)
+
+	case yaml.AliasNode:
+		return writeJSON(buffer, node.Alias)
+
+	default:
+		return eris.Errorf("unexpected YAML node kind %d at line %d", node.Kind, node.Line)
+	}
+
+	return nil
+}
+
+// writeScalar writes a scalar YAML node as the equivalent JSON value.
+func writeScalar(buffer *bytes.Buffer, node *yaml.Node) {
+	switch node.ShortTag() {
+	case "!!null":
+		buffer.WriteString("null")
+	case "!!bool":
+		var value bool
+		if node.Decode(&value) == nil && value {
+			buffer.WriteString("true")
+		} else {
+			buffer.WriteString("false")
+		}
+	case "!!int", "!!float":
+		// YAML numbers that aren't valid JSON numbers (e.g. .inf, 0x1F) are written as strings
+		if json.Valid([]byte(node.Value)) {
+			buffer.WriteString(node.Value)
+		} else {
+			writeString(buffer, node.Value)
+		}
+	default:
+		writeString(buffer, node.Value)
+	}
+}
+
+// writeString writes the value as a JSON string, without escaping HTML characters.
+func writeString(buffer *bytes.Buffer, value string) {
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+
+	// Encoding a string can't fail
+	_ = encoder.Encode(value)
+
+	// Remove the newline added by Encode
+	buffer.Truncate(buffer.Len() - 1)
+}
@@ -0,0 +1,152 @@
+package cassettefile
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/rotisserie/eris"
+	"go.yaml.in/yaml/v3"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+)
+
+// legacyCassette is a cassette as written by go-vcr v1 and v2 (format version 1).
+type legacyCassette struct {
+	Version      int                  `yaml:"version"`
+	Interactions []*legacyInteraction `yaml:"interactions"`
+}
+
+// legacyInteraction is a single interaction in format version 1, which doesn't record IDs.
+type legacyInteraction struct {
+	Request  legacyRequest  `yaml:"request"`
+	Response legacyResponse `yaml:"response"`
+}
+
+// legacyRequest is a recorded request in format version 1.
+type legacyRequest struct {
+	Body    string      `yaml:"body"`
+	Form    url.Values  `yaml:"form"`
+	Headers http.Header `yaml:"headers"`
+	URL     string      `yaml:"url"`
+	Method  string      `yaml:"method"`
+}
+
+// legacyResponse is a recorded response in format version 1, where the duration is stored as a string.
+type legacyResponse struct {
+	Body     string      `yaml:"body"`
+	Headers  http.Header `yaml:"headers"`
+	Status   string      `yaml:"status"`
+	Code     int         `yaml:"code"`
+	Duration string      `yaml:"duration"`
+}
+
+// legacySchema is the format written by go-vcr v1 and v2.
+type legacySchema struct{}
+
+var _ schema = legacySchema{}
+
+func (legacySchema) decode(content []byte) ([]*cassette.Interaction, error) {
+	var doc legacyCassette
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, eris.Wrap(err, "decoding legacy cassette")
+	}
+
+	if doc.Version != LegacyVersion {
+		return nil, eris.Errorf("expected format version %d, found %d", LegacyVersion, doc.Version)
+	}
+
+	result := make([]*cassette.Interaction, len(doc.Interactions))
+	for id, i := range doc.Interactions {
+		result[id] = i.interaction(id)
+	}
+
+	return result, nil
+}
+
+func (legacySchema) encode(interactions []*cassette.Interaction) ([]byte, error) {
+	doc := legacyCassette{
+		Version:      LegacyVersion,
+		Interactions: make([]*legacyInteraction, len(interactions)),
+	}
+
+	for index, i := range interactions {
+		doc.Interactions[index] = legacyInteractionOf(i)
+	}
+
+	content, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, eris.Wrap(err, "encoding legacy cassette")
+	}
+
+	return append([]byte("---\n"), content...), nil
+}
+
+func (legacySchema) decodeInteraction(node *yaml.Node) (*cassette.Interaction, error) {
+	var result legacyInteraction
+	if err := node.Decode(&result); err != nil {
+		return nil, eris.Wrap(err, "decoding legacy interaction")
+	}
+
+	return result.interaction(0), nil
+}
+
+func (legacySchema) hasIDs() bool {
+	return false
+}
+
+func (legacySchema) duration(d time.Duration) any {
+	return d.String()
+}
+
+// interaction converts the legacy interaction to the current model, with the specified ID.
+func (l *legacyInteraction) interaction(id int) *cassette.Interaction {
+	// Older recordings may have no duration; treat anything we can't parse as unknown
+	duration, err := time.ParseDuration(l.Response.Duration)
+	if err != nil {
+		duration = 0
+	}
+
+	host := ""
+	if u, err := url.Parse(l.Request.URL); err == nil {
+		host = u.Host
+	}
+
+	return &cassette.Interaction{
+		ID: id,
+		Request: cassette.Request{
+			Body:    l.Request.Body,
+			Form:    l.Request.Form,
+			Headers: l.Request.Headers,
+			URL:     l.Request.URL,
+			Method:  l.Request.Method,
+			Host:    host,
+		},
+		Response: cassette.Response{
+			Body:     l.Response.Body,
+			Headers:  l.Response.Headers,
+			Status:   l.Response.Status,
+			Code:     l.Response.Code,
+			Duration: duration,
+		},
+	}
+}
+
+// legacyInteractionOf converts an interaction to format version 1, dropping anything that format can't record.
+func legacyInteractionOf(i *cassette.Interaction) *legacyInteraction {
+	return &legacyInteraction{
+		Request: legacyRequest{
+			Body:    i.Request.Body,
+			Form:    i.Request.Form,
+			Headers: i.Request.Headers,
+			URL:     i.Request.URL,
+			Method:  i.Request.Method,
+		},
+		Response: legacyResponse{
+			Body:     i.Response.Body,
+			Headers:  i.Response.Headers,
+			Status:   i.Response.Status,
+			Code:     i.Response.Code,
+			Duration: i.Response.Duration.String(),
+		},
+	}
+}
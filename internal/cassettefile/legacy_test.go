@@ -0,0 +1,136 @@
+package cassettefile
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestLoad_WithLegacyCassette_ConvertsInteractions(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path, _ := copyTestData(t, g, "legacy.yaml")
+
+	file, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(file.Version).To(Equal(LegacyVersion))
+
+	interactions := file.Cassette.Interactions
+	g.Expect(interactions).To(HaveLen(3))
+	g.Expect(interactions[1].ID).To(Equal(1))
+	g.Expect(interactions[1].Request.Method).To(Equal("GET"))
+	g.Expect(interactions[1].Request.Host).To(Equal("api.example.com"))
+	g.Expect(interactions[1].Response.Code).To(Equal(200))
+	g.Expect(interactions[1].Response.Body).To(Equal(`{"name":"widget"}`))
+	g.Expect(interactions[1].Response.Duration).To(Equal(1500 * time.Millisecond))
+	g.Expect(interactions[2].Response.Duration).To(BeZero())
+}
+
+func TestSave_WithLegacyCassette_PreservesFormat(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path, original := copyTestData(t, g, "legacy.yaml")
+
+	file, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	interactions := file.Cassette.Interactions
+	interactions[0].DiscardOnSave = true
+	interactions[1].Response.Headers.Set("Retry-After", "0")
+	interactions[1].Response.Duration = 100 * time.Millisecond
+
+	g.Expect(file.Save()).To(Succeed())
+
+	// Legacy interactions have no IDs, so removing one changes no other lines
+	lines := strings.SplitAfter(original, "\n")
+	first := indexOfLine(lines, "- request:")
+	second := first + 1 + indexOfLine(lines[first+1:], "- request:")
+
+	kept := append([]string{}, lines[:first]...)
+	kept = append(kept, lines[second:]...)
+	expected := strings.Join(kept, "")
+	expected = strings.Replace(expected, "      - \"10\"\n", "      - \"0\"\n", 1)
+	expected = strings.Replace(expected, "    duration: 1.5s\n", "    duration: 100ms\n", 1)
+
+	content, err := os.ReadFile(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(content)).To(Equal(expected))
+}
+
+func TestSave_WithLegacyCassetteNeedingFullMarshal_PreservesFormat(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path, _ := copyTestData(t, g, "legacy.yaml")
+
+	file, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// Added headers can't be patched in place
+	file.Cassette.Interactions[2].Response.Headers.Set("Retry-After", "0")
+	g.Expect(file.Save()).To(Succeed())
+
+	content, err := os.ReadFile(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(content)).To(HavePrefix("---\nversion: 1\n"))
+	g.Expect(string(content)).ToNot(ContainSubstring("id:"))
+
+	reloaded, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(reloaded.Version).To(Equal(LegacyVersion))
+	g.Expect(reloaded.Cassette.Interactions).To(HaveLen(3))
+	g.Expect(reloaded.Cassette.Interactions[2].Response.Headers.Get("Retry-After")).To(Equal("0"))
+}
+
+func TestUpgrade_WithLegacyCassette_SavesInCurrentFormat(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path, _ := copyTestData(t, g, "legacy.yaml")
+
+	file, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	file.Cassette.Interactions[0].DiscardOnSave = true
+
+	g.Expect(file.Upgrade()).To(BeTrue())
+	g.Expect(file.Save()).To(Succeed())
+
+	content, err := os.ReadFile(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(content)).To(HavePrefix("---\nversion: 2\n"))
+
+	reloaded, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(reloaded.Version).To(Equal(CurrentVersion))
+	g.Expect(reloaded.Cassette.Interactions).To(HaveLen(2))
+	g.Expect(reloaded.Cassette.Interactions[0].ID).To(Equal(0))
+	g.Expect(reloaded.Cassette.Interactions[0].Response.Duration).To(Equal(1500 * time.Millisecond))
+}
+
+func TestUpgrade_WithCurrentCassette_ReturnsFalse(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path, _ := copyTestData(t, g, "handwritten.yaml")
+
+	file, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(file.Upgrade()).To(BeFalse())
+}
+
+func TestCanStream_WithLegacyCassette_ReturnsFalse(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path, _ := copyTestData(t, g, "legacy.yaml")
+
+	g.Expect(CanStream(path)).To(BeFalse())
+}
@@ -0,0 +1,397 @@
+package cassettefile
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rotisserie/eris"
+	"go.yaml.in/yaml/v3"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+)
+
+// lineEdit replaces a range of lines in a file.
+type lineEdit struct {
+	start       int      // Index of the first line replaced
+	end         int      // Index of the line after the last line replaced
+	replacement []string // Replacement lines, including line endings
+}
+
+// patcher edits the original YAML of a cassette so it matches the cassette in memory, changing as few lines as
+// possible. Discarded interactions are removed, IDs renumbered to close any gaps (as go-vcr does) and changed header
+// values and durations rewritten; every other line is left byte-for-byte identical.
+type patcher struct {
+	lines  []string           // Lines of the original file, including line endings
+	order  map[*yaml.Node]int // Position of each node in document order
+	nodes  []*yaml.Node       // Every node, in document order
+	last   map[*yaml.Node]int // Position of the last descendant of each node, in document order
+	schema schema             // Layout of interactions in the file
+	edits  []lineEdit
+}
+
+// patchYAML returns the original content edited to match the cassette, along with the interactions retained.
+// loaded are the interactions of the cassette as loaded from original, in order; any no longer present in the
+// cassette, or marked DiscardOnSave, are removed. s describes how interactions are laid out in original.
+// Returns false if the content can't be patched (e.g. because it isn't laid out as go-vcr writes it, or because
+// changes other than those listed above were made), in which case the cassette must be marshalled in full.
+func patchYAML(
+	original []byte,
+	loaded []*cassette.Interaction,
+	cas *cassette.Cassette,
+	s schema,
+) ([]byte, []*cassette.Interaction, bool) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(original, &root); err != nil {
+		return nil, nil, false
+	}
+
+	interactions, ok := sequenceOf(&root, "interactions")
+	if !ok || len(interactions.Content) != len(loaded) {
+		return nil, nil, false
+	}
+
+	p := newPatcher(original, &root, s)
+
+	present := make(map[*cassette.Interaction]bool, len(cas.Interactions))
+	for _, i := range cas.Interactions {
+		present[i] = true
+	}
+
+	retained := make([]*cassette.Interaction, 0, len(loaded))
+	expected := make([]*cassette.Interaction, 0, len(loaded))
+
+	for index, i := range loaded {
+		node := interactions.Content[index]
+
+		if i.DiscardOnSave || !present[i] {
+			p.remove(node, node)
+
+			continue
+		}
+
+		id := len(retained)
+		if !p.patchInteraction(node, i, id) {
+			return nil, nil, false
+		}
+
+		copied := *i
+		copied.ID = id
+		retained = append(retained, i)
+		expected = append(expected, &copied)
+	}
+
+	if len(retained) == 0 {
+		// An empty sequence can't be expressed by removing lines
+		return nil, nil, false
+	}
+
+	result := p.apply()
+
+	// Check the patched content reads back exactly as the cassette would if marshalled in full
+	if !readsAs(s, result, expected) {
+		return nil, nil, false
+	}
+
+	return result, retained, true
+}
+
+// patchInteraction returns the text of a single interaction, as split from a streamed cassette, edited to match the
+// interaction with the specified ID. If the text can't be patched, the interaction is marshalled in full at the same
+// indentation.
+func patchInteraction(content []byte, i *cassette.Interaction, id int) ([]byte, error) {
+	expected := *i
+	expected.ID = id
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, eris.Wrap(err, "decoding interaction")
+	}
+
+	if root.Kind == yaml.DocumentNode && len(root.Content) == 1 && len(root.Content[0].Content) == 1 {
+		p := newPatcher(content, &root, currentSchema{})
+		if p.patchInteraction(root.Content[0].Content[0], i, id) {
+			result := p.apply()
+			if decodesAs(result, []*cassette.Interaction{&expected}) {
+				return result, nil
+			}
+		}
+	}
+
+	marshalled, err := yaml.Marshal([]*cassette.Interaction{&expected})
+	if err != nil {
+		return nil, eris.Wrap(err, "encoding interaction")
+	}
+
+	return indentLines(marshalled, indentationOf(content)), nil
+}
+
+func newPatcher(content []byte, root *yaml.Node, s schema) *patcher {
+	result := &patcher{
+		order:  make(map[*yaml.Node]int),
+		last:   make(map[*yaml.Node]int),
+		schema: s,
+	}
+
+	for line := range bytes.Lines(content) {
+		result.lines = append(result.lines, string(line))
+	}
+
+	result.index(root)
+
+	return result
+}
+
+// index records the position of the node and its descendants in document order.
+func (p *patcher) index(node *yaml.Node) {
+	p.order[node] = len(p.nodes)
+	p.nodes = append(p.nodes, node)
+
+	for _, child := range node.Content {
+		p.index(child)
+	}
+
+	p.last[node] = len(p.nodes) - 1
+}
+
+// endLine returns the index of the line following the last line occupied by the node and its descendants.
+func (p *patcher) endLine(node *yaml.Node) int {
+	next := p.last[node] + 1
+	if next < len(p.nodes) {
+		return p.nodes[next].Line - 1
+	}
+
+	return len(p.lines)
+}
+
+// remove deletes the lines from the start of first to the end of last.
+func (p *patcher) remove(first *yaml.Node, last *yaml.Node) {
+	p.edits = append(p.edits, lineEdit{
+		start: first.Line - 1,
+		end:   p.endLine(last),
+	})
+}
+
+// replaceScalar rewrites a scalar that ends its line, keeping whatever precedes it on the line.
+// Returns false if the scalar doesn't occupy the remainder of its line.
+func (p *patcher) replaceScalar(node *yaml.Node, value any) bool {
+	if node.Kind != yaml.ScalarNode || node.Line < 1 || node.Line > len(p.lines) {
+		return false
+	}
+
+	line := p.lines[node.Line-1]
+	content := trimLineEnding(line)
+	ending := line[len(content):]
+
+	column := node.Column - 1
+	if column < 0 || column > len(content) {
+		return false
+	}
+
+	// Confirm the remainder of the line is exactly the scalar, with no trailing comment
+	var existing string
+	if err := yaml.Unmarshal([]byte(content[column:]), &existing); err != nil || existing != node.Value {
+		return false
+	}
+
+	encoded, err := yaml.Marshal(value)
+	if err != nil {
+		return false
+	}
+
+	p.edits = append(p.edits, lineEdit{
+		start:       node.Line - 1,
+		end:         node.Line,
+		replacement: []string{content[:column] + trimLineEnding(string(encoded)) + ending},
+	})
+
+	return true
+}
+
+// patchInteraction rewrites the ID, duration and response headers of an interaction, where they've changed.
+func (p *patcher) patchInteraction(node *yaml.Node, i *cassette.Interaction, id int) bool {
+	original, err := p.schema.decodeInteraction(node)
+	if err != nil {
+		return false
+	}
+
+	if p.schema.hasIDs() && original.ID != id {
+		value, ok := valueOf(node, "id")
+		if !ok || !p.replaceScalar(value, id) {
+			return false
+		}
+	}
+
+	response, ok := valueOf(node, "response")
+	if !ok {
+		return false
+	}
+
+	if original.Response.Duration != i.Response.Duration {
+		value, ok := valueOf(response, "duration")
+		if !ok || !p.replaceScalar(value, p.schema.duration(i.Response.Duration)) {
+			return false
+		}
+	}
+
+	if !reflect.DeepEqual(original.Response.Headers, i.Response.Headers) {
+		headers, ok := valueOf(response, "headers")
+		if !ok || !p.patchHeaders(headers, i.Response.Headers) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// patchHeaders rewrites a block mapping of headers to match the specified headers.
+// Headers are only removed or changed in place; added headers can't be patched.
+func (p *patcher) patchHeaders(node *yaml.Node, headers map[string][]string) bool {
+	if node.Kind != yaml.MappingNode || node.Style&yaml.FlowStyle != 0 {
+		return false
+	}
+
+	seen := make(map[string]bool, len(headers))
+
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		key := node.Content[index]
+		values := node.Content[index+1]
+		seen[key.Value] = true
+
+		replacement, ok := headers[key.Value]
+		if !ok {
+			p.remove(key, values)
+
+			continue
+		}
+
+		if !p.patchValues(values, replacement) {
+			return false
+		}
+	}
+
+	for name := range headers {
+		if !seen[name] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// patchValues rewrites the values of a single header in place.
+// Returns false if the number of values has changed.
+func (p *patcher) patchValues(node *yaml.Node, values []string) bool {
+	if node.Kind != yaml.SequenceNode || node.Style&yaml.FlowStyle != 0 || len(node.Content) != len(values) {
+		return false
+	}
+
+	for index, item := range node.Content {
+		if item.Value == values[index] {
+			continue
+		}
+
+		if !p.replaceScalar(item, values[index]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// apply returns the content with all edits made.
+func (p *patcher) apply() []byte {
+	// Apply edits from the end of the file, so earlier line numbers stay valid
+	sort.Slice(p.edits, func(i, j int) bool {
+		return p.edits[i].start > p.edits[j].start
+	})
+
+	lines := slices.Clone(p.lines)
+	for _, edit := range p.edits {
+		lines = slices.Replace(lines, edit.start, edit.end, edit.replacement...)
+	}
+
+	var buffer bytes.Buffer
+	for _, line := range lines {
+		buffer.WriteString(line)
+	}
+
+	return buffer.Bytes()
+}
+
+// sequenceOf returns the block sequence stored under key in the root mapping of a document.
+func sequenceOf(root *yaml.Node, key string) (*yaml.Node, bool) {
+	if root.Kind != yaml.DocumentNode || len(root.Content) != 1 {
+		return nil, false
+	}
+
+	result, ok := valueOf(root.Content[0], key)
+	if !ok || result.Kind != yaml.SequenceNode || result.Style&yaml.FlowStyle != 0 {
+		return nil, false
+	}
+
+	return result, true
+}
+
+// valueOf returns the value stored under key in a block mapping.
+func valueOf(mapping *yaml.Node, key string) (*yaml.Node, bool) {
+	if mapping.Kind != yaml.MappingNode || mapping.Style&yaml.FlowStyle != 0 {
+		return nil, false
+	}
+
+	for index := 0; index+1 < len(mapping.Content); index += 2 {
+		if mapping.Content[index].Value == key {
+			return mapping.Content[index+1], true
+		}
+	}
+
+	return nil, false
+}
+
+// decodesAs returns true if content decodes to the same value as expected, when marshalled in full, decodes to.
+func decodesAs[T any](content []byte, expected T) bool {
+	marshalled, err := yaml.Marshal(expected)
+	if err != nil {
+		return false
+	}
+
+	var want, got T
+	if yaml.Unmarshal(marshalled, &want) != nil || yaml.Unmarshal(content, &got) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(want, got)
+}
+
+// indentationOf returns the indentation of the first line of content that isn't blank.
+func indentationOf(content []byte) string {
+	for line := range bytes.Lines(content) {
+		trimmed := bytes.TrimLeft(line, " ")
+		if len(bytes.TrimSpace(trimmed)) > 0 {
+			return string(line[:len(line)-len(trimmed)])
+		}
+	}
+
+	return ""
+}
+
+// indentLines prefixes each line of content with the specified indentation.
+func indentLines(content []byte, indentation string) []byte {
+	var buffer bytes.Buffer
+	for line := range bytes.Lines(content) {
+		buffer.WriteString(indentation)
+		buffer.Write(line)
+	}
+
+	return buffer.Bytes()
+}
+
+// trimLineEnding removes any trailing line ending.
+func trimLineEnding(line string) string {
+	if trimmed, ok := strings.CutSuffix(line, "\r\n"); ok {
+		return trimmed
+	}
+
+	return strings.TrimSuffix(line, "\n")
+}
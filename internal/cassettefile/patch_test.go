@@ -0,0 +1,97 @@
+package cassettefile
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSave_WithNoChanges_LeavesFileIdentical(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path, original := copyTestData(t, g, "handwritten.yaml")
+
+	file, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(file.Save()).To(Succeed())
+
+	content, err := os.ReadFile(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(content)).To(Equal(original))
+}
+
+func TestSave_WithRemovalsAndRewrites_ChangesOnlyAffectedLines(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path, original := copyTestData(t, g, "handwritten.yaml")
+
+	file, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	interactions := file.Cassette.Interactions
+	interactions[0].DiscardOnSave = true
+	interactions[1].Response.Headers.Set("Retry-After", "0")
+	interactions[1].Response.Headers.Del("X-Request-Id")
+	interactions[1].Response.Duration = 100 * time.Millisecond
+
+	g.Expect(file.Save()).To(Succeed())
+
+	// Build the expected content by editing the original text directly
+	lines := strings.SplitAfter(original, "\n")
+	first := indexOfLine(lines, "  - id: 0")
+	second := indexOfLine(lines, "  - id: 1")
+
+	kept := append([]string{}, lines[:first]...)
+	kept = append(kept, lines[second:]...)
+	expected := strings.Join(kept, "")
+	expected = strings.Replace(expected, "  - id: 1\n", "  - id: 0\n", 1)
+	expected = strings.Replace(expected, "  - id: 2\n", "  - id: 1\n", 1)
+	expected = strings.Replace(expected, "          - \"10\"\n", "          - \"0\"\n", 1)
+	expected = strings.Replace(expected, "        X-Request-Id:\n          - \"d1b2c3\"\n", "", 1)
+	expected = strings.Replace(expected, "      duration: 1.5s\n", "      duration: 100ms\n", 1)
+
+	content, err := os.ReadFile(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(content)).To(Equal(expected))
+
+	// The cassette in memory matches the file, as it would after a full save
+	g.Expect(file.Cassette.Interactions).To(HaveLen(2))
+	g.Expect(file.Cassette.Interactions[1].ID).To(Equal(1))
+}
+
+func TestSave_WithAddedHeader_MarshalsInFull(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path, original := copyTestData(t, g, "handwritten.yaml")
+
+	file, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	file.Cassette.Interactions[2].Response.Headers.Set("Retry-After", "0")
+	g.Expect(file.Save()).To(Succeed())
+
+	content, err := os.ReadFile(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(content)).ToNot(Equal(original))
+
+	reloaded, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(reloaded.Cassette.Interactions[2].Response.Headers.Get("Retry-After")).To(Equal("0"))
+}
+
+// indexOfLine returns the index of the first line with the specified content, ignoring the line ending.
+func indexOfLine(lines []string, content string) int {
+	for index, line := range lines {
+		if strings.TrimSuffix(line, "\n") == content {
+			return index
+		}
+	}
+
+	return -1
+}
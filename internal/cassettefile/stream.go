@@ -0,0 +1,582 @@
+package cassettefile
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rotisserie/eris"
+	"go.yaml.in/yaml/v3"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+)
+
+// Streaming support for very large cassettes.
+// Rather than decoding the whole file, the text of each interaction is split out and decoded in turn, so only one
+// interaction needs to be held in memory at a time. This relies on the cassette being a YAML block sequence, as
+// go-vcr writes it.
+
+// section identifies a part of a streamed cassette.
+type section int
+
+const (
+	prologueSection    section = iota // Everything up to and including the interactions key
+	interactionSection                // A single interaction
+	epilogueSection                   // Anything following the interactions
+)
+
+// sniffLength is the amount of content read to detect the encoding of a streamed cassette.
+const sniffLength = 4096
+
+// interactionsKey introduces the sequence of interactions in a cassette.
+var interactionsKey = []byte("interactions:")
+
+// errPrologueRead stops splitting a cassette once the prologue has been read.
+var errPrologueRead = errors.New("prologue read")
+
+// CanStream returns true if the cassette at path can be streamed, which requires it to be stored as YAML (optionally
+// compressed) in the current format version. Cassettes recorded by older versions of go-vcr must be loaded in full.
+func CanStream(path string) bool {
+	encoding, err := detectPrefix(path)
+	if err != nil || encoding.Format != YAML {
+		return false
+	}
+
+	reader, err := openStream(path)
+	if err != nil {
+		return false
+	}
+
+	defer reader.Close()
+
+	// Only the prologue needs to be read to find the version, as go-vcr declares it first
+	var version versionCheck
+
+	err = splitCassette(reader, func(_ section, content []byte) error {
+		if err := version.observe(content); err != nil {
+			return err
+		}
+
+		return errPrologueRead
+	})
+
+	return errors.Is(err, errPrologueRead)
+}
+
+// EachInteraction decodes the interactions of the YAML cassette at path one at a time, passing each to visit.
+// Returns an error if the file can't be read, isn't a cassette, or if visit returns an error.
+func EachInteraction(path string, visit func(*cassette.Interaction) error) error {
+	reader, err := openStream(path)
+	if err != nil {
+		return err
+	}
+
+	defer reader.Close()
+
+	var version versionCheck
+
+	err = splitCassette(reader, func(kind section, content []byte) error {
+		if kind != interactionSection {
+			return version.observe(content)
+		}
+
+		i, err := decodeInteraction(content)
+		if err != nil {
+			return err
+		}
+
+		return visit(i)
+	})
+	if err != nil {
+		return eris.Wrapf(err, "streaming cassette %s", path)
+	}
+
+	return version.verify(path)
+}
+
+// Rewrite streams the YAML cassette at path through edit, replacing the file with the result.
+// edit returns false if the interaction should be removed; it may also change the response headers or duration of the
+// interaction. As with Save, retained interactions are renumbered and every line not affected by a change is left
+// byte-for-byte identical. The file is replaced only once the new content has been written in full.
+// Returns the number of interactions retained.
+func Rewrite(path string, edit func(*cassette.Interaction) (bool, error)) (int, error) {
+	encoding, err := detectPrefix(path)
+	if err != nil {
+		return 0, err
+	}
+
+	reader, err := openStream(path)
+	if err != nil {
+		return 0, err
+	}
+
+	defer reader.Close()
+
+	output, err := newStreamWriter(path, encoding)
+	if err != nil {
+		return 0, err
+	}
+
+	// Clean up the temporary file if we fail before replacing the original
+	defer output.discard()
+
+	r := &rewriter{
+		edit:   edit,
+		output: output,
+	}
+
+	err = splitCassette(reader, r.handle)
+	if err != nil {
+		return 0, eris.Wrapf(err, "rewriting cassette %s", path)
+	}
+
+	err = r.version.verify(path)
+	if err != nil {
+		return 0, err
+	}
+
+	err = output.commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return r.retained, nil
+}
+
+// rewriter writes each section of a streamed cassette, editing interactions as they pass.
+type rewriter struct {
+	edit     func(*cassette.Interaction) (bool, error) // Edit to apply to each interaction
+	output   *streamWriter                             // Destination for the rewritten cassette
+	prologue []byte                                    // Prologue, held back until the first interaction is retained
+	retained int                                       // Number of interactions retained so far
+	version  versionCheck
+}
+
+// handle rewrites a single section of the cassette.
+func (r *rewriter) handle(kind section, content []byte) error {
+	switch kind {
+	case prologueSection:
+		r.prologue = bytes.Clone(content)
+
+		return r.version.observe(content)
+
+	case epilogueSection:
+		if err := r.version.observe(content); err != nil {
+			return err
+		}
+
+		if r.retained == 0 {
+			// Nothing was retained, so the prologue is still pending
+			return r.output.write(emptyInteractions(r.prologue), content)
+		}
+
+		return r.output.write(content)
+
+	default:
+		return r.interaction(content)
+	}
+}
+
+// interaction decodes and edits a single interaction, writing it if retained.
+func (r *rewriter) interaction(content []byte) error {
+	i, err := decodeInteraction(content)
+	if err != nil {
+		return err
+	}
+
+	keep, err := r.edit(i)
+	if err != nil || !keep {
+		return err
+	}
+
+	patched, err := patchInteraction(content, i, r.retained)
+	if err != nil {
+		return err
+	}
+
+	if r.retained == 0 {
+		err = r.output.write(r.prologue, patched)
+	} else {
+		err = r.output.write(patched)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	r.retained++
+
+	return nil
+}
+
+// splitCassette reads a YAML cassette line by line, passing each section to handle in order: the prologue, each
+// interaction and finally the epilogue (which may be empty).
+// Returns an error if the content isn't laid out as a block sequence of interactions.
+func splitCassette(reader io.Reader, handle func(section, []byte) error) error {
+	lines := bufio.NewReaderSize(reader, 64*1024)
+	s := &splitter{
+		handle: handle,
+		indent: -1,
+	}
+
+	for {
+		line, err := lines.ReadBytes('\n')
+		if len(line) > 0 {
+			if lineErr := s.line(line); lineErr != nil {
+				return lineErr
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return eris.Wrap(err, "reading cassette")
+		}
+	}
+
+	return s.finish()
+}
+
+// splitter tracks progress through a cassette as it's split into sections.
+type splitter struct {
+	handle  func(section, []byte) error // Handler for each section
+	kind    section                     // Kind of section currently being read
+	indent  int                         // Indentation of the sequence of interactions, once known
+	started bool                        // True once the current interaction has started
+	current bytes.Buffer                // Content of the current section
+}
+
+// line adds a line to the current section, passing on any section completed.
+func (s *splitter) line(line []byte) error {
+	switch s.kind {
+	case prologueSection:
+		s.current.Write(line)
+
+		trimmed := bytes.TrimRight(line, " \t\r\n")
+		if !bytes.HasPrefix(trimmed, interactionsKey) {
+			return nil
+		}
+
+		if len(trimmed) != len(interactionsKey) {
+			return eris.New("interactions are not stored as a block sequence")
+		}
+
+		err := s.handle(prologueSection, s.current.Bytes())
+		s.current.Reset()
+		s.kind = interactionSection
+
+		return err
+
+	case interactionSection:
+		return s.interactionLine(line)
+
+	default:
+		s.current.Write(line)
+
+		return nil
+	}
+}
+
+// interactionLine adds a line within the sequence of interactions, passing on the current interaction when the next
+// one starts, or when the sequence ends.
+func (s *splitter) interactionLine(line []byte) error {
+	trimmed := bytes.TrimLeft(line, " ")
+	if len(bytes.TrimSpace(trimmed)) == 0 || trimmed[0] == '#' {
+		// Blank lines and comments stay with the current interaction
+		s.current.Write(line)
+
+		return nil
+	}
+
+	depth := len(line) - len(trimmed)
+	entry := trimmed[0] == '-' && (len(trimmed) == 1 || trimmed[1] == ' ' || trimmed[1] == '\n' || trimmed[1] == '\r')
+
+	if s.indent < 0 && entry {
+		s.indent = depth
+	}
+
+	if s.indent < 0 || depth < s.indent || (depth == s.indent && !entry) {
+		// The sequence has ended; everything else is part of the epilogue
+		if err := s.emitInteraction(); err != nil {
+			return err
+		}
+
+		s.kind = epilogueSection
+		s.current.Write(line)
+
+		return nil
+	}
+
+	if depth == s.indent {
+		// A new interaction starts
+		if err := s.emitInteraction(); err != nil {
+			return err
+		}
+
+		s.started = true
+	}
+
+	s.current.Write(line)
+
+	return nil
+}
+
+// emitInteraction passes on the current interaction, if one has started.
+// Otherwise any blank lines or comments read are kept for the next section.
+func (s *splitter) emitInteraction() error {
+	if !s.started {
+		return nil
+	}
+
+	err := s.handle(interactionSection, s.current.Bytes())
+	s.current.Reset()
+	s.started = false
+
+	return err
+}
+
+// finish passes on any remaining sections once the whole cassette has been read.
+func (s *splitter) finish() error {
+	switch s.kind {
+	case prologueSection:
+		return eris.New("no interactions found")
+
+	case interactionSection:
+		if err := s.emitInteraction(); err != nil {
+			return err
+		}
+	}
+
+	return s.handle(epilogueSection, s.current.Bytes())
+}
+
+// versionCheck confirms a streamed cassette declares a supported format version, wherever the declaration appears.
+type versionCheck struct {
+	version int
+}
+
+// observe looks for a version declaration in a section of the cassette outside the interactions.
+func (v *versionCheck) observe(content []byte) error {
+	var header struct {
+		Version *int `yaml:"version"`
+	}
+
+	err := yaml.Unmarshal(content, &header)
+	if err != nil {
+		return eris.Wrap(err, "decoding cassette header")
+	}
+
+	if header.Version != nil {
+		v.version = *header.Version
+		if v.version != cassette.CassetteFormatVersion {
+			return eris.Errorf("unsupported format version %d", v.version)
+		}
+	}
+
+	return nil
+}
+
+// verify returns an error if the cassette didn't declare a supported version.
+func (v *versionCheck) verify(path string) error {
+	if v.version != cassette.CassetteFormatVersion {
+		return eris.Errorf("cassette %s has unsupported format version %d", path, v.version)
+	}
+
+	return nil
+}
+
+// decodeInteraction decodes the text of a single interaction, as split from a cassette.
+func decodeInteraction(content []byte) (*cassette.Interaction, error) {
+	var items []*cassette.Interaction
+
+	err := yaml.Unmarshal(content, &items)
+	if err != nil {
+		return nil, eris.Wrap(err, "decoding interaction")
+	}
+
+	if len(items) != 1 || items[0] == nil {
+		return nil, eris.Errorf("expected a single interaction, found %d", len(items))
+	}
+
+	return items[0], nil
+}
+
+// emptyInteractions rewrites the interactions key at the end of the prologue to hold an empty sequence.
+func emptyInteractions(prologue []byte) []byte {
+	index := bytes.LastIndex(prologue, interactionsKey)
+	if index < 0 {
+		return prologue
+	}
+
+	end := index + len(interactionsKey)
+
+	result := bytes.Clone(prologue[:end])
+	result = append(result, " []"...)
+
+	return append(result, prologue[end:]...)
+}
+
+// openStream opens the cassette at path for reading, decompressing it if needed.
+func openStream(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, eris.Wrapf(err, "opening cassette file %s", path)
+	}
+
+	buffered := bufio.NewReader(file)
+
+	magic, err := buffered.Peek(len(gzipMagic))
+	if err != nil || !bytes.Equal(magic, gzipMagic) {
+		return &streamReader{Reader: buffered, closers: []io.Closer{file}}, nil
+	}
+
+	decompressed, err := gzip.NewReader(buffered)
+	if err != nil {
+		_ = file.Close()
+
+		return nil, eris.Wrapf(err, "decompressing cassette file %s", path)
+	}
+
+	return &streamReader{Reader: decompressed, closers: []io.Closer{decompressed, file}}, nil
+}
+
+// streamReader reads a cassette, closing any underlying readers when done.
+type streamReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// Close closes all the underlying readers.
+func (r *streamReader) Close() error {
+	var errs []error
+	for _, closer := range r.closers {
+		errs = append(errs, closer.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// detectPrefix determines the encoding of the cassette at path from its extension and the start of its content.
+func detectPrefix(path string) (Encoding, error) {
+	reader, err := openStream(path)
+	if err != nil {
+		return Encoding{}, err
+	}
+
+	defer reader.Close()
+
+	prefix := make([]byte, sniffLength)
+
+	n, err := io.ReadFull(reader, prefix)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return Encoding{}, eris.Wrapf(err, "reading cassette file %s", path)
+	}
+
+	// The prefix has already been decompressed, so we detect the format as if the file wasn't compressed
+	result := Detect(strings.TrimSuffix(path, ".gz"), prefix[:n])
+	result.Compressed = isCompressed(reader)
+
+	return result, nil
+}
+
+// isCompressed returns true if the reader is decompressing its content.
+func isCompressed(reader io.ReadCloser) bool {
+	if r, ok := reader.(*streamReader); ok {
+		_, ok := r.Reader.(*gzip.Reader)
+
+		return ok
+	}
+
+	return false
+}
+
+// streamWriter writes a rewritten cassette to a temporary file, replacing the original only when complete.
+type streamWriter struct {
+	path       string
+	file       *os.File
+	buffer     *bufio.Writer
+	compressor *gzip.Writer
+	output     io.Writer
+	committed  bool
+}
+
+// newStreamWriter creates a temporary file alongside the cassette at path, to hold the rewritten cassette.
+func newStreamWriter(path string, encoding Encoding) (*streamWriter, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, eris.Wrapf(err, "creating temporary file for %s", path)
+	}
+
+	result := &streamWriter{
+		path:   path,
+		file:   file,
+		buffer: bufio.NewWriterSize(file, 64*1024),
+	}
+
+	result.output = result.buffer
+
+	if encoding.Compressed {
+		result.compressor = gzip.NewWriter(result.buffer)
+		result.output = result.compressor
+	}
+
+	return result, nil
+}
+
+// write appends content to the rewritten cassette.
+func (w *streamWriter) write(content ...[]byte) error {
+	for _, c := range content {
+		if _, err := w.output.Write(c); err != nil {
+			return eris.Wrapf(err, "writing %s", w.file.Name())
+		}
+	}
+
+	return nil
+}
+
+// commit completes the rewritten cassette and moves it into place, keeping the permissions of the original.
+func (w *streamWriter) commit() error {
+	if w.compressor != nil {
+		if err := w.compressor.Close(); err != nil {
+			return eris.Wrap(err, "closing gzip stream")
+		}
+	}
+
+	if err := w.buffer.Flush(); err != nil {
+		return eris.Wrapf(err, "writing %s", w.file.Name())
+	}
+
+	if err := w.file.Close(); err != nil {
+		return eris.Wrapf(err, "closing %s", w.file.Name())
+	}
+
+	if info, err := os.Stat(w.path); err == nil {
+		if err := os.Chmod(w.file.Name(), info.Mode().Perm()); err != nil {
+			return eris.Wrapf(err, "setting permissions of %s", w.file.Name())
+		}
+	}
+
+	if err := os.Rename(w.file.Name(), w.path); err != nil {
+		return eris.Wrapf(err, "replacing %s", w.path)
+	}
+
+	w.committed = true
+
+	return nil
+}
+
+// discard removes the temporary file, unless it has been committed.
+func (w *streamWriter) discard() {
+	if w.committed {
+		return
+	}
+
+	_ = w.file.Close()
+	_ = os.Remove(w.file.Name())
+}
@@ -0,0 +1,179 @@
+package cassettefile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+)
+
+func TestCanStream(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		name     string
+		encoding Encoding
+		expected bool
+	}{
+		"YAML":           {name: "widget.yaml", encoding: Encoding{Format: YAML}, expected: true},
+		"CompressedYAML": {name: "widget.yaml.gz", encoding: Encoding{Format: YAML, Compressed: true}, expected: true},
+		"JSON":           {name: "widget.json", encoding: Encoding{Format: JSON}},
+		"CompressedJSON": {name: "widget.cassette", encoding: Encoding{Format: JSON, Compressed: true}},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			path := copyTestDataAs(t, g, "widget.yaml", c.name, c.encoding)
+
+			g.Expect(CanStream(path)).To(Equal(c.expected))
+		})
+	}
+}
+
+func TestEachInteraction_GivenCassette_VisitsInteractionsInOrder(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path, _ := copyTestData(t, g, "handwritten.yaml")
+
+	var visited []*cassette.Interaction
+
+	err := EachInteraction(path, func(i *cassette.Interaction) error {
+		visited = append(visited, i)
+
+		return nil
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	file, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(visited).To(Equal(file.Cassette.Interactions))
+}
+
+func TestEachInteraction_GivenUnsupportedContent_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		content  string
+		expected string
+	}{
+		"FlowSequence": {
+			content:  "version: 2\ninteractions: []\n",
+			expected: "not stored as a block sequence",
+		},
+		"NoInteractions": {
+			content:  "name: config\n",
+			expected: "no interactions found",
+		},
+		"OldVersion": {
+			content:  "version: 1\ninteractions:\n    - request:\n        url: https://api.example.com\n",
+			expected: "unsupported format version 1",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			path := filepath.Join(t.TempDir(), "cassette.yaml")
+			g.Expect(os.WriteFile(path, []byte(c.content), 0o600)).To(Succeed())
+
+			err := EachInteraction(path, func(*cassette.Interaction) error {
+				return nil
+			})
+
+			g.Expect(err).To(MatchError(ContainSubstring(c.expected)))
+		})
+	}
+}
+
+func TestRewrite_WithRemovalsAndRewrites_MatchesSave(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	streamed, _ := copyTestData(t, g, "handwritten.yaml")
+	loaded, _ := copyTestData(t, g, "handwritten.yaml")
+
+	// Make the same changes to both copies
+	change := func(i *cassette.Interaction) bool {
+		switch i.ID {
+		case 0:
+			return false
+		case 1:
+			i.Response.Headers.Set("Retry-After", "0")
+			i.Response.Headers.Del("X-Request-Id")
+			i.Response.Duration = 100 * time.Millisecond
+		}
+
+		return true
+	}
+
+	retained, err := Rewrite(streamed, func(i *cassette.Interaction) (bool, error) {
+		return change(i), nil
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(retained).To(Equal(2))
+
+	file, err := Load(loaded)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	for _, i := range file.Cassette.Interactions {
+		i.DiscardOnSave = !change(i)
+	}
+
+	g.Expect(file.Save()).To(Succeed())
+
+	expected, err := os.ReadFile(loaded)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	actual, err := os.ReadFile(streamed)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(actual)).To(Equal(string(expected)))
+}
+
+func TestRewrite_WithAddedHeader_MarshalsInteractionInFull(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path, _ := copyTestData(t, g, "handwritten.yaml")
+
+	_, err := Rewrite(path, func(i *cassette.Interaction) (bool, error) {
+		if i.ID == 2 {
+			i.Response.Headers.Set("Retry-After", "0")
+		}
+
+		return true, nil
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	file, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(file.Cassette.Interactions).To(HaveLen(3))
+	g.Expect(file.Cassette.Interactions[2].Response.Headers.Get("Retry-After")).To(Equal("0"))
+}
+
+func TestRewrite_RemovingEverything_LeavesEmptyCassette(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := copyTestDataAs(t, g, "widget.yaml", "widget.yaml.gz", Encoding{Format: YAML, Compressed: true})
+
+	retained, err := Rewrite(path, func(*cassette.Interaction) (bool, error) {
+		return false, nil
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(retained).To(Equal(0))
+
+	file, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(file.Encoding).To(Equal(Encoding{Format: YAML, Compressed: true}))
+	g.Expect(file.Cassette.Interactions).To(BeEmpty())
+}
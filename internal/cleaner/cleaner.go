@@ -1,23 +1,37 @@
 package cleaner
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
-	"maps"
+	"strings"
 	"sync"
 
 	"github.com/google/uuid"
 	"github.com/rotisserie/eris"
 
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/delays"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/retention"
 )
 
 // Cleaner is a tool for cleaning go-vcr recordings.
 type Cleaner struct {
-	// analyzers is a set of active analyzers, keyed by a randomly assigned identifier for tracking.
-	analyzers map[uuid.UUID]analyzer.Interface
-	// interactionsToRemove is a set of interactions we've selected for removal from the recording
-	interactionsToRemove map[uuid.UUID]bool
+	// analyzers is the set of active analyzers, in the order they run.
+	analyzers registry
+	// interactionsToRemove is a set of interactions we've selected for removal from the recording, each mapped to the
+	// provenance explaining why
+	interactionsToRemove map[uuid.UUID]analyzer.Provenance
+	// interactionsToRetain is a set of interactions an analyzer has insisted must be kept, each mapped to the
+	// provenance explaining why. These are never removed, even if selected for removal by another analyzer.
+	interactionsToRetain map[uuid.UUID]analyzer.Provenance
+	// retention maps cleaning strategies to the retention policy used by their analyzers.
+	retention map[string]retention.Policy
+	// defaultRetention is the retention policy used by analyzers of other strategies, if set.
+	defaultRetention *retention.Policy
+	// zeroDelays indicates whether analyzers should rewrite delay hints on the interactions they retain to zero.
+	zeroDelays bool
 	// padlock is used to make concurrent access safe
 	padlock sync.Mutex
 }
@@ -25,8 +39,9 @@ type Cleaner struct {
 // New creates a new Cleaner instance with the specified analyzers included.
 func New(analyzers ...analyzer.Interface) *Cleaner {
 	result := &Cleaner{
-		analyzers:            make(map[uuid.UUID]analyzer.Interface),
-		interactionsToRemove: make(map[uuid.UUID]bool),
+		interactionsToRemove: make(map[uuid.UUID]analyzer.Provenance),
+		interactionsToRetain: make(map[uuid.UUID]analyzer.Provenance),
+		retention:            make(map[string]retention.Policy),
 	}
 
 	result.AddAnalyzers(analyzers...)
@@ -39,52 +54,174 @@ func (c *Cleaner) AddAnalyzers(analyzers ...analyzer.Interface) {
 	c.padlock.Lock()
 	defer c.padlock.Unlock()
 
-	c.add(analyzers...)
+	c.add("", analyzers...)
+}
+
+// AddStrategy adds one or more analyzers to the cleaner's active set, as part of the named cleaning strategy.
+// Any analyzers they spawn inherit the same strategy, allowing exclusions to be traced back to it.
+func (c *Cleaner) AddStrategy(strategy string, analyzers ...analyzer.Interface) {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	c.add(strategy, analyzers...)
+}
+
+// SetRetention sets the retention policy used by analyzers of the named cleaning strategy, including any they spawn.
+// If strategy is empty, the policy is used by all strategies without a policy of their own.
+// Policies may be set before or after the strategy is added.
+func (c *Cleaner) SetRetention(strategy string, policy retention.Policy) {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	if strategy == "" {
+		c.defaultRetention = &policy
+	} else {
+		c.retention[strategy] = policy
+	}
+
+	for _, reg := range c.analyzers.entries {
+		c.applyRetention(reg.strategy, reg.analyzer)
+	}
+}
+
+// RetentionOf returns the retention policy used by analyzers of the named cleaning strategy.
+// This is the policy set for the strategy if there is one, or else the default policy.
+func (c *Cleaner) RetentionOf(strategy string) retention.Policy {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	if policy, ok := c.retention[strategy]; ok {
+		return policy
+	}
+
+	if c.defaultRetention != nil {
+		return *c.defaultRetention
+	}
+
+	return retention.DefaultPolicy()
+}
+
+// SetZeroDelays controls whether analyzers rewrite delay hints (such as Retry-After) to zero on the polling sequences
+// they clean, so that clients replaying the recording don't wait before or between polls.
+// Applies to analyzers added both before and after the call.
+func (c *Cleaner) SetZeroDelays(enabled bool) {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	c.zeroDelays = enabled
+
+	for _, reg := range c.analyzers.entries {
+		c.applyZeroDelays(reg.analyzer)
+	}
 }
 
 // Analyze processes an interaction through all active analyzers, handling spawning and finishing as needed.
+// Analyzers run in the order they were added, with any they spawn added after all existing analyzers, so the
+// outcome (and logging) is the same on every run.
+// If ctx has been cancelled, the interaction is not analyzed and an error is returned.
 func (c *Cleaner) Analyze(
+	ctx context.Context,
 	log *slog.Logger,
 	i interaction.Interface,
 ) error {
+	if err := ctx.Err(); err != nil {
+		return eris.Wrap(err, "analysis cancelled")
+	}
+
 	var (
-		toRemove  []uuid.UUID
-		toAdd     []analyzer.Interface
-		toExclude []interaction.Interface
+		toRemove  []*registration
+		toAdd     []spawned
+		toExclude []decision
+		toRetain  []decision
 	)
 
 	// Get all active analyzers
 	c.padlock.Lock()
-	analyzers := maps.Clone(c.analyzers)
+	analyzers := c.analyzers.snapshot()
 	c.padlock.Unlock()
 
-	for id, a := range analyzers {
-		result, err := a.Analyze(log, i)
+	for _, reg := range analyzers {
+		result, err := reg.analyzer.Analyze(ctx, log, i)
 		if err != nil {
 			return eris.Wrapf(err, "analyzing interaction ID %s", i.ID())
 		}
 
 		if result.Finished {
-			toRemove = append(toRemove, id)
+			toRemove = append(toRemove, reg)
 		}
 
-		// Add any spawned analyzers (if any)
-		toAdd = append(toAdd, result.Spawn...)
+		// Add any spawned analyzers (if any), inheriting the strategy of their parent
+		if len(result.Spawn) > 0 {
+			toAdd = append(toAdd, spawned{
+				strategy:  reg.strategy,
+				analyzers: result.Spawn,
+			})
+		}
 
-		// Exclude any interactions marked for exclusion (if any)
-		toExclude = append(toExclude, result.Excluded...)
+		// Exclude any interactions marked for exclusion, and retain any marked for retention (if any)
+		toExclude = append(toExclude, decisionsFrom(reg, result.Provenance, result.Excluded)...)
+		toRetain = append(toRetain, decisionsFrom(reg, result.RetentionProvenance(), result.Retained)...)
 	}
 
 	c.padlock.Lock()
 	defer c.padlock.Unlock()
 
-	c.remove(toRemove...)
-	c.add(toAdd...)
-	c.exclude(toExclude...)
+	c.analyzers.remove(toRemove...)
+
+	for _, s := range toAdd {
+		c.add(s.strategy, s.analyzers...)
+	}
+
+	c.retain(log, toRetain...)
+	c.exclude(log, toExclude...)
 
 	return nil
 }
 
+// Finish tells the active analyzers that there are no more interactions to analyze, allowing any that implement
+// analyzer.Finisher to decide what to do with a sequence that never completed. Finishers are called in the same order
+// analyzers run. Afterwards no analyzers remain active, so calling Finish again has no effect.
+// If ctx has been cancelled, no analyzers are finished and an error is returned.
+func (c *Cleaner) Finish(ctx context.Context, log *slog.Logger) error {
+	if err := ctx.Err(); err != nil {
+		return eris.Wrap(err, "analysis cancelled")
+	}
+
+	var (
+		toExclude []decision
+		toRetain  []decision
+	)
+
+	c.padlock.Lock()
+	analyzers := c.analyzers.snapshot()
+	c.analyzers.remove(analyzers...)
+	c.padlock.Unlock()
+
+	for _, reg := range analyzers {
+		finisher, ok := reg.analyzer.(analyzer.Finisher)
+		if !ok {
+			continue
+		}
+
+		result, err := finisher.Finish(ctx, log)
+		if err != nil {
+			return eris.Wrapf(err, "finishing %s", nameOf(reg.analyzer))
+		}
+
+		toExclude = append(toExclude, decisionsFrom(reg, result.Provenance, result.Excluded)...)
+		toRetain = append(toRetain, decisionsFrom(reg, result.RetentionProvenance(), result.Retained)...)
+	}
+
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	c.retain(log, toRetain...)
+	c.exclude(log, toExclude...)
+
+	return nil
+}
+
+// ShouldRemove returns true if the interaction has been selected for removal.
 func (c *Cleaner) ShouldRemove(i interaction.Interface) bool {
 	c.padlock.Lock()
 	defer c.padlock.Unlock()
@@ -94,6 +231,24 @@ func (c *Cleaner) ShouldRemove(i interaction.Interface) bool {
 	return ok
 }
 
+// Provenance returns the provenance explaining why the interaction was selected for removal.
+// Returns false if the interaction has not been selected for removal.
+func (c *Cleaner) Provenance(i interaction.Interface) (analyzer.Provenance, bool) {
+	return c.ProvenanceOf(i.ID())
+}
+
+// ProvenanceOf returns the provenance explaining why the interaction with the specified ID was selected for removal.
+// This allows callers to look up the outcome without retaining the interaction itself.
+// Returns false if the interaction has not been selected for removal.
+func (c *Cleaner) ProvenanceOf(id uuid.UUID) (analyzer.Provenance, bool) {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	provenance, ok := c.interactionsToRemove[id]
+
+	return provenance, ok
+}
+
 // InteractionsToRemove returns the number of interactions marked for removal.
 func (c *Cleaner) InteractionsToRemove() int {
 	c.padlock.Lock()
@@ -102,24 +257,133 @@ func (c *Cleaner) InteractionsToRemove() int {
 	return len(c.interactionsToRemove)
 }
 
-// add one or more analyzers to the cleaner's active set.
-func (c *Cleaner) add(analyzers ...analyzer.Interface) {
+// add one or more analyzers to the end of the cleaner's active set, in order.
+// strategy is the cleaning strategy responsible for the analyzers, if known.
+func (c *Cleaner) add(strategy string, analyzers ...analyzer.Interface) {
 	for _, a := range analyzers {
-		// We give each analyzer a unique identifier to make it easy to track them when finished
-		c.analyzers[uuid.New()] = a
+		c.analyzers.add(strategy, a)
+		c.applyRetention(strategy, a)
+		c.applyZeroDelays(a)
+	}
+}
+
+// applyRetention configures the analyzer with the retention policy for its strategy, if it has one.
+// Analyzers that don't support retention policies are left unchanged.
+func (c *Cleaner) applyRetention(strategy string, a analyzer.Interface) {
+	configurable, ok := a.(retention.Configurable)
+	if !ok {
+		return
+	}
+
+	if policy, ok := c.retention[strategy]; ok {
+		configurable.SetRetention(policy)
+	} else if c.defaultRetention != nil {
+		configurable.SetRetention(*c.defaultRetention)
 	}
 }
 
-// remove one or more analyzers from the cleaner's active set.
-func (c *Cleaner) remove(ids ...uuid.UUID) {
-	for _, id := range ids {
-		delete(c.analyzers, id)
+// applyZeroDelays configures whether the analyzer rewrites delay hints on the interactions it retains.
+// Analyzers that don't support rewriting delay hints are left unchanged.
+func (c *Cleaner) applyZeroDelays(a analyzer.Interface) {
+	if configurable, ok := a.(delays.Configurable); ok {
+		configurable.SetZeroDelays(c.zeroDelays)
 	}
 }
 
 // exclude adds the specified interactions to the set of interactions to be removed.
-func (c *Cleaner) exclude(interactions ...interaction.Interface) {
-	for _, inter := range interactions {
-		c.interactionsToRemove[inter.ID()] = true
+// If an interaction is excluded more than once, the provenance from the first exclusion is retained.
+// Interactions that must be retained are never excluded; the conflict is logged instead.
+func (c *Cleaner) exclude(log *slog.Logger, exclusions ...decision) {
+	for _, ex := range exclusions {
+		id := ex.interaction.ID()
+		if retained, ok := c.interactionsToRetain[id]; ok {
+			logConflict(log, ex.interaction, ex.provenance, retained)
+
+			continue
+		}
+
+		if _, ok := c.interactionsToRemove[id]; !ok {
+			c.interactionsToRemove[id] = ex.provenance
+		}
 	}
 }
+
+// retain adds the specified interactions to the set of interactions to be kept.
+// If an interaction is retained more than once, the provenance from the first retention is kept.
+// Any interaction already selected for removal is restored, and the conflict logged.
+func (c *Cleaner) retain(log *slog.Logger, retentions ...decision) {
+	for _, ret := range retentions {
+		id := ret.interaction.ID()
+		if _, ok := c.interactionsToRetain[id]; !ok {
+			c.interactionsToRetain[id] = ret.provenance
+		}
+
+		if excluded, ok := c.interactionsToRemove[id]; ok {
+			logConflict(log, ret.interaction, excluded, c.interactionsToRetain[id])
+			delete(c.interactionsToRemove, id)
+		}
+	}
+}
+
+// logConflict logs an interaction selected for removal by one analyzer that must be retained for another.
+func logConflict(
+	log *slog.Logger,
+	i interaction.Interface,
+	excluded analyzer.Provenance,
+	retained analyzer.Provenance,
+) {
+	log.Info(
+		"Retaining interaction selected for removal",
+		"method", i.Request().Method(),
+		"url", i.Request().FullURL().String(),
+		"excludedBy", excluded.Analyzer,
+		"excludingStrategy", excluded.Strategy,
+		"excludedBecause", excluded.Reason,
+		"retainedBy", retained.Analyzer,
+		"retainingStrategy", retained.Strategy,
+		"retainedBecause", retained.Reason,
+	)
+}
+
+// decisionsFrom returns a decision for each of the specified interactions, made by a registered analyzer for the
+// reason given by provenance, filling in any provenance the analyzer left empty.
+func decisionsFrom(
+	reg *registration,
+	provenance analyzer.Provenance,
+	interactions []interaction.Interface,
+) []decision {
+	if provenance.Analyzer == "" {
+		provenance.Analyzer = nameOf(reg.analyzer)
+	}
+
+	if provenance.Strategy == "" {
+		provenance.Strategy = reg.strategy
+	}
+
+	decisions := make([]decision, 0, len(interactions))
+	for _, i := range interactions {
+		decisions = append(decisions, decision{
+			interaction: i,
+			provenance:  provenance,
+		})
+	}
+
+	return decisions
+}
+
+// decision captures an interaction selected for removal (or retention), along with the provenance explaining why.
+type decision struct {
+	interaction interaction.Interface
+	provenance  analyzer.Provenance
+}
+
+// spawned captures analyzers spawned by a parent, along with the strategy they inherit.
+type spawned struct {
+	strategy  string
+	analyzers []analyzer.Interface
+}
+
+// nameOf returns a readable name for an analyzer, based on its type (e.g. "generic.MonitorDeletion").
+func nameOf(a analyzer.Interface) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", a), "*")
+}
@@ -1,7 +1,10 @@
 package cleaner
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"log/slog"
 	"net/http"
 	"testing"
 
@@ -11,7 +14,9 @@ import (
 
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/fake"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/must"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/retention"
 )
 
 // Constructor Tests
@@ -23,7 +28,7 @@ func TestNewCleaner_WithNoAnalyzers_CreatesEmptyInstance(t *testing.T) {
 	c := New()
 
 	g.Expect(c).ToNot(BeNil())
-	g.Expect(c.analyzers).To(BeEmpty())
+	g.Expect(c.analyzers.all()).To(BeEmpty())
 }
 
 func TestNewCleaner_WithAnalyzers_AddsAllToActiveSet(t *testing.T) {
@@ -36,9 +41,9 @@ func TestNewCleaner_WithAnalyzers_AddsAllToActiveSet(t *testing.T) {
 
 	c := New(a1, a2, a3)
 
-	g.Expect(c.analyzers).To(ContainElement(a1))
-	g.Expect(c.analyzers).To(ContainElement(a2))
-	g.Expect(c.analyzers).To(ContainElement(a3))
+	g.Expect(c.analyzers.all()).To(ContainElement(a1))
+	g.Expect(c.analyzers.all()).To(ContainElement(a2))
+	g.Expect(c.analyzers.all()).To(ContainElement(a3))
 }
 
 // Add Method Tests
@@ -52,7 +57,7 @@ func TestAdd_WithSingleAnalyzer_AddsToActiveSet(t *testing.T) {
 
 	c.AddAnalyzers(a)
 
-	g.Expect(c.analyzers).To(ContainElement(a))
+	g.Expect(c.analyzers.all()).To(ContainElement(a))
 }
 
 func TestAdd_WithMultipleAnalyzers_AddsAllToActiveSet(t *testing.T) {
@@ -66,9 +71,9 @@ func TestAdd_WithMultipleAnalyzers_AddsAllToActiveSet(t *testing.T) {
 
 	c.AddAnalyzers(a1, a2, a3)
 
-	g.Expect(c.analyzers).To(ContainElement(a1))
-	g.Expect(c.analyzers).To(ContainElement(a2))
-	g.Expect(c.analyzers).To(ContainElement(a3))
+	g.Expect(c.analyzers.all()).To(ContainElement(a1))
+	g.Expect(c.analyzers.all()).To(ContainElement(a2))
+	g.Expect(c.analyzers.all()).To(ContainElement(a3))
 }
 
 func TestAdd_WhenCalledMultipleTimes_AccumulatesAnalyzers(t *testing.T) {
@@ -84,9 +89,9 @@ func TestAdd_WhenCalledMultipleTimes_AccumulatesAnalyzers(t *testing.T) {
 	c.AddAnalyzers(a2)
 	c.AddAnalyzers(a3)
 
-	g.Expect(c.analyzers).To(ContainElement(a1))
-	g.Expect(c.analyzers).To(ContainElement(a2))
-	g.Expect(c.analyzers).To(ContainElement(a3))
+	g.Expect(c.analyzers.all()).To(ContainElement(a1))
+	g.Expect(c.analyzers.all()).To(ContainElement(a2))
+	g.Expect(c.analyzers.all()).To(ContainElement(a3))
 }
 
 // Analyze Method Tests
@@ -101,7 +106,7 @@ func TestAnalyze_SingleAnalyzer_ProcessesInteraction(t *testing.T) {
 
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
 	inter := fake.Interaction(baseURL, http.MethodGet, 200)
-	err := c.Analyze(log, inter)
+	err := c.Analyze(t.Context(), log, inter)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(a.CallCount).To(Equal(1))
@@ -120,7 +125,7 @@ func TestAnalyze_MultipleAnalyzers_AllProcessInteraction(t *testing.T) {
 
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
 	inter := fake.Interaction(baseURL, http.MethodGet, 200)
-	err := c.Analyze(log, inter)
+	err := c.Analyze(t.Context(), log, inter)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(a1.CallCount).To(Equal(1))
@@ -142,11 +147,30 @@ func TestAnalyze_WhenAnalyzerReturnsError_PropagatesError(t *testing.T) {
 
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
 	inter := fake.Interaction(baseURL, http.MethodGet, 200)
-	err := c.Analyze(log, inter)
+	err := c.Analyze(t.Context(), log, inter)
 
 	g.Expect(err).To(MatchError(ContainSubstring(expectedErr.Error())))
 }
 
+func TestAnalyze_WhenCancelled_ReturnsErrorWithoutAnalyzing(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	a := fake.Analyzer("analyzer1")
+	c := New(a)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	inter := fake.Interaction(baseURL, http.MethodGet, 200)
+	err := c.Analyze(ctx, log, inter)
+
+	g.Expect(err).To(MatchError(context.Canceled))
+	g.Expect(a.CallCount).To(Equal(0))
+}
+
 func TestAnalyze_WhenAnalyzerFinishes_RemovesFromActiveSet(t *testing.T) {
 	t.Parallel()
 	g := NewWithT(t)
@@ -157,16 +181,16 @@ func TestAnalyze_WhenAnalyzerFinishes_RemovesFromActiveSet(t *testing.T) {
 
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
 	inter1 := fake.Interaction(baseURL, http.MethodGet, 200)
-	err := c.Analyze(log, inter1)
+	err := c.Analyze(t.Context(), log, inter1)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(a.CallCount).To(Equal(1))
 
-	g.Expect(c.analyzers).NotTo(ContainElement(a))
+	g.Expect(c.analyzers.all()).NotTo(ContainElement(a))
 
 	// Second interaction should not be processed by the finished analyzer
 	inter2 := fake.Interaction(baseURL, http.MethodGet, 200)
-	err = c.Analyze(log, inter2)
+	err = c.Analyze(t.Context(), log, inter2)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(a.CallCount).To(Equal(1), "Finished analyzer should not process second interaction")
@@ -183,10 +207,10 @@ func TestAnalyze_AnalyzerSpawns_AddsNewAnalyzersToActiveSet(t *testing.T) {
 
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
 	inter1 := fake.Interaction(baseURL, http.MethodGet, 200)
-	err := c.Analyze(log, inter1)
+	err := c.Analyze(t.Context(), log, inter1)
 
 	g.Expect(err).ToNot(HaveOccurred())
-	g.Expect(c.analyzers).To(ContainElement(spawned))
+	g.Expect(c.analyzers.all()).To(ContainElement(spawned))
 }
 
 func TestAnalyze_AnalyzerExcludesInteractions_TracksExclusions(t *testing.T) {
@@ -199,11 +223,11 @@ func TestAnalyze_AnalyzerExcludesInteractions_TracksExclusions(t *testing.T) {
 	inter2 := fake.Interaction(baseURL, http.MethodGet, 200)
 
 	a := fake.Analyzer("analyzer1").
-		WithResult(analyzer.FinishedWithExclusions(inter1, inter2))
+		WithResult(analyzer.FinishedWithExclusions(analyzer.Provenance{}, inter1, inter2))
 	c := New(a)
 
 	inter3 := fake.Interaction(baseURL, http.MethodDelete, 200)
-	err := c.Analyze(log, inter3)
+	err := c.Analyze(t.Context(), log, inter3)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(c.interactionsToRemove).To(HaveKey(inter1.ID()))
@@ -225,14 +249,14 @@ func TestAnalyze_AnalyzerFinishesAndSpawns_HandlesBoth(t *testing.T) {
 
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
 	inter1 := fake.Interaction(baseURL, http.MethodGet, 200)
-	err := c.Analyze(log, inter1)
+	err := c.Analyze(t.Context(), log, inter1)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(a.CallCount).To(Equal(1))
 
 	// Second interaction should only be processed by spawned analyzer
 	inter2 := fake.Interaction(baseURL, http.MethodPost, 201)
-	err = c.Analyze(log, inter2)
+	err = c.Analyze(t.Context(), log, inter2)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(a.CallCount).To(Equal(1), "Finished analyzer should not process second interaction")
@@ -252,11 +276,11 @@ func TestAnalyze_MultipleAnalyzersFinish_RemovesAll(t *testing.T) {
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
 	inter1 := fake.Interaction(baseURL, http.MethodGet, 200)
 
-	g.Expect(c.Analyze(log, inter1)).To(Succeed())
+	g.Expect(c.Analyze(t.Context(), log, inter1)).To(Succeed())
 
-	g.Expect(c.analyzers).NotTo(ContainElement(a1))
-	g.Expect(c.analyzers).NotTo(ContainElement(a2))
-	g.Expect(c.analyzers).NotTo(ContainElement(a3))
+	g.Expect(c.analyzers.all()).NotTo(ContainElement(a1))
+	g.Expect(c.analyzers.all()).NotTo(ContainElement(a2))
+	g.Expect(c.analyzers.all()).NotTo(ContainElement(a3))
 }
 
 func TestAnalyze_SpawnedAnalyzerProcessesNextInteraction_Works(t *testing.T) {
@@ -282,17 +306,94 @@ func TestAnalyze_SpawnedAnalyzerProcessesNextInteraction_Works(t *testing.T) {
 
 	// First interaction: a spawns spawned1
 	inter1 := fake.Interaction(baseURL, http.MethodGet, 200)
-	err := c.Analyze(log, inter1)
+	err := c.Analyze(t.Context(), log, inter1)
 	g.Expect(err).ToNot(HaveOccurred())
-	g.Expect(c.analyzers).To(ContainElement(spawned1))
+	g.Expect(c.analyzers.all()).To(ContainElement(spawned1))
 
 	// Second interaction: both a and spawned1 process, a finishes and spawns spawned2
 	inter2 := fake.Interaction(baseURL, http.MethodPost, 201)
-	err = c.Analyze(log, inter2)
+	err = c.Analyze(t.Context(), log, inter2)
 	g.Expect(err).ToNot(HaveOccurred())
-	g.Expect(c.analyzers).NotTo(ContainElement(a))
-	g.Expect(c.analyzers).To(ContainElement(spawned1))
-	g.Expect(c.analyzers).To(ContainElement(spawned2))
+	g.Expect(c.analyzers.all()).NotTo(ContainElement(a))
+	g.Expect(c.analyzers.all()).To(ContainElement(spawned1))
+	g.Expect(c.analyzers.all()).To(ContainElement(spawned2))
+}
+
+func TestAnalyze_MultipleAnalyzers_RunInOrderAdded(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	var calls []string
+
+	names := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	c := New()
+
+	for _, name := range names {
+		c.AddAnalyzers(fake.Analyzer(name).RecordingCallsTo(&calls))
+	}
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+
+	// Repeat, as map iteration would only occasionally produce the expected order
+	for range 10 {
+		calls = nil
+
+		g.Expect(c.Analyze(t.Context(), log, fake.Interaction(baseURL, http.MethodGet, 200))).To(Succeed())
+		g.Expect(calls).To(Equal(names))
+	}
+}
+
+func TestAnalyze_SpawnedAnalyzers_RunAfterParents(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	var calls []string
+
+	child1 := fake.Analyzer("child1").RecordingCallsTo(&calls)
+	child2 := fake.Analyzer("child2").RecordingCallsTo(&calls)
+	parent1 := fake.Analyzer("parent1").
+		RecordingCallsTo(&calls).
+		WithResults(analyzer.Spawn(child1), analyzer.Result{})
+	parent2 := fake.Analyzer("parent2").
+		RecordingCallsTo(&calls).
+		WithResults(analyzer.Spawn(child2), analyzer.Result{})
+
+	c := New(parent1, parent2)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+
+	g.Expect(c.Analyze(t.Context(), log, fake.Interaction(baseURL, http.MethodPut, 201))).To(Succeed())
+	g.Expect(calls).To(Equal([]string{"parent1", "parent2"}))
+
+	calls = nil
+
+	g.Expect(c.Analyze(t.Context(), log, fake.Interaction(baseURL, http.MethodGet, 200))).To(Succeed())
+	g.Expect(calls).To(Equal([]string{"parent1", "parent2", "child1", "child2"}))
+}
+
+func TestAnalyze_WhenAnalyzerFinishes_PreservesOrderOfOthers(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	var calls []string
+
+	a1 := fake.Analyzer("analyzer1").RecordingCallsTo(&calls)
+	a2 := fake.Analyzer("analyzer2").RecordingCallsTo(&calls).WithResult(analyzer.Finished())
+	a3 := fake.Analyzer("analyzer3").RecordingCallsTo(&calls)
+	c := New(a1, a2, a3)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+
+	g.Expect(c.Analyze(t.Context(), log, fake.Interaction(baseURL, http.MethodGet, 200))).To(Succeed())
+
+	calls = nil
+
+	g.Expect(c.Analyze(t.Context(), log, fake.Interaction(baseURL, http.MethodGet, 200))).To(Succeed())
+	g.Expect(calls).To(Equal([]string{"analyzer1", "analyzer3"}))
+	g.Expect(c.analyzers.all()).To(Equal([]analyzer.Interface{a1, a3}))
 }
 
 func TestAnalyze_ExclusionFromMultipleAnalyzers_AccumulatesAll(t *testing.T) {
@@ -305,13 +406,13 @@ func TestAnalyze_ExclusionFromMultipleAnalyzers_AccumulatesAll(t *testing.T) {
 	inter2 := fake.Interaction(baseURL, http.MethodGet, 201)
 	inter3 := fake.Interaction(baseURL, http.MethodGet, 202)
 
-	a1 := fake.Analyzer("analyzer1").WithResult(analyzer.FinishedWithExclusions(inter1))
-	a2 := fake.Analyzer("analyzer2").WithResult(analyzer.FinishedWithExclusions(inter2))
-	a3 := fake.Analyzer("analyzer3").WithResult(analyzer.FinishedWithExclusions(inter3))
+	a1 := fake.Analyzer("analyzer1").WithResult(analyzer.FinishedWithExclusions(analyzer.Provenance{}, inter1))
+	a2 := fake.Analyzer("analyzer2").WithResult(analyzer.FinishedWithExclusions(analyzer.Provenance{}, inter2))
+	a3 := fake.Analyzer("analyzer3").WithResult(analyzer.FinishedWithExclusions(analyzer.Provenance{}, inter3))
 	c := New(a1, a2, a3)
 
 	inter4 := fake.Interaction(baseURL, http.MethodDelete, 200)
-	err := c.Analyze(log, inter4)
+	err := c.Analyze(t.Context(), log, inter4)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(c.interactionsToRemove).To(HaveKey(inter1.ID()))
@@ -329,14 +430,14 @@ func TestAnalyze_EmptyResult_NoSideEffects(t *testing.T) {
 
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
 	inter1 := fake.Interaction(baseURL, http.MethodGet, 200)
-	err := c.Analyze(log, inter1)
+	err := c.Analyze(t.Context(), log, inter1)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(a.CallCount).To(Equal(1))
 
 	// Second interaction should still be processed
 	inter2 := fake.Interaction(baseURL, http.MethodGet, 200)
-	err = c.Analyze(log, inter2)
+	err = c.Analyze(t.Context(), log, inter2)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(a.CallCount).To(Equal(2))
@@ -353,7 +454,7 @@ func TestAnalyze_NoAnalyzers_NoError(t *testing.T) {
 
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
 	inter := fake.Interaction(baseURL, http.MethodGet, 200)
-	err := c.Analyze(log, inter)
+	err := c.Analyze(t.Context(), log, inter)
 
 	g.Expect(err).ToNot(HaveOccurred())
 }
@@ -369,7 +470,7 @@ func TestAnalyze_AllAnalyzersFinish_LeavesEmptySet(t *testing.T) {
 
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
 	inter1 := fake.Interaction(baseURL, http.MethodGet, 200)
-	err := c.Analyze(log, inter1)
+	err := c.Analyze(t.Context(), log, inter1)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(a1.CallCount).To(Equal(1))
@@ -377,9 +478,466 @@ func TestAnalyze_AllAnalyzersFinish_LeavesEmptySet(t *testing.T) {
 
 	// After all analyzers finish, subsequent interactions should work but do nothing
 	inter2 := fake.Interaction(baseURL, http.MethodGet, 200)
-	err = c.Analyze(log, inter2)
+	err = c.Analyze(t.Context(), log, inter2)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(a1.CallCount).To(Equal(1), "Finished analyzer should not be called again")
 	g.Expect(a2.CallCount).To(Equal(1), "Finished analyzer should not be called again")
 }
+
+// Finish Tests
+
+func TestFinish_ActiveFinishers_CalledInOrder(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	var calls []string
+
+	child := fake.Analyzer("child").RecordingCallsTo(&calls)
+	parent := fake.Analyzer("parent").RecordingCallsTo(&calls).WithResults(analyzer.Spawn(child), analyzer.Result{})
+	other := fake.Analyzer("other").RecordingCallsTo(&calls)
+	c := New(parent, other)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	g.Expect(c.Analyze(t.Context(), log, fake.Interaction(baseURL, http.MethodPut, 201))).To(Succeed())
+
+	calls = nil
+
+	g.Expect(c.Finish(t.Context(), log)).To(Succeed())
+	g.Expect(calls).To(Equal([]string{"parent", "other", "child"}))
+}
+
+func TestFinish_FinishedAnalyzers_NotCalled(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	a := fake.Analyzer("analyzer1").WithResult(analyzer.Finished())
+	c := New(a)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	g.Expect(c.Analyze(t.Context(), log, fake.Interaction(baseURL, http.MethodGet, 200))).To(Succeed())
+
+	g.Expect(c.Finish(t.Context(), log)).To(Succeed())
+	g.Expect(a.FinishCount).To(BeZero())
+}
+
+func TestFinish_WithExclusions_TracksExclusionsWithProvenance(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	poll := fake.Interaction(baseURL, http.MethodGet, 200)
+
+	a := fake.Analyzer("analyzer1").WithFinishResult(analyzer.Result{
+		Excluded:   []interaction.Interface{poll},
+		Provenance: analyzer.Because(baseURL.String(), "recording ended"),
+	})
+
+	c := New()
+	c.AddStrategy("polling", a)
+
+	g.Expect(c.Finish(t.Context(), log)).To(Succeed())
+
+	provenance, ok := c.Provenance(poll)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(provenance.Analyzer).To(Equal("fake.TestAnalyzer"))
+	g.Expect(provenance.Strategy).To(Equal("polling"))
+	g.Expect(provenance.Reason).To(Equal("recording ended"))
+}
+
+func TestFinish_CalledTwice_FinishesAnalyzersOnce(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	a := fake.Analyzer("analyzer1")
+	c := New(a)
+
+	g.Expect(c.Finish(t.Context(), log)).To(Succeed())
+	g.Expect(c.Finish(t.Context(), log)).To(Succeed())
+
+	g.Expect(a.FinishCount).To(Equal(1))
+	g.Expect(c.analyzers.all()).To(BeEmpty())
+}
+
+func TestFinish_AnalyzerWithoutFinisher_Discarded(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	// Embedding only the analyzer interface hides the Finish method of the fake
+	a := struct{ analyzer.Interface }{fake.Analyzer("analyzer1")}
+	c := New(a)
+
+	g.Expect(c.Finish(t.Context(), log)).To(Succeed())
+	g.Expect(c.analyzers.all()).To(BeEmpty())
+}
+
+func TestFinish_WhenCancelled_ReturnsErrorWithoutFinishing(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	a := fake.Analyzer("analyzer1")
+	c := New(a)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	err := c.Finish(ctx, log)
+
+	g.Expect(err).To(MatchError(context.Canceled))
+	g.Expect(a.FinishCount).To(Equal(0))
+}
+
+// Provenance Tests
+
+func TestProvenance_ExcludedInteraction_FillsInAnalyzerName(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	inter1 := fake.Interaction(baseURL, http.MethodGet, 200)
+
+	provenance := analyzer.Because(baseURL.String(), "testing")
+	a := fake.Analyzer("analyzer1").WithResult(analyzer.FinishedWithExclusions(provenance, inter1))
+	c := New(a)
+
+	inter2 := fake.Interaction(baseURL, http.MethodDelete, 200)
+	g.Expect(c.Analyze(t.Context(), log, inter2)).To(Succeed())
+
+	actual, ok := c.Provenance(inter1)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(actual).To(Equal(analyzer.Provenance{
+		Analyzer: "fake.TestAnalyzer",
+		URL:      baseURL.String(),
+		Reason:   "testing",
+	}))
+}
+
+func TestProvenance_ExcludedBySpawnedAnalyzer_InheritsStrategy(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	inter1 := fake.Interaction(baseURL, http.MethodGet, 200)
+
+	spawned := fake.Analyzer("spawned").
+		WithResult(analyzer.FinishedWithExclusions(analyzer.Provenance{}, inter1))
+	a := fake.Analyzer("analyzer1").WithResult(analyzer.Spawn(spawned))
+
+	c := New()
+	c.AddStrategy("testing-strategy", a)
+
+	inter2 := fake.Interaction(baseURL, http.MethodGet, 200)
+	g.Expect(c.Analyze(t.Context(), log, inter2)).To(Succeed())
+
+	inter3 := fake.Interaction(baseURL, http.MethodGet, 200)
+	g.Expect(c.Analyze(t.Context(), log, inter3)).To(Succeed())
+
+	actual, ok := c.Provenance(inter1)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(actual.Strategy).To(Equal("testing-strategy"))
+}
+
+func TestProvenance_StrategyProvidedByAnalyzer_IsRetained(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	inter1 := fake.Interaction(baseURL, http.MethodGet, 200)
+
+	provenance := analyzer.Provenance{
+		Analyzer: "custom",
+		Strategy: "explicit-strategy",
+	}
+	a := fake.Analyzer("analyzer1").WithResult(analyzer.FinishedWithExclusions(provenance, inter1))
+
+	c := New()
+	c.AddStrategy("testing-strategy", a)
+
+	inter2 := fake.Interaction(baseURL, http.MethodGet, 200)
+	g.Expect(c.Analyze(t.Context(), log, inter2)).To(Succeed())
+
+	actual, ok := c.Provenance(inter1)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(actual).To(Equal(provenance))
+}
+
+func TestProvenance_RetainedInteraction_ReturnsFalse(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	a := fake.Analyzer("analyzer1")
+	c := New(a)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	inter := fake.Interaction(baseURL, http.MethodGet, 200)
+	g.Expect(c.Analyze(t.Context(), log, inter)).To(Succeed())
+
+	_, ok := c.Provenance(inter)
+	g.Expect(ok).To(BeFalse())
+}
+
+// Protection Tests
+
+func TestAnalyze_RetainAfterExclusion_RestoresInteraction(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	poll := fake.Interaction(baseURL, http.MethodGet, 200)
+
+	excluder := fake.Analyzer("excluder").
+		WithResult(analyzer.FinishedWithExclusions(analyzer.Provenance{}, poll))
+	retainer := fake.Analyzer("retainer").
+		WithResults(analyzer.Result{}, analyzer.Retain(analyzer.Provenance{}, poll))
+	c := New(excluder, retainer)
+
+	g.Expect(c.Analyze(t.Context(), log, poll)).To(Succeed())
+	g.Expect(c.ShouldRemove(poll)).To(BeTrue())
+
+	g.Expect(c.Analyze(t.Context(), log, fake.Interaction(baseURL, http.MethodGet, 200))).To(Succeed())
+	g.Expect(c.ShouldRemove(poll)).To(BeFalse())
+	g.Expect(c.InteractionsToRemove()).To(Equal(0))
+}
+
+func TestAnalyze_RetainBeforeExclusion_PreventsExclusion(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	poll := fake.Interaction(baseURL, http.MethodGet, 200)
+
+	retainer := fake.Analyzer("retainer").
+		WithResults(analyzer.Retain(analyzer.Provenance{}, poll), analyzer.Result{})
+	excluder := fake.Analyzer("excluder").
+		WithResults(analyzer.Result{}, analyzer.FinishedWithExclusions(analyzer.Provenance{}, poll))
+	c := New(retainer, excluder)
+
+	g.Expect(c.Analyze(t.Context(), log, poll)).To(Succeed())
+	g.Expect(c.Analyze(t.Context(), log, fake.Interaction(baseURL, http.MethodGet, 200))).To(Succeed())
+
+	_, ok := c.Provenance(poll)
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestAnalyze_RetainAndExcludeOfSameInteraction_RetainWinsRegardlessOfOrder(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	poll := fake.Interaction(baseURL, http.MethodGet, 200)
+
+	// The excluder runs first, but both decisions are made for the same interaction
+	excluder := fake.Analyzer("excluder").
+		WithResult(analyzer.FinishedWithExclusions(analyzer.Provenance{}, poll))
+	retainer := fake.Analyzer("retainer").
+		WithResult(analyzer.Retain(analyzer.Provenance{}, poll))
+	c := New(excluder, retainer)
+
+	g.Expect(c.Analyze(t.Context(), log, poll)).To(Succeed())
+	g.Expect(c.ShouldRemove(poll)).To(BeFalse())
+}
+
+func TestAnalyze_RetainOnlySomeExclusions_RemovesTheRest(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	poll1 := fake.Interaction(baseURL, http.MethodGet, 200)
+	poll2 := fake.Interaction(baseURL, http.MethodGet, 200)
+
+	excluder := fake.Analyzer("excluder").
+		WithResult(analyzer.FinishedWithExclusions(analyzer.Provenance{}, poll1, poll2))
+	retainer := fake.Analyzer("retainer").
+		WithResult(analyzer.Retain(analyzer.Provenance{}, poll2))
+	c := New(excluder, retainer)
+
+	g.Expect(c.Analyze(t.Context(), log, poll2)).To(Succeed())
+	g.Expect(c.ShouldRemove(poll1)).To(BeTrue())
+	g.Expect(c.ShouldRemove(poll2)).To(BeFalse())
+}
+
+func TestFinish_RetainedByFinisher_RestoresInteraction(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	poll := fake.Interaction(baseURL, http.MethodGet, 200)
+
+	excluder := fake.Analyzer("excluder").
+		WithResult(analyzer.FinishedWithExclusions(analyzer.Provenance{}, poll))
+	retainer := fake.Analyzer("retainer").
+		WithFinishResult(analyzer.Retain(analyzer.Provenance{}, poll))
+	c := New(excluder, retainer)
+
+	g.Expect(c.Analyze(t.Context(), log, poll)).To(Succeed())
+	g.Expect(c.Finish(t.Context(), log)).To(Succeed())
+	g.Expect(c.ShouldRemove(poll)).To(BeFalse())
+}
+
+func TestAnalyze_StrategiesDisagree_LogsConflict(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	var buffer bytes.Buffer
+
+	log := slog.New(slog.NewTextHandler(&buffer, nil))
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	poll := fake.Interaction(baseURL, http.MethodGet, 200)
+
+	excluder := fake.Analyzer("excluder").
+		WithResult(analyzer.FinishedWithExclusions(analyzer.Because(baseURL.String(), "intermediate poll"), poll))
+	retainer := fake.Analyzer("retainer").
+		WithResult(analyzer.Retain(analyzer.Because(baseURL.String(), "terminal poll"), poll))
+
+	c := New()
+	c.AddStrategy("excluding-strategy", excluder)
+	c.AddStrategy("retaining-strategy", retainer)
+
+	g.Expect(c.Analyze(t.Context(), log, poll)).To(Succeed())
+
+	output := buffer.String()
+	g.Expect(output).To(ContainSubstring("Retaining interaction selected for removal"))
+	g.Expect(output).To(ContainSubstring("excludingStrategy=excluding-strategy"))
+	g.Expect(output).To(ContainSubstring(`excludedBecause="intermediate poll"`))
+	g.Expect(output).To(ContainSubstring("retainingStrategy=retaining-strategy"))
+	g.Expect(output).To(ContainSubstring(`retainedBecause="terminal poll"`))
+}
+
+// Retention Tests
+
+func TestSetRetention_ForStrategy_AppliesToSpawnedAnalyzers(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	policy := retention.Policy{First: 2, Last: 3}
+
+	spawned := fake.Analyzer("spawned")
+	detector := fake.Analyzer("detector").WithResult(analyzer.Spawn(spawned))
+
+	c := New()
+	c.AddStrategy("testing-strategy", detector)
+	c.SetRetention("testing-strategy", policy)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	g.Expect(c.Analyze(t.Context(), log, fake.Interaction(baseURL, http.MethodGet, 200))).To(Succeed())
+
+	g.Expect(detector.Retention).To(HaveValue(Equal(policy)))
+	g.Expect(spawned.Retention).To(HaveValue(Equal(policy)))
+}
+
+func TestSetRetention_ForStrategy_TakesPrecedenceOverDefault(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	defaultPolicy := retention.Policy{First: 2, Last: 2}
+	strategyPolicy := retention.Policy{First: 1, Last: 1, Every: 4}
+
+	specific := fake.Analyzer("specific")
+	other := fake.Analyzer("other")
+
+	c := New()
+	c.SetRetention("", defaultPolicy)
+	c.AddStrategy("specific-strategy", specific)
+	c.SetRetention("specific-strategy", strategyPolicy)
+	c.SetRetention("", defaultPolicy)
+	c.AddStrategy("other-strategy", other)
+
+	g.Expect(specific.Retention).To(HaveValue(Equal(strategyPolicy)))
+	g.Expect(other.Retention).To(HaveValue(Equal(defaultPolicy)))
+}
+
+func TestSetRetention_NotSet_LeavesAnalyzersUnchanged(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	a := fake.Analyzer("analyzer")
+
+	c := New()
+	c.AddStrategy("testing-strategy", a)
+	c.SetRetention("other-strategy", retention.Policy{First: 3})
+
+	g.Expect(a.Retention).To(BeNil())
+}
+
+func TestRetentionOf_FallsBackToDefaultPolicy(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	c := New()
+	g.Expect(c.RetentionOf("testing-strategy")).To(Equal(retention.DefaultPolicy()))
+
+	c.SetRetention("", retention.Policy{First: 2, Last: 2})
+	c.SetRetention("testing-strategy", retention.Policy{First: 3})
+
+	g.Expect(c.RetentionOf("testing-strategy")).To(Equal(retention.Policy{First: 3}))
+	g.Expect(c.RetentionOf("other-strategy")).To(Equal(retention.Policy{First: 2, Last: 2}))
+}
+
+func TestSetZeroDelays_AppliesToExistingAndSpawnedAnalyzers(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	log := slogt.New(t)
+
+	spawned := fake.Analyzer("spawned")
+	detector := fake.Analyzer("detector").WithResult(analyzer.Spawn(spawned))
+
+	c := New()
+	c.AddStrategy("testing-strategy", detector)
+	c.SetZeroDelays(true)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	g.Expect(c.Analyze(t.Context(), log, fake.Interaction(baseURL, http.MethodGet, 200))).To(Succeed())
+
+	g.Expect(detector.ZeroDelays).To(BeTrue())
+	g.Expect(spawned.ZeroDelays).To(BeTrue())
+}
+
+func TestAnalyze_ResultBothExcludesAndRetains_LogsRetainedProvenance(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	var buffer bytes.Buffer
+
+	log := slog.New(slog.NewTextHandler(&buffer, nil))
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	first := fake.Interaction(baseURL, http.MethodGet, 200)
+	intermediate := fake.Interaction(baseURL, http.MethodGet, 200)
+
+	excluder := fake.Analyzer("excluder").
+		WithResult(analyzer.FinishedWithExclusions(analyzer.Because(baseURL.String(), "any poll"), first))
+	monitor := fake.Analyzer("monitor").
+		WithResult(
+			analyzer.FinishedWithExclusions(analyzer.Because(baseURL.String(), "intermediate poll"), intermediate).
+				AlsoRetain(analyzer.Because(baseURL.String(), "first poll"), first))
+	c := New(excluder, monitor)
+
+	g.Expect(c.Analyze(t.Context(), log, intermediate)).To(Succeed())
+	g.Expect(c.ShouldRemove(first)).To(BeFalse())
+	g.Expect(c.ShouldRemove(intermediate)).To(BeTrue())
+
+	provenance, ok := c.Provenance(intermediate)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(provenance.Reason).To(Equal("intermediate poll"))
+
+	output := buffer.String()
+	g.Expect(output).To(ContainSubstring(`excludedBecause="any poll"`))
+	g.Expect(output).To(ContainSubstring(`retainedBecause="first poll"`))
+}
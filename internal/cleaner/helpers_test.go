@@ -0,0 +1,19 @@
+package cleaner
+
+import (
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+)
+
+/*
+ * Helper functions for testing
+ */
+
+// all returns the active analyzers, in order.
+func (r *registry) all() []analyzer.Interface {
+	result := make([]analyzer.Interface, len(r.entries))
+	for index, reg := range r.entries {
+		result[index] = reg.analyzer
+	}
+
+	return result
+}
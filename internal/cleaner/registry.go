@@ -0,0 +1,50 @@
+package cleaner
+
+import (
+	"slices"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+)
+
+// registry is the set of active analyzers, kept in the order they were registered.
+// Analyzers are run in this order for every interaction, so cleaning (and any logging) is the same on every run.
+// Analyzers spawned while analyzing an interaction are registered after all existing analyzers, so they always run
+// after their parents.
+type registry struct {
+	entries []*registration
+}
+
+// registration is a single active analyzer, along with the cleaning strategy that introduced it (if known).
+type registration struct {
+	analyzer analyzer.Interface
+	strategy string
+}
+
+// add registers an analyzer at the end of the registry.
+func (r *registry) add(strategy string, a analyzer.Interface) {
+	r.entries = append(r.entries, &registration{
+		analyzer: a,
+		strategy: strategy,
+	})
+}
+
+// remove unregisters the specified registrations, preserving the order of those remaining.
+func (r *registry) remove(finished ...*registration) {
+	if len(finished) == 0 {
+		return
+	}
+
+	done := make(map[*registration]bool, len(finished))
+	for _, reg := range finished {
+		done[reg] = true
+	}
+
+	r.entries = slices.DeleteFunc(r.entries, func(reg *registration) bool {
+		return done[reg]
+	})
+}
+
+// snapshot returns the current registrations, in order, safe to iterate while the registry is modified.
+func (r *registry) snapshot() []*registration {
+	return slices.Clone(r.entries)
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
+
+type CheckCommand struct {
+	Stream  bool          `help:"Stream YAML cassettes rather than loading them into memory, for very large files."`
+	Timeout time.Duration `help:"Time allowed for checking each cassette, before it fails." placeholder:"DURATION"`
+
+	Globs []string        `arg:""   help:"Paths to go-vcr cassette or HAR files to check. Globbing allowed." type:"file"`
+	Clean CleaningOptions `embed:"" prefix:"clean-"`
+}
+
+// Run checks each cassette identified by the provided paths, returning an error if any could be reduced.
+// No files are modified.
+func (c *CheckCommand) Run(ctx *Context) error {
+	for _, glob := range c.Globs {
+		if ctx.stopping() {
+			break
+		}
+
+		err := c.checkFilesByGlob(ctx, glob)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ctx.stopping() {
+		return eris.Errorf("interrupted after %d cassettes, remaining cassettes were not checked", ctx.FilesScanned)
+	}
+
+	// Cassettes that could be reduced are counted as those that would be modified
+	if ctx.FilesModified > 0 {
+		return eris.Errorf(
+			"%d of %d cassettes could be reduced; run the clean command with the same options",
+			ctx.FilesModified,
+			ctx.FilesScanned)
+	}
+
+	ctx.Log.Info(
+		"Check complete, all cassettes are clean",
+		"scanned", ctx.FilesScanned,
+	)
+
+	return nil
+}
+
+// checkFilesByGlob checks any cassette files identified by the given glob path.
+func (c *CheckCommand) checkFilesByGlob(ctx *Context, glob string) error {
+	paths, err := findCassettes(ctx, glob)
+	if err != nil {
+		return err
+	}
+
+	// Collect errors, allowing us to attempt checking of all files
+	var errs []error
+
+	for _, path := range paths {
+		if ctx.stopping() {
+			break
+		}
+
+		err := c.checkFile(ctx, path)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return eris.Wrap(
+			errors.Join(errs...),
+			"one or more errors occurred while checking cassette files")
+	}
+
+	return nil
+}
+
+// checkFile checks whether the cassette file at the specified path could be reduced, logging the outcome.
+func (c *CheckCommand) checkFile(ctx *Context, path string) error {
+	options, err := ctx.cleaningOptionsFor(path, &c.Clean)
+	if err != nil {
+		return eris.Wrap(err, "building cleaner options")
+	}
+
+	if c.Stream {
+		options = append(options, vcrcleaner.Streaming())
+	}
+
+	ctx.fileScanned()
+
+	cleaner := vcrcleaner.New(
+		ctx.Log,
+		options...,
+	)
+
+	fileCtx, cancel := ctx.fileContext(c.Timeout)
+	defer cancel()
+
+	plan, err := cleaner.PlanFile(fileCtx, path)
+	if err != nil {
+		return eris.Wrapf(err, "checking cassette file at path %s", path)
+	}
+
+	removals := plan.Removals()
+	rewrites := plan.Rewrites()
+
+	if removals == 0 && rewrites == 0 {
+		ctx.Log.Log(context.Background(), vcrcleaner.LevelVerbose, "Cassette is clean", "path", path)
+
+		return nil
+	}
+
+	ctx.fileModified()
+
+	ctx.Log.Warn(
+		"Cassette could be reduced",
+		"path", path,
+		"interactions", len(plan.Interactions),
+		"removals", removals,
+		"rewrites", rewrites,
+	)
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
+
+func TestCheckRun_WithCleanCassette_Succeeds(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	cassettePath := createTestRecording(t, g, t.TempDir(), "test.yaml")
+
+	c := &CheckCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+		Globs: []string{cassettePath},
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	err := c.Run(ctx)
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ctx.FilesScanned).To(Equal(1))
+	g.Expect(ctx.FilesModified).To(Equal(0))
+}
+
+func TestCheckRun_WithReducibleCassette_ReturnsErrorWithoutModifyingFile(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+	createTestRecording(t, g, tmpDir, "clean.yaml")
+	cassettePath := copyTestData(t, g, "deletion.yaml", tmpDir, "deletion.yaml")
+	original, err := os.ReadFile(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	c := &CheckCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+		Globs: []string{filepath.Join(tmpDir, "*.yaml")},
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	err = c.Run(ctx)
+
+	g.Expect(err).To(MatchError(ContainSubstring("1 of 2 cassettes could be reduced")))
+
+	unchanged, err := os.ReadFile(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(unchanged).To(Equal(original))
+}
+
+func TestCheckRun_AfterClean_Succeeds(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	cassettePath := copyTestData(t, g, "deletion.yaml", t.TempDir(), "deletion.yaml")
+	options := CleaningOptions{
+		strategies: selecting(true, vcrcleaner.StrategyDeletion),
+	}
+
+	clean := &CleanCommand{
+		Clean: options,
+		Globs: []string{cassettePath},
+	}
+
+	err := clean.Run(&Context{Log: slogt.New(t)})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	check := &CheckCommand{
+		Clean: options,
+		Globs: []string{cassettePath},
+	}
+
+	err = check.Run(&Context{Log: slogt.New(t)})
+	g.Expect(err).ToNot(HaveOccurred())
+}
+
+func TestCheckRun_WithNoOptionsSet_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	cassettePath := createTestRecording(t, g, t.TempDir(), "test.yaml")
+
+	c := &CheckCommand{
+		Globs: []string{cassettePath},
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	err := c.Run(ctx)
+
+	g.Expect(err).To(MatchError(ContainSubstring("building cleaner options")))
+}
+
+func TestCheckRun_WhenInterrupted_StopsWithoutCheckingCassettes(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	cassettePath := copyTestData(t, g, "deletion.yaml", t.TempDir(), "deletion.yaml")
+
+	c := &CheckCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+		Globs: []string{cassettePath},
+	}
+
+	ctx := &Context{
+		Log:  slogt.New(t),
+		stop: cancelled(t),
+	}
+
+	err := c.Run(ctx)
+	g.Expect(err).To(MatchError(ContainSubstring("remaining cassettes were not checked")))
+	g.Expect(ctx.FilesScanned).To(Equal(0))
+}
@@ -1,36 +1,75 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"os"
-	"path/filepath"
-	"strings"
+	"time"
 
-	"github.com/phsym/console-slog"
 	"github.com/rotisserie/eris"
 
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/report"
 	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
 )
 
 type CleanCommand struct {
-	Verbose bool `help:"Enable verbose logging."`
-	Debug   bool `help:"Enable debug logging."`
+	DryRun  bool          `help:"Show what would be removed from each cassette, without modifying any files."`
+	Report  string        `help:"Write a Markdown report summarizing the changes to the specified file." type:"path"`
+	Jobs    int           `default:"1" help:"Number of cassettes to clean concurrently."`
+	Stream  bool          `help:"Stream YAML cassettes rather than loading them into memory, for very large files."`
+	Upgrade bool          `help:"Rewrite cassettes recorded by go-vcr v1 and v2 in the current format."`
+	Timeout time.Duration `help:"Time allowed for each cassette; slower ones are left untouched." placeholder:"DURATION"`
 
-	Globs []string        `arg:""   help:"Paths to go-vcr cassette files to clean. Globbing allowed." type:"file"`
+	Globs []string        `arg:""   help:"Paths to go-vcr cassette or HAR files to clean. Globbing allowed." type:"file"`
 	Clean CleaningOptions `embed:"" prefix:"clean-"`
 }
 
 // Run executes the clean command for each provided path.
 func (c *CleanCommand) Run(ctx *Context) error {
+	if c.Report != "" && ctx.Report == nil {
+		ctx.Report = report.NewCleaningReport()
+		if c.DryRun {
+			// Files aren't rewritten, so their size after cleaning isn't known
+			ctx.Report.OmitSizes()
+		}
+	}
+
 	for _, glob := range c.Globs {
+		if ctx.stopping() {
+			break
+		}
+
 		err := c.cleanFilesByGlob(ctx, glob)
 		if err != nil {
 			return err
 		}
 	}
 
+	if ctx.stopping() {
+		return eris.Errorf("interrupted after %d cassettes, remaining cassettes were not cleaned", ctx.FilesScanned)
+	}
+
+	if c.Report != "" {
+		err := ctx.Report.SaveTo(c.Report)
+		if err != nil {
+			return eris.Wrap(err, "saving cleaning report")
+		}
+
+		ctx.Log.Info("Saved cleaning report", "path", c.Report)
+	}
+
 	// Log final summary
+	if c.DryRun {
+		ctx.Log.Info(
+			"Dry run complete, no files modified",
+			"scanned", ctx.FilesScanned,
+			"wouldModify", ctx.FilesModified,
+		)
+
+		return nil
+	}
+
 	ctx.Log.Info(
 		"Cleaning complete",
 		"scanned", ctx.FilesScanned,
@@ -40,12 +79,20 @@ func (c *CleanCommand) Run(ctx *Context) error {
 	return nil
 }
 
-// buildOptions builds the vcrcleaner options based on the CLI flags.
-func (c *CleanCommand) buildOptions() ([]vcrcleaner.Option, error) {
-	options := c.Clean.Options()
+// buildOptions builds the vcrcleaner options for the cassette at the specified path, based on the CLI flags and
+// any project configuration.
+func (c *CleanCommand) buildOptions(ctx *Context, path string) ([]vcrcleaner.Option, error) {
+	options, err := ctx.cleaningOptionsFor(path, &c.Clean)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Stream {
+		options = append(options, vcrcleaner.Streaming())
+	}
 
-	if len(options) == 0 {
-		return nil, eris.New("no cleaning options specified; at least one must be set")
+	if c.Upgrade {
+		options = append(options, vcrcleaner.UpgradeFormat())
 	}
 
 	return options, nil
@@ -53,89 +100,262 @@ func (c *CleanCommand) buildOptions() ([]vcrcleaner.Option, error) {
 
 // cleanFilesByGlob cleans any cassette files identified by the given glob path.
 func (c *CleanCommand) cleanFilesByGlob(ctx *Context, glob string) error {
-	paths, err := filepath.Glob(glob)
+	paths, err := findCassettes(ctx, glob)
 	if err != nil {
-		return eris.Wrap(err, "failed to glob path")
+		return err
 	}
 
-	// Early exit for no matches
 	if len(paths) == 0 {
-		ctx.Log.Info("No cassettes found to clean", "filespec", glob)
-
 		return nil
 	}
 
-	// Log the number of matching files found, for globs only
-	if c.isGlob(glob) {
-		ctx.Log.Info(
-			"Found multiple cassettes to clean",
-			"count", len(paths),
-			"filespec", glob)
+	// Collect errors, allowing us to attempt processing of all files
+	errs := c.cleanFiles(ctx, paths)
+	if len(errs) > 0 {
+		return eris.Wrap(
+			errors.Join(errs...),
+			"one or more errors occurred while cleaning cassette files")
+	}
+
+	return nil
+}
+
+// cleanFiles cleans the cassette files at the specified paths, using a pool of workers to process them concurrently.
+// With more than one worker, log output for each file is buffered and written in path order, so output is
+// deterministic regardless of the number of workers. Returns any errors, also in path order.
+func (c *CleanCommand) cleanFiles(ctx *Context, paths []string) []error {
+	type outcome struct {
+		err  error
+		logs *logBuffer
+	}
+
+	// One channel per path, allowing us to collect outcomes in order
+	outcomes := make([]chan outcome, len(paths))
+	for index := range outcomes {
+		outcomes[index] = make(chan outcome, 1)
+	}
+
+	queue := make(chan int)
+
+	go func() {
+		defer close(queue)
+
+		for index := range paths {
+			queue <- index
+		}
+	}()
+
+	workers := min(max(c.Jobs, 1), len(paths))
+	for range workers {
+		go func() {
+			for index := range queue {
+				if ctx.stopping() {
+					// Interrupted; leave the remaining files alone
+					outcomes[index] <- outcome{}
+
+					continue
+				}
+
+				log, logs := fileLogger(ctx, workers)
+				err := c.cleanFileWithLog(ctx, log, paths[index])
+				outcomes[index] <- outcome{
+					err:  err,
+					logs: logs,
+				}
+			}
+		}()
 	}
 
-	// Collect errors, allowing us to attempt processing of all files
 	var errs []error
 
-	for _, path := range paths {
-		err := c.cleanFile(ctx, path)
-		if err != nil {
-			errs = append(errs, err)
+	for _, ch := range outcomes {
+		o := <-ch
+		if o.logs != nil {
+			o.logs.Replay(context.Background())
+		}
+
+		if o.err != nil {
+			errs = append(errs, o.err)
 		}
 	}
 
-	if len(errs) > 0 {
-		return eris.Wrap(
-			errors.Join(errs...),
-			"one or more errors occurred while cleaning cassette files")
+	return errs
+}
+
+// fileLogger returns the logger to use for cleaning a single file, along with the buffer capturing its output, if any.
+// A single worker cleans files in path order, so its output is written as it happens; otherwise output is buffered.
+func fileLogger(ctx *Context, workers int) (*slog.Logger, *logBuffer) {
+	if workers <= 1 {
+		return ctx.Log, nil
 	}
 
-	return nil
+	return newBufferedLogger(ctx.Log.Handler())
 }
 
-// cleanFile cleans the cassette file at the specified path.
-func (c *CleanCommand) cleanFile(ctx *Context, path string) error {
-	options, err := c.buildOptions()
+// cleanFileWithLog cleans the cassette file at the specified path, using the specified logger.
+func (c *CleanCommand) cleanFileWithLog(
+	ctx *Context,
+	log *slog.Logger,
+	path string,
+) error {
+	options, err := c.buildOptions(ctx, path)
 	if err != nil {
 		return eris.Wrap(err, "building cleaner options")
 	}
 
-	ctx.FilesScanned++
+	ctx.fileScanned()
 
 	cleaner := vcrcleaner.New(
-		ctx.Log,
+		log,
 		options...,
 	)
 
-	modified, err := cleaner.CleanFile(path)
+	bytesBefore := c.fileSize(path)
+
+	if c.DryRun {
+		err = c.planFile(ctx, log, cleaner, path)
+	} else {
+		err = c.cleanCassetteFile(ctx, cleaner, path)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	c.summarize(ctx, cleaner, path, bytesBefore)
+
+	return nil
+}
+
+// cleanCassetteFile cleans the cassette file at the specified path, saving any changes.
+func (c *CleanCommand) cleanCassetteFile(
+	ctx *Context,
+	cleaner *vcrcleaner.Cleaner,
+	path string,
+) error {
+	fileCtx, cancel := ctx.fileContext(c.Timeout)
+	defer cancel()
+
+	modified, err := cleaner.CleanFile(fileCtx, path)
 	if err != nil {
 		return eris.Wrapf(err, "cleaning cassette file at path %s", path)
 	}
 
 	if modified {
-		ctx.FilesModified++
+		ctx.fileModified()
 	}
 
 	return nil
 }
 
-// CreateLogger builds a slog logger configured from the CleanCommand flags.
-func (c *CleanCommand) CreateLogger() *slog.Logger {
-	level := slog.LevelInfo
-	if c.Debug {
-		level = slog.LevelDebug
-	} else if c.Verbose {
-		level = vcrcleaner.LevelVerbose
+// summarize adds a summary of the changes made to the cassette at the specified path to the report, if requested.
+// bytesBefore is the size of the file before cleaning.
+func (c *CleanCommand) summarize(
+	ctx *Context,
+	cleaner *vcrcleaner.Cleaner,
+	path string,
+	bytesBefore int64,
+) {
+	stats := cleaner.Statistics()
+	if stats.Interactions == 0 {
+		// Not a cassette, or nothing in it; nothing to report
+		return
 	}
 
-	opts := &console.HandlerOptions{
-		Level: level,
+	ctx.summarize(report.CassetteSummary{
+		Path:               path,
+		InteractionsBefore: stats.Interactions,
+		InteractionsAfter:  stats.Interactions - stats.Removed,
+		BytesBefore:        bytesBefore,
+		BytesAfter:         c.fileSize(path),
+		DurationBefore:     stats.Duration,
+		DurationAfter:      stats.RemainingDuration(),
+		RemovedByStrategy:  stats.RemovedByStrategy,
+
+		RemovedDurationByStrategy: stats.RemovedDurationByStrategy,
+	})
+}
+
+// fileSize returns the size of the file at the specified path, or zero if it can't be determined.
+func (*CleanCommand) fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
 	}
-	handler := console.NewHandler(os.Stderr, opts)
 
-	return slog.New(handler)
+	return info.Size()
 }
 
-// isGlob checks if the provided path contains any globbing characters.
-func (*CleanCommand) isGlob(path string) bool {
-	return strings.ContainsAny(path, "*?[]")
+// planFile plans the cleaning of the cassette file at the specified path, logging the outcome for each interaction.
+func (c *CleanCommand) planFile(
+	ctx *Context,
+	log *slog.Logger,
+	cleaner *vcrcleaner.Cleaner,
+	path string,
+) error {
+	fileCtx, cancel := ctx.fileContext(c.Timeout)
+	defer cancel()
+
+	plan, err := cleaner.PlanFile(fileCtx, path)
+	if err != nil {
+		return eris.Wrapf(err, "planning cassette file at path %s", path)
+	}
+
+	for _, step := range plan.Interactions {
+		if step.Remove {
+			log.Info(
+				"Would remove interaction",
+				"path", plan.Path,
+				"id", step.ID,
+				"method", step.Method,
+				"status", step.StatusCode,
+				"url", step.URL,
+				"analyzer", step.Provenance.Analyzer,
+				"strategy", step.Provenance.Strategy,
+				"reason", step.Provenance.Reason,
+			)
+		} else if step.Rewrite {
+			log.Info(
+				"Would rewrite interaction",
+				"path", plan.Path,
+				"id", step.ID,
+				"method", step.Method,
+				"status", step.StatusCode,
+				"url", step.URL,
+			)
+		} else {
+			log.Debug(
+				"Would keep interaction",
+				"path", plan.Path,
+				"id", step.ID,
+				"method", step.Method,
+				"status", step.StatusCode,
+				"url", step.URL,
+			)
+		}
+	}
+
+	if plan.Upgrade {
+		log.Info("Would upgrade cassette format", "path", plan.Path)
+	}
+
+	removals := plan.Removals()
+	rewrites := plan.Rewrites()
+
+	if removals > 0 || rewrites > 0 || plan.Upgrade {
+		ctx.fileModified()
+
+		log.Info(
+			"Cassette would be modified",
+			"path", plan.Path,
+			"interactions", len(plan.Interactions),
+			"removals", removals,
+			"rewrites", rewrites,
+			"upgrade", plan.Upgrade,
+		)
+	} else {
+		log.Log(context.Background(), vcrcleaner.LevelVerbose, "No change to cassette", "path", plan.Path)
+	}
+
+	return nil
 }
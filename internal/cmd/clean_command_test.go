@@ -1,14 +1,22 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
 	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/cassettefile"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/har"
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
 )
 
 // buildOptions Tests
@@ -95,13 +103,15 @@ func TestBuildOptions(t *testing.T) {
 			g := NewWithT(t)
 
 			cmd := &CleanCommand{}
-			cmd.Clean.DeferredCreations = c.deferredCreations
-			cmd.Clean.Deletes = c.deletes
-			cmd.Clean.Azure.LongRunningOperations = c.longRunningOperations
-			cmd.Clean.Azure.ResourceModifications = c.resourceModifications
-			cmd.Clean.Azure.ResourceDeletions = c.resourceDeletions
+			cmd.Clean.strategies = map[string]*bool{
+				vcrcleaner.StrategyDeferredCreation:          c.deferredCreations,
+				vcrcleaner.StrategyDeletion:                  c.deletes,
+				vcrcleaner.StrategyAzureLongRunningOperation: c.longRunningOperations,
+				vcrcleaner.StrategyAzureResourceModification: c.resourceModifications,
+				vcrcleaner.StrategyAzureResourceDeletion:     c.resourceDeletions,
+			}
 
-			options, err := cmd.buildOptions()
+			options, err := cmd.buildOptions(&Context{}, "cassette.yaml")
 
 			if c.expectedErrorSubstring != "" {
 				g.Expect(err).To(MatchError(ContainSubstring(c.expectedErrorSubstring)))
@@ -123,7 +133,7 @@ func TestCleanGlob_WithNoMatchingFiles_LogsAndSucceeds(t *testing.T) {
 	tmpDir := t.TempDir()
 	c := &CleanCommand{
 		Clean: CleaningOptions{
-			Deletes: toPtr(true),
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
 		},
 	}
 
@@ -145,7 +155,7 @@ func TestCleanGlob_WithSingleMatchingFile_CleansFile(t *testing.T) {
 	cassettePath := createTestRecording(t, g, tmpDir, "test.yaml")
 	c := &CleanCommand{
 		Clean: CleaningOptions{
-			Deletes: toPtr(true),
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
 		},
 	}
 
@@ -175,7 +185,7 @@ func TestCleanGlob_WithMultipleMatchingFiles_CleansAllFiles(t *testing.T) {
 
 	c := &CleanCommand{
 		Clean: CleaningOptions{
-			Deletes: toPtr(true),
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
 		},
 	}
 
@@ -195,7 +205,7 @@ func TestCleanGlob_WithInvalidGlobPattern_ReturnsError(t *testing.T) {
 
 	c := &CleanCommand{
 		Clean: CleaningOptions{
-			Deletes: toPtr(true),
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
 		},
 	}
 
@@ -219,7 +229,7 @@ func TestCleanPath_WithValidCassette_CleansSuccessfully(t *testing.T) {
 	cassettePath := createTestRecording(t, g, tmpDir, "test.yaml")
 	c := &CleanCommand{
 		Clean: CleaningOptions{
-			Deletes: toPtr(true),
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
 		},
 	}
 
@@ -227,7 +237,7 @@ func TestCleanPath_WithValidCassette_CleansSuccessfully(t *testing.T) {
 		Log: slogt.New(t),
 	}
 
-	err := c.cleanFile(ctx, cassettePath)
+	err := c.cleanFileWithLog(ctx, ctx.Log, cassettePath)
 
 	g.Expect(err).ToNot(HaveOccurred())
 }
@@ -245,7 +255,7 @@ func TestCleanPath_WithNoOptionsSet_ReturnsError(t *testing.T) {
 		Log: slogt.New(t),
 	}
 
-	err := c.cleanFile(ctx, cassettePath)
+	err := c.cleanFileWithLog(ctx, ctx.Log, cassettePath)
 
 	g.Expect(err).To(MatchError(ContainSubstring("building cleaner options")))
 }
@@ -256,7 +266,7 @@ func TestCleanPath_WithNonexistentFile_SkipsFile(t *testing.T) {
 
 	c := &CleanCommand{
 		Clean: CleaningOptions{
-			Deletes: toPtr(true),
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
 		},
 	}
 
@@ -264,13 +274,69 @@ func TestCleanPath_WithNonexistentFile_SkipsFile(t *testing.T) {
 		Log: slogt.New(t),
 	}
 
-	err := c.cleanFile(ctx, filepath.Join(t.TempDir(), "nonexistent", "path", "cassette.yaml"))
+	err := c.cleanFileWithLog(ctx, ctx.Log, filepath.Join(t.TempDir(), "nonexistent", "path", "cassette.yaml"))
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(ctx.FilesScanned).To(Equal(1))
 	g.Expect(ctx.FilesModified).To(Equal(0))
 }
 
+func TestCleanPath_WithDeletionRecording_ModifiesFile(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	cassettePath := copyTestData(t, g, "deletion.yaml", t.TempDir(), "deletion.yaml")
+	original, err := os.ReadFile(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	c := &CleanCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	err = c.cleanFileWithLog(ctx, ctx.Log, cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ctx.FilesModified).To(Equal(1))
+
+	cleaned, err := os.ReadFile(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cleaned).ToNot(Equal(original))
+}
+
+func TestCleanPath_WithDryRun_LeavesFileUnchanged(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	cassettePath := copyTestData(t, g, "deletion.yaml", t.TempDir(), "deletion.yaml")
+	original, err := os.ReadFile(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	c := &CleanCommand{
+		DryRun: true,
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	err = c.cleanFileWithLog(ctx, ctx.Log, cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ctx.FilesScanned).To(Equal(1))
+	g.Expect(ctx.FilesModified).To(Equal(1), "dry run should count files that would be modified")
+
+	unchanged, err := os.ReadFile(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(unchanged).To(Equal(original))
+}
+
 // Run Tests
 
 func TestRun_WithSingleGlob_ProcessesSuccessfully(t *testing.T) {
@@ -282,7 +348,7 @@ func TestRun_WithSingleGlob_ProcessesSuccessfully(t *testing.T) {
 
 	c := &CleanCommand{
 		Clean: CleaningOptions{
-			Deletes: toPtr(true),
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
 		},
 		Globs: []string{cassettePath},
 	}
@@ -311,7 +377,7 @@ func TestRun_WithMultipleGlobs_ProcessesAllSuccessfully(t *testing.T) {
 
 	c := &CleanCommand{
 		Clean: CleaningOptions{
-			Deletes: toPtr(true),
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
 		},
 		Globs: globs,
 	}
@@ -348,7 +414,7 @@ func TestRun_WithErrorInGlob_PropagatesError(t *testing.T) {
 
 	c := &CleanCommand{
 		Clean: CleaningOptions{
-			Deletes: toPtr(true),
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
 		},
 		Globs: []string{filepath.Join(t.TempDir(), "test[.yaml")},
 	}
@@ -362,6 +428,237 @@ func TestRun_WithErrorInGlob_PropagatesError(t *testing.T) {
 	g.Expect(err).To(MatchError(ContainSubstring("failed to glob path")))
 }
 
+func TestRun_WithReport_WritesMarkdownReport(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+	cassettePath := copyTestData(t, g, "deletion.yaml", tmpDir, "deletion.yaml")
+	reportPath := filepath.Join(tmpDir, "report.md")
+
+	c := &CleanCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+		Globs:  []string{cassettePath},
+		Report: reportPath,
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	err := c.Run(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	content, err := os.ReadFile(reportPath)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// Four GETs are accumulated while waiting for the deletion, of which the middle two are removed
+	rpt := string(content)
+	g.Expect(rpt).To(MatchRegexp(`\| deletion +\|`))
+	g.Expect(rpt).To(MatchRegexp(
+		`\| %s +\| 6 +\| 4 +\| \d+ +\| \d+ +\| 1\.5s +\| 1s +\| 2 \(500ms\) +\|`,
+		regexp.QuoteMeta(cassettePath)))
+	g.Expect(rpt).To(MatchRegexp(`\| \*\*Total\*\* +\| 6 +\| 4 +\|`))
+}
+
+func TestRun_DryRunWithReport_OmitsFileSizes(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+	cassettePath := copyTestData(t, g, "deletion.yaml", tmpDir, "deletion.yaml")
+	reportPath := filepath.Join(tmpDir, "report.md")
+
+	c := &CleanCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+		DryRun: true,
+		Globs:  []string{cassettePath},
+		Report: reportPath,
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	err := c.Run(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// The cassette isn't rewritten, so only the planned interaction counts and durations are reported
+	content, err := os.ReadFile(reportPath)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	rpt := string(content)
+	g.Expect(rpt).ToNot(ContainSubstring("Bytes"))
+	g.Expect(rpt).To(MatchRegexp(
+		`\| %s +\| 6 +\| 4 +\| 1\.5s +\| 1s +\| 2 \(500ms\) +\|`,
+		regexp.QuoteMeta(cassettePath)))
+}
+
+func TestRun_WithCompressDurations_ReportsCompressedDurations(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+	cassettePath := copyTestData(t, g, "deletion.yaml", tmpDir, "deletion.yaml")
+	reportPath := filepath.Join(tmpDir, "report.md")
+
+	c := &CleanCommand{
+		Clean: CleaningOptions{
+			strategies:        selecting(true, vcrcleaner.StrategyDeletion),
+			CompressDurations: toPtr(100 * time.Millisecond),
+		},
+		Globs:  []string{cassettePath},
+		Report: reportPath,
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	err := c.Run(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// Each of the six interactions was recorded at 250ms; two are removed and the remaining four capped at 100ms
+	content, err := os.ReadFile(reportPath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(content)).To(MatchRegexp(`\| 1\.5s +\| 400ms +\| 2 \(500ms\) +\|`))
+
+	cleaned, err := os.ReadFile(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(cleaned)).To(ContainSubstring("duration: 100ms"))
+	g.Expect(string(cleaned)).ToNot(ContainSubstring("duration: 250ms"))
+}
+
+func TestRun_WithCompressedJSONCassette_PreservesEncoding(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+	source, err := cassettefile.Load(filepath.Join("testdata", "deletion.yaml"))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	encoding := cassettefile.Encoding{Format: cassettefile.JSON, Compressed: true}
+	cassettePath := filepath.Join(tmpDir, "deletion.json.gz")
+	g.Expect(source.SaveAs(cassettePath, encoding)).To(Succeed())
+
+	c := &CleanCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+		Globs: []string{filepath.Join(tmpDir, "*.json.gz")},
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	err = c.Run(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ctx.FilesModified).To(Equal(1))
+
+	cleaned, err := cassettefile.Load(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cleaned.Encoding).To(Equal(encoding))
+	g.Expect(cleaned.Cassette.Interactions).To(HaveLen(4))
+}
+
+func TestRun_WithMultipleJobs_CleansAllFiles(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+
+	for i := range 8 {
+		copyTestData(t, g, "deletion.yaml", tmpDir, "deletion"+strconv.Itoa(i+1)+".yaml")
+	}
+
+	c := &CleanCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+		Globs: []string{filepath.Join(tmpDir, "*.yaml")},
+		Jobs:  4,
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	err := c.Run(ctx)
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ctx.FilesScanned).To(Equal(8))
+	g.Expect(ctx.FilesModified).To(Equal(8))
+}
+
+func TestRun_WithMultipleJobs_LogsSameAsSingleJob(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	sequential := captureCleaningLog(t, g, 1)
+	concurrent := captureCleaningLog(t, g, 4)
+
+	g.Expect(concurrent).To(Equal(sequential))
+}
+
+func TestFileLogger_SingleWorker_LogsAsFilesAreCleaned(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	log, logs := fileLogger(ctx, 1)
+
+	g.Expect(log).To(BeIdenticalTo(ctx.Log))
+	g.Expect(logs).To(BeNil())
+}
+
+func TestFileLogger_SeveralWorkers_BuffersLogs(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	log, logs := fileLogger(ctx, 4)
+
+	g.Expect(log).ToNot(BeIdenticalTo(ctx.Log))
+	g.Expect(logs).ToNot(BeNil())
+}
+
+func TestRun_WithMultipleJobs_AggregatesErrors(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+
+	for i := range 3 {
+		createTestRecording(t, g, tmpDir, "test"+strconv.Itoa(i+1)+".yaml")
+	}
+
+	// No cleaning options, so every file fails
+	c := &CleanCommand{
+		Globs: []string{filepath.Join(tmpDir, "*.yaml")},
+		Jobs:  3,
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	err := c.Run(ctx)
+
+	g.Expect(err).To(MatchError(ContainSubstring("one or more errors occurred while cleaning cassette files")))
+	g.Expect(strings.Count(err.Error(), "building cleaner options")).To(Equal(3))
+}
+
 // Statistics Tests
 
 func TestRun_TracksFilesScanned(t *testing.T) {
@@ -377,7 +674,7 @@ func TestRun_TracksFilesScanned(t *testing.T) {
 
 	c := &CleanCommand{
 		Clean: CleaningOptions{
-			Deletes: toPtr(true),
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
 		},
 		Globs: []string{filepath.Join(tmpDir, "*.yaml")},
 	}
@@ -406,7 +703,7 @@ func TestRun_TracksFilesModified(t *testing.T) {
 
 	c := &CleanCommand{
 		Clean: CleaningOptions{
-			Deletes: toPtr(true),
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
 		},
 		Globs: []string{filepath.Join(tmpDir, "*.yaml")},
 	}
@@ -421,3 +718,155 @@ func TestRun_TracksFilesModified(t *testing.T) {
 	g.Expect(ctx.FilesScanned).To(Equal(3))
 	g.Expect(ctx.FilesModified).To(Equal(0)) // sample.yaml has no DELETE operations
 }
+
+func TestCleanPath_WithHAR_RemovesEntries(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+	cassettePath := copyTestData(t, g, "deletion.yaml", tmpDir, "deletion.yaml")
+	harPath := filepath.Join(tmpDir, "deletion.har")
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	convert := &ConvertCommand{Source: cassettePath, Destination: harPath}
+	g.Expect(convert.Run(ctx)).To(Succeed())
+
+	before, err := har.Load(harPath)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	c := &CleanCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+	}
+
+	g.Expect(c.cleanFileWithLog(ctx, ctx.Log, harPath)).To(Succeed())
+	g.Expect(ctx.FilesModified).To(Equal(1))
+
+	after, err := har.Load(harPath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(len(after.Log.Entries)).To(BeNumerically("<", len(before.Log.Entries)))
+}
+
+func TestCleanPath_WithUpgrade_RewritesLegacyCassette(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	cassettePath := copyTestData(t, g, "deletion.yaml", t.TempDir(), "deletion.yaml")
+
+	// Rewrite the cassette as go-vcr v1 and v2 would have recorded it
+	legacy, err := cassettefile.Load(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	legacy.Version = cassettefile.LegacyVersion
+	g.Expect(legacy.SaveAs(cassettePath, legacy.Encoding)).To(Succeed())
+
+	c := &CleanCommand{
+		Upgrade: true,
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	g.Expect(c.cleanFileWithLog(ctx, ctx.Log, cassettePath)).To(Succeed())
+	g.Expect(ctx.FilesModified).To(Equal(1))
+
+	upgraded, err := cassettefile.Load(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(upgraded.Version).To(Equal(cassettefile.CurrentVersion))
+	g.Expect(len(upgraded.Cassette.Interactions)).To(BeNumerically("<", len(legacy.Cassette.Interactions)))
+}
+
+func TestRun_WhenInterrupted_StopsWithoutStartingCassettes(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+	cassettePath := copyTestData(t, g, "deletion.yaml", tmpDir, "deletion.yaml")
+	original, err := os.ReadFile(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	c := &CleanCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+		Globs: []string{filepath.Join(tmpDir, "*.yaml")},
+		Jobs:  2,
+	}
+
+	ctx := &Context{
+		Log:  slogt.New(t),
+		stop: cancelled(t),
+	}
+
+	err = c.Run(ctx)
+	g.Expect(err).To(MatchError(ContainSubstring("remaining cassettes were not cleaned")))
+	g.Expect(ctx.FilesScanned).To(Equal(0))
+
+	content, err := os.ReadFile(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(content).To(Equal(original))
+}
+
+func TestCleanPath_WhenAbandoned_LeavesFileUnchanged(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	cassettePath := copyTestData(t, g, "deletion.yaml", t.TempDir(), "deletion.yaml")
+	original, err := os.ReadFile(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	c := &CleanCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+	}
+
+	ctx := &Context{
+		Log:     slogt.New(t),
+		abandon: cancelled(t),
+	}
+
+	err = c.cleanFileWithLog(ctx, ctx.Log, cassettePath)
+	g.Expect(err).To(MatchError(context.Canceled))
+	g.Expect(ctx.FilesModified).To(Equal(0))
+
+	content, err := os.ReadFile(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(content).To(Equal(original))
+}
+
+func TestCleanFiles_WhenInterrupted_SkipsRemainingFiles(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+
+	paths := make([]string, 0, 4)
+	for i := range 4 {
+		paths = append(paths, copyTestData(t, g, "deletion.yaml", tmpDir, "deletion"+strconv.Itoa(i+1)+".yaml"))
+	}
+
+	c := &CleanCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+		Jobs: 2,
+	}
+
+	ctx := &Context{
+		Log:  slogt.New(t),
+		stop: cancelled(t),
+	}
+
+	errs := c.cleanFiles(ctx, paths)
+	g.Expect(errs).To(BeEmpty())
+	g.Expect(ctx.FilesScanned).To(Equal(0))
+}
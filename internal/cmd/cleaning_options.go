@@ -1,40 +1,124 @@
 package cmd
 
-import "github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+import (
+	"cmp"
+	"time"
 
+	"github.com/alecthomas/kong"
+	"github.com/rotisserie/eris"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
+
+//nolint:revive // Struct tags are clearer kept on a single line
 type CleaningOptions struct {
-	All               *bool `help:"Clean all supported interaction types."`
-	DeferredCreations *bool `help:"Clean deferred creation interactions."`
-	Deletes           *bool `help:"Clean delete interactions."`
+	All *bool `help:"Clean all supported interaction types." yaml:"all"`
+
+	// Flags holds the command line flags selecting strategies and families, generated by AddStrategyFlags.
+	Flags kong.Plugins `embed:"" yaml:"-"`
+
+	Rules string `help:"Clean polling described by declarative rules in a YAML file." type:"existingfile" yaml:"rules"`
+
+	ZeroPollingDelays *bool `help:"Zero Retry-After delays on retained polling interactions." yaml:"zeroPollingDelays"`
 
-	Azure AzureCleaningOptions `embed:"" prefix:"azure-"`
+	CompressDurations *time.Duration `help:"Cap recorded durations, so replays simulating latency run faster." placeholder:"DURATION" yaml:"compressDurations"`
+
+	Retain RetentionOptions `embed:"" prefix:"retain-" yaml:"retain"`
+
+	// Selections are unexported rather than tagged kong:"-", as kong would also ignore any other field sharing their
+	// names, such as Retain.Strategies.
+	strategies     map[string]*bool // Selection of individual strategies, keyed by strategy name
+	families       map[string]*bool // Selection of every strategy in a family (other than generic), keyed by family
+	selectionFlags []selectionFlag  // Flags to copy into strategies and families once the command line is parsed
 }
 
+// Options builds the vcrcleaner options for the selected strategies, in the order the strategies were registered.
 func (opt *CleaningOptions) Options() []vcrcleaner.Option {
 	var result []vcrcleaner.Option
-	if opt.ShouldCleanDeferredCreations() {
-		result = append(result, vcrcleaner.ReduceDeferredCreationMonitoring())
+	for _, s := range vcrcleaner.Strategies() {
+		if opt.ShouldClean(s) {
+			result = append(result, s.New())
+		}
+	}
+
+	return result
+}
+
+// RequiredOptions returns the vcrcleaner options selected, including any declarative polling rules, returning an
+// error if none are.
+// loadRules is used to load the polling rules file, if one is selected.
+func (opt *CleaningOptions) RequiredOptions(
+	loadRules func(path string) (*vcrcleaner.PollingRules, error),
+) ([]vcrcleaner.Option, error) {
+	options := opt.Options()
+
+	if opt.Rules != "" {
+		rules, err := loadRules(opt.Rules)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, vcrcleaner.ReducePollingByRules(rules))
 	}
 
-	if opt.ShouldCleanDeletes() {
-		result = append(result, vcrcleaner.ReduceDeleteMonitoring())
+	if len(options) == 0 {
+		return nil, eris.New("no cleaning options specified; at least one must be set")
 	}
 
-	azureOptions := opt.Azure.Options(opt.All)
-	result = append(result, azureOptions...)
+	if opt.ZeroPollingDelays != nil && *opt.ZeroPollingDelays {
+		options = append(options, vcrcleaner.ZeroPollingDelays())
+	}
 
-	return result
+	if opt.CompressDurations != nil {
+		options = append(options, vcrcleaner.CompressDurations(*opt.CompressDurations))
+	}
+
+	retain, err := opt.Retain.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(options, retain...), nil
 }
 
-func (opt *CleaningOptions) ShouldCleanDeletes() bool {
-	return opt.coalesce(
-		opt.Deletes,
-		opt.All)
+// Override returns options where any selection made by opt takes precedence over those made by base.
+// Each strategy is resolved using the same precedence as ShouldClean, checking opt before base, so that
+// (for example) --clean-all overrides deletes: false in a config file.
+func (opt *CleaningOptions) Override(base *CleaningOptions) *CleaningOptions {
+	strategies := make(map[string]*bool)
+	for _, s := range vcrcleaner.Strategies() {
+		selected := firstSet(
+			opt.strategies[s.Name],
+			opt.families[s.Family],
+			opt.All,
+			base.strategies[s.Name],
+			base.families[s.Family],
+			base.All)
+		if selected != nil {
+			strategies[s.Name] = selected
+		}
+	}
+
+	return &CleaningOptions{
+		strategies: strategies,
+		Rules:      cmp.Or(opt.Rules, base.Rules),
+		ZeroPollingDelays: firstSet(
+			opt.ZeroPollingDelays,
+			base.ZeroPollingDelays),
+		CompressDurations: firstSet(
+			opt.CompressDurations,
+			base.CompressDurations),
+		Retain: opt.Retain.override(&base.Retain),
+	}
 }
 
-func (opt *CleaningOptions) ShouldCleanDeferredCreations() bool {
+// ShouldClean indicates whether the specified strategy has been selected.
+// Selecting a strategy by name overrides the selection of its family, which in turn overrides the general 'All'
+// option.
+func (opt *CleaningOptions) ShouldClean(strategy vcrcleaner.Strategy) bool {
 	return opt.coalesce(
-		opt.DeferredCreations,
+		opt.strategies[strategy.Name],
+		opt.families[strategy.Family],
 		opt.All)
 }
 
@@ -47,3 +131,14 @@ func (*CleaningOptions) coalesce(opts ...*bool) bool {
 
 	return false
 }
+
+// firstSet returns the first of the provided options that has been set, or nil if none have.
+func firstSet[T any](opts ...*T) *T {
+	for _, o := range opts {
+		if o != nil {
+			return o
+		}
+	}
+
+	return nil
+}
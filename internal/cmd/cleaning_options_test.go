@@ -1,9 +1,17 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
 )
 
 // CleaningOptions.Options Tests
@@ -62,10 +70,12 @@ func TestCleaningOptions_Options(t *testing.T) {
 			g := NewWithT(t)
 
 			opt := &CleaningOptions{
-				DeferredCreations: c.deferredCreations,
-				Deletes:           c.deletes,
-				Azure: AzureCleaningOptions{
-					All: c.azureAll,
+				strategies: map[string]*bool{
+					vcrcleaner.StrategyDeferredCreation: c.deferredCreations,
+					vcrcleaner.StrategyDeletion:         c.deletes,
+				},
+				families: map[string]*bool{
+					vcrcleaner.FamilyAzure: c.azureAll,
 				},
 			}
 
@@ -76,26 +86,52 @@ func TestCleaningOptions_Options(t *testing.T) {
 	}
 }
 
-// CleaningOptions.ShouldCleanDeletes Tests
+// CleaningOptions.ShouldClean Tests
 
-func TestCleaningOptions_ShouldCleanDeletes(t *testing.T) {
+//nolint:funlen // Table test cases are extensive but clear
+func TestCleaningOptions_ShouldClean(t *testing.T) {
 	t.Parallel()
 
 	cases := map[string]struct {
-		deletes  *bool
+		all      *bool
+		azureAll *bool
+		strategy *bool
 		expected bool
 	}{
-		"WithDeletesTrue_ReturnsTrue": {
-			deletes:  toPtr(true),
+		"WithNothingSet_ReturnsFalse": {
+			expected: false,
+		},
+		"WithStrategyTrue_ReturnsTrue": {
+			strategy: toPtr(true),
+			expected: true,
+		},
+		"WithStrategyFalse_ReturnsFalse": {
+			strategy: toPtr(false),
+			expected: false,
+		},
+		"WithAllTrue_ReturnsTrue": {
+			all:      toPtr(true),
+			expected: true,
+		},
+		"WithFamilyTrue_ReturnsTrue": {
+			azureAll: toPtr(true),
 			expected: true,
 		},
-		"WithDeletesFalse_ReturnsFalse": {
-			deletes:  toPtr(false),
+		"WithAllTrueAndFamilyFalse_ReturnsFalse": {
+			all:      toPtr(true),
+			azureAll: toPtr(false),
 			expected: false,
 		},
-		"WithDeletesNil_ReturnsFalse": {
+		"WithFamilyTrueAndStrategyFalse_ReturnsFalse": {
+			azureAll: toPtr(true),
+			strategy: toPtr(false),
 			expected: false,
 		},
+		"WithAllFalseAndStrategyTrue_ReturnsTrue": {
+			all:      toPtr(false),
+			strategy: toPtr(true),
+			expected: true,
+		},
 	}
 
 	for name, c := range cases {
@@ -104,35 +140,260 @@ func TestCleaningOptions_ShouldCleanDeletes(t *testing.T) {
 			g := NewWithT(t)
 
 			opt := &CleaningOptions{
-				Deletes: c.deletes,
+				All: c.all,
+				strategies: map[string]*bool{
+					vcrcleaner.StrategyAzureLongRunningOperation: c.strategy,
+				},
+				families: map[string]*bool{
+					vcrcleaner.FamilyAzure: c.azureAll,
+				},
 			}
 
-			result := opt.ShouldCleanDeletes()
-
-			g.Expect(result).To(Equal(c.expected))
+			g.Expect(shouldClean(opt, vcrcleaner.StrategyAzureLongRunningOperation)).To(Equal(c.expected))
 		})
 	}
 }
 
-// CleaningOptions.ShouldCleanDeferredCreations Tests
+func TestCleaningOptions_ShouldClean_WithFamilySet_IgnoresOtherFamilies(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	opt := &CleaningOptions{
+		families: map[string]*bool{
+			vcrcleaner.FamilyAzure: toPtr(true),
+		},
+	}
+
+	g.Expect(shouldClean(opt, vcrcleaner.StrategyDeletion)).To(BeFalse())
+}
+
+// Selection precedence Tests
+
+// strategySelection is a selection of a single strategy, its family and all strategies, as made by flags or a project
+// configuration file. Any of them may be left unset.
+type strategySelection struct {
+	all      *bool
+	family   *bool
+	strategy *bool
+}
+
+// flags returns the command line flags making the selection for the specified strategy.
+func (sel strategySelection) flags(strategy vcrcleaner.Strategy) []string {
+	var result []string
+	if sel.all != nil {
+		result = append(result, "--clean-all="+strconv.FormatBool(*sel.all))
+	}
+
+	if sel.family != nil {
+		result = append(result, "--clean-"+strategy.Family+"-all="+strconv.FormatBool(*sel.family))
+	}
+
+	if sel.strategy != nil {
+		result = append(result, "--clean-"+flagName(strategy)+"="+strconv.FormatBool(*sel.strategy))
+	}
+
+	return result
+}
+
+// config returns the content of a project configuration file making the selection for the specified strategy.
+func (sel strategySelection) config(strategy vcrcleaner.Strategy) string {
+	var builder strings.Builder
+
+	builder.WriteString("clean:\n")
+
+	if sel.all != nil {
+		builder.WriteString("  all: " + strconv.FormatBool(*sel.all) + "\n")
+	}
+
+	if strategy.Family == vcrcleaner.FamilyGeneric {
+		if sel.strategy != nil {
+			builder.WriteString("  " + configKey(strategy.Flag) + ": " + strconv.FormatBool(*sel.strategy) + "\n")
+		}
+
+		return builder.String()
+	}
+
+	if sel.family != nil || sel.strategy != nil {
+		builder.WriteString("  " + configKey(strategy.Family) + ":\n")
+	}
+
+	if sel.family != nil {
+		builder.WriteString("    all: " + strconv.FormatBool(*sel.family) + "\n")
+	}
+
+	if sel.strategy != nil {
+		builder.WriteString("    " + configKey(strategy.Flag) + ": " + strconv.FormatBool(*sel.strategy) + "\n")
+	}
+
+	return builder.String()
+}
+
+// selectedFor returns whether the selection applies to other, a strategy that may or may not be the one selected
+// individually.
+func (sel strategySelection) selectedFor(strategy vcrcleaner.Strategy, other vcrcleaner.Strategy) bool {
+	var family *bool
+	if other.Family == strategy.Family {
+		family = sel.family
+	}
+
+	if other.Name == strategy.Name && sel.strategy != nil {
+		return *sel.strategy
+	}
 
-func TestCleaningOptions_ShouldCleanDeferredCreations(t *testing.T) {
+	if family != nil {
+		return *family
+	}
+
+	return sel.all != nil && *sel.all
+}
+
+//nolint:funlen // Table test cases are extensive but clear
+func TestCleaningOptions_SelectionPrecedence_ForEachStrategy(t *testing.T) {
 	t.Parallel()
 
 	cases := map[string]struct {
-		deferredCreations *bool
-		expected          bool
+		selection strategySelection
+		expected  bool
 	}{
-		"WithDeferredCreationsTrue_ReturnsTrue": {
-			deferredCreations: toPtr(true),
-			expected:          true,
+		"WithNothingSet_NotSelected": {
+			expected: false,
 		},
-		"WithDeferredCreationsFalse_ReturnsFalse": {
-			deferredCreations: toPtr(false),
-			expected:          false,
+		"WithAllTrue_Selected": {
+			selection: strategySelection{all: toPtr(true)},
+			expected:  true,
 		},
-		"WithDeferredCreationsNil_ReturnsFalse": {
-			expected: false,
+		"WithAllFalse_NotSelected": {
+			selection: strategySelection{all: toPtr(false)},
+			expected:  false,
+		},
+		"WithFamilyTrue_Selected": {
+			selection: strategySelection{family: toPtr(true)},
+			expected:  true,
+		},
+		"WithFamilyFalseOverAllTrue_NotSelected": {
+			selection: strategySelection{all: toPtr(true), family: toPtr(false)},
+			expected:  false,
+		},
+		"WithFamilyTrueOverAllFalse_Selected": {
+			selection: strategySelection{all: toPtr(false), family: toPtr(true)},
+			expected:  true,
+		},
+		"WithStrategyTrue_Selected": {
+			selection: strategySelection{strategy: toPtr(true)},
+			expected:  true,
+		},
+		"WithStrategyFalse_NotSelected": {
+			selection: strategySelection{strategy: toPtr(false)},
+			expected:  false,
+		},
+		"WithStrategyFalseOverAllTrue_NotSelected": {
+			selection: strategySelection{all: toPtr(true), strategy: toPtr(false)},
+			expected:  false,
+		},
+		"WithStrategyTrueOverAllFalse_Selected": {
+			selection: strategySelection{all: toPtr(false), strategy: toPtr(true)},
+			expected:  true,
+		},
+		"WithStrategyFalseOverFamilyTrue_NotSelected": {
+			selection: strategySelection{family: toPtr(true), strategy: toPtr(false)},
+			expected:  false,
+		},
+		"WithStrategyTrueOverFamilyFalse_Selected": {
+			selection: strategySelection{family: toPtr(false), strategy: toPtr(true)},
+			expected:  true,
+		},
+		"WithStrategyFalseOverFamilyAndAllTrue_NotSelected": {
+			selection: strategySelection{all: toPtr(true), family: toPtr(true), strategy: toPtr(false)},
+			expected:  false,
+		},
+		"WithStrategyTrueOverFamilyAndAllFalse_Selected": {
+			selection: strategySelection{all: toPtr(false), family: toPtr(false), strategy: toPtr(true)},
+			expected:  true,
+		},
+	}
+
+	sources := map[string]func(t *testing.T, g Gomega, sel strategySelection, s vcrcleaner.Strategy) *CleaningOptions{
+		"Flags": func(t *testing.T, g Gomega, sel strategySelection, s vcrcleaner.Strategy) *CleaningOptions {
+			t.Helper()
+
+			return parseCheck(t, g, sel.flags(s)...)
+		},
+		"Config": func(t *testing.T, g Gomega, sel strategySelection, s vcrcleaner.Strategy) *CleaningOptions {
+			t.Helper()
+
+			config, err := LoadProjectConfig(writeProjectConfig(t, g, t.TempDir(), sel.config(s)))
+			g.Expect(err).ToNot(HaveOccurred())
+
+			return &config.Clean
+		},
+	}
+
+	for _, strategy := range vcrcleaner.Strategies() {
+		for name, c := range cases {
+			if strategy.Family == vcrcleaner.FamilyGeneric && c.selection.family != nil {
+				// The generic family can't be selected as a whole
+				continue
+			}
+
+			for source, load := range sources {
+				t.Run(strategy.Name+"/"+name+"/"+source, func(t *testing.T) {
+					t.Parallel()
+					g := NewWithT(t)
+
+					opt := load(t, g, c.selection, strategy)
+
+					g.Expect(opt.ShouldClean(strategy)).To(Equal(c.expected))
+
+					// Other strategies are affected only by the selection of all strategies, or of their family
+					for _, other := range vcrcleaner.Strategies() {
+						g.Expect(opt.ShouldClean(other)).To(Equal(c.selection.selectedFor(strategy, other)), other.Name)
+					}
+				})
+			}
+		}
+	}
+}
+
+// CleaningOptions.Override Tests
+
+func TestCleaningOptions_Override(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		flags         CleaningOptions
+		config        CleaningOptions
+		deletes       bool
+		azureDeletion bool
+	}{
+		"WithNothingSet_CleansNothing": {},
+		"WithOnlyConfigSet_UsesConfig": {
+			config:  CleaningOptions{strategies: selecting(true, vcrcleaner.StrategyDeletion)},
+			deletes: true,
+		},
+		"WithFlagDisablingConfig_UsesFlag": {
+			flags:   CleaningOptions{strategies: selecting(false, vcrcleaner.StrategyDeletion)},
+			config:  CleaningOptions{strategies: selecting(true, vcrcleaner.StrategyDeletion)},
+			deletes: false,
+		},
+		"WithFlagAllOverridingSpecificConfig_UsesFlag": {
+			flags:         CleaningOptions{All: toPtr(true)},
+			config:        CleaningOptions{strategies: selecting(false, vcrcleaner.StrategyDeletion)},
+			deletes:       true,
+			azureDeletion: true,
+		},
+		"WithSpecificFlagAndConfigAll_UsesBoth": {
+			flags:         CleaningOptions{strategies: selecting(false, vcrcleaner.StrategyDeletion)},
+			config:        CleaningOptions{All: toPtr(true)},
+			deletes:       false,
+			azureDeletion: true,
+		},
+		"WithAzureFlagAndConfigAll_UsesFlagForAzure": {
+			flags: CleaningOptions{
+				families: selecting(false, vcrcleaner.FamilyAzure),
+			},
+			config:        CleaningOptions{All: toPtr(true)},
+			deletes:       true,
+			azureDeletion: false,
 		},
 	}
 
@@ -141,13 +402,94 @@ func TestCleaningOptions_ShouldCleanDeferredCreations(t *testing.T) {
 			t.Parallel()
 			g := NewWithT(t)
 
-			opt := &CleaningOptions{
-				DeferredCreations: c.deferredCreations,
-			}
+			result := c.flags.Override(&c.config)
+
+			g.Expect(shouldClean(result, vcrcleaner.StrategyDeletion)).To(Equal(c.deletes))
+			g.Expect(shouldClean(result, vcrcleaner.StrategyAzureResourceDeletion)).To(Equal(c.azureDeletion))
+		})
+	}
+}
+
+// CleaningOptions.RequiredOptions Tests
+
+func TestCleaningOptions_RequiredOptions_WithOnlyRules_ReturnsRulesOption(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	err := os.WriteFile(path, []byte(`
+rules:
+  - name: widget
+    trigger:
+      methods: [PUT]
+    poll:
+      field: status
+      waiting: [Pending]
+      done: [Ready]
+`), 0o600)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	opt := &CleaningOptions{
+		Rules: path,
+	}
+
+	options, err := opt.RequiredOptions(vcrcleaner.LoadPollingRules)
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(options).To(HaveLen(1))
+}
+
+func TestCleaningOptions_RequiredOptions_WithInvalidRules_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	err := os.WriteFile(path, []byte("rules:\n  - name: widget\n"), 0o600)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	opt := &CleaningOptions{
+		Rules: path,
+	}
+
+	_, err = opt.RequiredOptions(vcrcleaner.LoadPollingRules)
+
+	g.Expect(err).To(MatchError(ContainSubstring("polling rules")))
+}
+
+func TestCleaningOptions_ParsedRetainStrategiesFlag_AppliesPolicyToStrategy(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args     []string
+		retain   map[string]string
+		modified bool
+	}{
+		"WithoutFlag_CollapsesPolls": {
+			modified: true,
+		},
+		"RetainingFirstTwoAndLastTwoForDeletion_KeepsPolls": {
+			args:     []string{"--clean-retain-strategies", "deletion=first:2,last:2"},
+			retain:   map[string]string{vcrcleaner.StrategyDeletion: "first:2,last:2"},
+			modified: false,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			opt := parseCheck(t, g, append([]string{"--clean-deletes"}, c.args...)...)
+			g.Expect(opt.Retain.Strategies).To(Equal(c.retain))
+
+			options, err := opt.RequiredOptions(vcrcleaner.LoadPollingRules)
+			g.Expect(err).ToNot(HaveOccurred())
 
-			result := opt.ShouldCleanDeferredCreations()
+			cassettePath := copyTestData(t, g, "deletion.yaml", t.TempDir(), "deletion.yaml")
 
-			g.Expect(result).To(Equal(c.expected))
+			modified, err := vcrcleaner.New(slogt.New(t), options...).CleanFile(t.Context(), cassettePath)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(modified).To(Equal(c.modified))
 		})
 	}
 }
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/phsym/console-slog"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
+
+// CLI is the root of the command line interface, holding flags shared by all commands.
+type CLI struct {
+	Verbose bool `help:"Enable verbose logging."`
+	Debug   bool `help:"Enable debug logging."`
+
+	Clean   CleanCommand   `cmd:"" help:"Clean go-vcr cassette files, removing redundant interactions."`
+	Check   CheckCommand   `cmd:"" help:"Check go-vcr cassette files are already clean, failing if any could be reduced."`
+	Convert ConvertCommand `cmd:"" help:"Convert recordings between go-vcr cassettes and HTTP archives (HAR files)."`
+	Verify  VerifyCommand  `cmd:"" help:"Verify a cleaned recording by replaying the requests of the original against it."`
+
+	ListStrategies ListStrategiesCommand `cmd:"" help:"List the available cleaning strategies and their flags."`
+}
+
+// NewCLI creates the command line interface, with flags selecting each registered cleaning strategy.
+func NewCLI() *CLI {
+	cli := &CLI{}
+	cli.Clean.Clean.AddStrategyFlags()
+	cli.Check.Clean.AddStrategyFlags()
+
+	return cli
+}
+
+// CreateLogger builds a slog logger configured from the CLI flags.
+func (c *CLI) CreateLogger() *slog.Logger {
+	level := slog.LevelInfo
+	if c.Debug {
+		level = slog.LevelDebug
+	} else if c.Verbose {
+		level = vcrcleaner.LevelVerbose
+	}
+
+	opts := &console.HandlerOptions{
+		Level: level,
+	}
+	handler := console.NewHandler(os.Stderr, opts)
+
+	return slog.New(handler)
+}
@@ -1,11 +1,204 @@
 package cmd
 
-import "log/slog"
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/report"
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
 
 type Context struct {
-	Verbose       bool         // Use verbose logging
-	Debug         bool         // Use debug logging
-	Log           *slog.Logger // Logger to use
-	FilesScanned  int          // Number of files scanned
-	FilesModified int          // Number of files modified
+	Verbose       bool                                // Use verbose logging
+	Debug         bool                                // Use debug logging
+	Log           *slog.Logger                        // Logger to use
+	FilesScanned  int                                 // Number of files scanned
+	FilesModified int                                 // Number of files modified
+	Report        *report.CleaningReport              // Report of changes made, if one was requested
+	Config        *ProjectConfig                      // Project configuration, if one was found
+	Out           io.Writer                           // Destination for command output, such as listings; stdout if nil
+	rules         map[string]*vcrcleaner.PollingRules // Polling rules already loaded, keyed by path
+	stop          context.Context                     // Done once no further files should be started; nil if never
+	abandon       context.Context                     // Done once files in progress should be abandoned; nil if never
+	padlock       sync.Mutex                          // Used to make concurrent updates safe
+}
+
+// HandleInterrupts handles interrupts (Ctrl-C) while a command runs.
+// The first interrupt stops any further files being started, while files in progress are finished and saved; a
+// second abandons the files in progress too, leaving them untouched. Files are always replaced atomically, so no
+// file is ever left half written.
+// Returns a function restoring the default handling, to be called once the command completes.
+func (c *Context) HandleInterrupts() func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+
+	release := c.watchInterrupts(signals)
+
+	return func() {
+		signal.Stop(signals)
+		release()
+	}
+}
+
+// watchInterrupts stops starting further files on the first signal received, and abandons files in progress on the
+// second. Returns a function to stop watching.
+func (c *Context) watchInterrupts(signals <-chan os.Signal) func() {
+	stop, stopped := context.WithCancel(context.Background())
+	abandon, abandoned := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	c.stop = stop
+	c.abandon = abandon
+
+	go func() {
+		select {
+		case <-signals:
+		case <-done:
+			return
+		}
+
+		c.Log.Warn("Interrupted, finishing files in progress; interrupt again to abandon them")
+		stopped()
+
+		select {
+		case <-signals:
+		case <-done:
+			return
+		}
+
+		c.Log.Warn("Interrupted again, abandoning files in progress")
+		abandoned()
+	}()
+
+	return func() {
+		close(done)
+		stopped()
+		abandoned()
+	}
+}
+
+// stopping returns true if no further files should be started, because the command has been interrupted.
+func (c *Context) stopping() bool {
+	return c.stop != nil && c.stop.Err() != nil
+}
+
+// fileContext returns the context for processing a single file, cancelled if files in progress are abandoned or, if
+// timeout is positive, once it has elapsed.
+func (c *Context) fileContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	parent := c.abandon
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	if timeout > 0 {
+		return context.WithTimeout(parent, timeout)
+	}
+
+	return context.WithCancel(parent)
+}
+
+// output returns the destination for command output.
+func (c *Context) output() io.Writer {
+	if c.Out != nil {
+		return c.Out
+	}
+
+	return os.Stdout
+}
+
+// fileScanned records that another file has been scanned.
+func (c *Context) fileScanned() {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	c.FilesScanned++
+}
+
+// fileModified records that another file has been modified.
+func (c *Context) fileModified() {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	c.FilesModified++
+}
+
+// summarize adds the summary of a cassette to the report, if one was requested.
+func (c *Context) summarize(summary report.CassetteSummary) {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	if c.Report != nil {
+		c.Report.Add(summary)
+	}
+}
+
+// DiscoverProjectConfig searches for a project configuration file in the specified directory and its parents,
+// loading the first one found.
+func (c *Context) DiscoverProjectConfig(dir string) error {
+	path, err := FindProjectConfig(dir)
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		// No configuration; only flags will be used
+		return nil
+	}
+
+	config, err := LoadProjectConfig(path)
+	if err != nil {
+		return err
+	}
+
+	c.Log.Log(context.Background(), vcrcleaner.LevelVerbose, "Using project configuration", "path", path)
+	c.Config = config
+
+	return nil
+}
+
+// cleaningOptionsFor returns the vcrcleaner options to use for the cassette at the specified path.
+// Flags take precedence over the project configuration, if any.
+func (c *Context) cleaningOptionsFor(path string, flags *CleaningOptions) ([]vcrcleaner.Option, error) {
+	selected := flags
+	if c.Config != nil {
+		configured, err := c.Config.OptionsFor(path)
+		if err != nil {
+			return nil, eris.Wrap(err, "applying project configuration")
+		}
+
+		selected = flags.Override(configured)
+	}
+
+	return selected.RequiredOptions(c.pollingRules)
+}
+
+// pollingRules returns the polling rules in the file at the specified path, loading each file only once per command
+// however many cassettes use it.
+func (c *Context) pollingRules(path string) (*vcrcleaner.PollingRules, error) {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	if rules, ok := c.rules[path]; ok {
+		return rules, nil
+	}
+
+	rules, err := vcrcleaner.LoadPollingRules(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.rules == nil {
+		c.rules = make(map[string]*vcrcleaner.PollingRules)
+	}
+
+	c.rules[path] = rules
+
+	return rules, nil
 }
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+)
+
+func TestWatchInterrupts_NoInterrupt_ContinuesWork(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	signals := make(chan os.Signal, 1)
+	release := ctx.watchInterrupts(signals)
+	defer release()
+
+	fileCtx, cancel := ctx.fileContext(0)
+	defer cancel()
+
+	g.Expect(ctx.stopping()).To(BeFalse())
+	g.Expect(fileCtx.Err()).ToNot(HaveOccurred())
+}
+
+func TestWatchInterrupts_FirstInterrupt_StopsStartingFilesButFinishesThoseInProgress(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	signals := make(chan os.Signal, 1)
+	release := ctx.watchInterrupts(signals)
+	defer release()
+
+	fileCtx, cancel := ctx.fileContext(0)
+	defer cancel()
+
+	signals <- os.Interrupt
+
+	g.Eventually(ctx.stopping).Should(BeTrue())
+	g.Consistently(fileCtx.Done(), 50*time.Millisecond).ShouldNot(BeClosed())
+}
+
+func TestWatchInterrupts_SecondInterrupt_AbandonsFilesInProgress(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	signals := make(chan os.Signal, 1)
+	release := ctx.watchInterrupts(signals)
+	defer release()
+
+	fileCtx, cancel := ctx.fileContext(0)
+	defer cancel()
+
+	signals <- os.Interrupt
+
+	g.Eventually(ctx.stopping).Should(BeTrue())
+
+	signals <- os.Interrupt
+
+	g.Eventually(fileCtx.Done()).Should(BeClosed())
+	g.Expect(fileCtx.Err()).To(MatchError(context.Canceled))
+}
+
+func TestFileContext_WithTimeout_HasDeadline(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	fileCtx, cancel := ctx.fileContext(time.Minute)
+	defer cancel()
+
+	deadline, ok := fileCtx.Deadline()
+	g.Expect(ok).To(BeTrue())
+	g.Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+}
+
+func TestFileContext_WithoutTimeout_HasNoDeadline(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	fileCtx, cancel := ctx.fileContext(0)
+	defer cancel()
+
+	_, ok := fileCtx.Deadline()
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestCleaningOptionsFor_SeveralCassettesSharingRules_LoadsRulesOnce(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	err := os.WriteFile(path, []byte(`
+rules:
+  - name: widget
+    trigger:
+      methods: [PUT]
+    poll:
+      field: status
+      waiting: [Pending]
+      done: [Ready]
+`), 0o600)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	flags := &CleaningOptions{
+		Rules: path,
+	}
+
+	_, err = ctx.cleaningOptionsFor("first.yaml", flags)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// With the rules file gone, the second cassette can only succeed using the rules already loaded
+	g.Expect(os.Remove(path)).To(Succeed())
+
+	options, err := ctx.cleaningOptionsFor("second.yaml", flags)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(options).To(HaveLen(1))
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/rotisserie/eris"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/cassettefile"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/har"
+)
+
+// ConvertCommand translates recordings between go-vcr cassettes and HTTP Archives (HAR files).
+// The format of each file is determined by its extension; anything other than .har is treated as a cassette.
+//
+//nolint:revive // Struct tags are clearer kept on a single line
+type ConvertCommand struct {
+	Source      string `arg:"" help:"Recording to convert, a go-vcr cassette or .har file."      type:"existingfile"`
+	Destination string `arg:"" help:"Path to write the converted recording, with its extension." type:"path"`
+}
+
+// Run converts the source recording, writing it to the destination.
+func (c *ConvertCommand) Run(ctx *Context) error {
+	if har.IsHAR(c.Source) && har.IsHAR(c.Destination) {
+		return eris.Errorf("%s and %s are both HTTP archives, nothing to convert", c.Source, c.Destination)
+	}
+
+	cas, err := c.loadSource()
+	if err != nil {
+		return err
+	}
+
+	err = c.saveDestination(cas)
+	if err != nil {
+		return err
+	}
+
+	ctx.Log.Info(
+		"Converted recording",
+		"source", c.Source,
+		"destination", c.Destination,
+		"interactions", len(cas.Interactions),
+	)
+
+	return nil
+}
+
+// loadSource loads the source recording as a cassette, converting it from an HTTP archive if needed.
+func (c *ConvertCommand) loadSource() (*cassette.Cassette, error) {
+	if har.IsHAR(c.Source) {
+		archive, err := har.Load(c.Source)
+		if err != nil {
+			return nil, eris.Wrap(err, "loading source HTTP archive")
+		}
+
+		return archive.ToCassette(strings.TrimSuffix(c.Source, filepath.Ext(c.Source))), nil
+	}
+
+	file, err := cassettefile.Load(c.Source)
+	if err != nil {
+		return nil, eris.Wrap(err, "loading source cassette")
+	}
+
+	return file.Cassette, nil
+}
+
+// saveDestination writes the cassette to the destination, as an HTTP archive or a cassette as indicated by the
+// extension. Cassettes are written in the encoding implied by the extension (e.g. .json.gz), defaulting to YAML.
+func (c *ConvertCommand) saveDestination(cas *cassette.Cassette) error {
+	if har.IsHAR(c.Destination) {
+		err := har.FromCassette(cas).Save(c.Destination)
+		if err != nil {
+			return eris.Wrap(err, "saving destination HTTP archive")
+		}
+
+		return nil
+	}
+
+	file := &cassettefile.File{
+		Cassette: cas,
+	}
+
+	err := file.SaveAs(c.Destination, cassettefile.Detect(c.Destination, nil))
+	if err != nil {
+		return eris.Wrap(err, "saving destination cassette")
+	}
+
+	return nil
+}
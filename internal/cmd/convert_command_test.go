@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/cassettefile"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/har"
+)
+
+func TestConvertRun_FromCassetteToHARAndBack_PreservesInteractions(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+	cassettePath := copyTestData(t, g, "deletion.yaml", tmpDir, "deletion.yaml")
+	harPath := filepath.Join(tmpDir, "deletion.har")
+	roundTripPath := filepath.Join(tmpDir, "roundtrip.json.gz")
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	toHAR := &ConvertCommand{Source: cassettePath, Destination: harPath}
+	g.Expect(toHAR.Run(ctx)).To(Succeed())
+
+	archive, err := har.Load(harPath)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	toCassette := &ConvertCommand{Source: harPath, Destination: roundTripPath}
+	g.Expect(toCassette.Run(ctx)).To(Succeed())
+
+	original, err := cassettefile.Load(cassettePath)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	converted, err := cassettefile.Load(roundTripPath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(converted.Encoding).To(Equal(cassettefile.Encoding{Format: cassettefile.JSON, Compressed: true}))
+	g.Expect(archive.Log.Entries).To(HaveLen(len(original.Cassette.Interactions)))
+	g.Expect(converted.Cassette.Interactions).To(HaveLen(len(original.Cassette.Interactions)))
+
+	for index, i := range converted.Cassette.Interactions {
+		expected := original.Cassette.Interactions[index]
+		g.Expect(i.ID).To(Equal(expected.ID))
+		g.Expect(i.Request.Method).To(Equal(expected.Request.Method))
+		g.Expect(i.Request.URL).To(Equal(expected.Request.URL))
+		g.Expect(i.Response.Code).To(Equal(expected.Response.Code))
+		g.Expect(i.Response.Body).To(Equal(expected.Response.Body))
+		g.Expect(i.Response.Duration).To(Equal(expected.Response.Duration))
+	}
+}
+
+func TestConvertRun_BetweenHARFiles_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+	c := &ConvertCommand{
+		Source:      filepath.Join(tmpDir, "source.har"),
+		Destination: filepath.Join(tmpDir, "destination.har"),
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	err := c.Run(ctx)
+
+	g.Expect(err).To(MatchError(ContainSubstring("nothing to convert")))
+}
+
+func TestConvertRun_FromHARWithInvalidURL_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+
+	archive := har.New()
+	archive.Log.Entries = []*har.Entry{
+		{Request: har.Request{Method: "GET", URL: "https://example.com/%zz"}},
+	}
+
+	source := filepath.Join(tmpDir, "source.har")
+	g.Expect(archive.Save(source)).To(Succeed())
+
+	c := &ConvertCommand{
+		Source:      source,
+		Destination: filepath.Join(tmpDir, "destination.yaml"),
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	err := c.Run(ctx)
+
+	g.Expect(err).To(MatchError(ContainSubstring("entry 0 has an invalid request URL")))
+}
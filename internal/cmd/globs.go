@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// findCassettes returns the paths of any files identified by the given glob path, logging what was found.
+func findCassettes(ctx *Context, glob string) ([]string, error) {
+	paths, err := filepath.Glob(glob)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to glob path")
+	}
+
+	// Early exit for no matches
+	if len(paths) == 0 {
+		ctx.Log.Info("No cassettes found", "filespec", glob)
+
+		return nil, nil
+	}
+
+	// Log the number of matching files found, for globs only
+	if isGlob(glob) {
+		ctx.Log.Info(
+			"Found multiple cassettes",
+			"count", len(paths),
+			"filespec", glob)
+	}
+
+	return paths, nil
+}
+
+// isGlob checks if the provided path contains any globbing characters.
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[]")
+}
+
+// matchGlob reports whether the slash separated path matches the glob pattern.
+// In addition to the syntax supported by path.Match, a ** segment matches zero or more directories.
+func matchGlob(pattern string, name string) (bool, error) {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments reports whether the segments of a path match the segments of a glob pattern.
+func matchSegments(pattern []string, name []string) (bool, error) {
+	if len(pattern) == 0 {
+		return len(name) == 0, nil
+	}
+
+	if pattern[0] == "**" {
+		// Try consuming successively more segments of the name
+		for skip := 0; skip <= len(name); skip++ {
+			match, err := matchSegments(pattern[1:], name[skip:])
+			if err != nil || match {
+				return match, err
+			}
+		}
+
+		return false, nil
+	}
+
+	if len(name) == 0 {
+		return false, nil
+	}
+
+	match, err := path.Match(pattern[0], name[0])
+	if err != nil {
+		return false, eris.Wrap(err, "invalid glob")
+	}
+
+	if !match {
+		return false, nil
+	}
+
+	return matchSegments(pattern[1:], name[1:])
+}
+
+// validateGlob checks that every segment of the slash separated glob pattern is well formed.
+func validateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		_, err := path.Match(segment, "")
+		if err != nil {
+			return eris.Wrapf(err, "invalid glob segment %q", segment)
+		}
+	}
+
+	return nil
+}
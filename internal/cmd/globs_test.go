@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMatchGlob(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		"ExactPath_Matches": {
+			pattern:  "testdata/azure/cassette.yaml",
+			name:     "testdata/azure/cassette.yaml",
+			expected: true,
+		},
+		"WildcardInFileName_Matches": {
+			pattern:  "testdata/azure/*.yaml",
+			name:     "testdata/azure/cassette.yaml",
+			expected: true,
+		},
+		"WildcardInFileName_DoesNotMatchSubdirectory": {
+			pattern:  "testdata/azure/*.yaml",
+			name:     "testdata/azure/storage/cassette.yaml",
+			expected: false,
+		},
+		"DoubleStar_MatchesImmediateChild": {
+			pattern:  "testdata/azure/**",
+			name:     "testdata/azure/cassette.yaml",
+			expected: true,
+		},
+		"DoubleStar_MatchesNestedChild": {
+			pattern:  "testdata/azure/**",
+			name:     "testdata/azure/storage/accounts/cassette.yaml",
+			expected: true,
+		},
+		"DoubleStar_DoesNotMatchSibling": {
+			pattern:  "testdata/azure/**",
+			name:     "testdata/aws/cassette.yaml",
+			expected: false,
+		},
+		"DoubleStarInMiddle_MatchesZeroDirectories": {
+			pattern:  "testdata/**/*.yaml",
+			name:     "testdata/cassette.yaml",
+			expected: true,
+		},
+		"DoubleStarInMiddle_MatchesManyDirectories": {
+			pattern:  "testdata/**/*.yaml",
+			name:     "testdata/azure/storage/cassette.yaml",
+			expected: true,
+		},
+		"DoubleStarInMiddle_ChecksExtension": {
+			pattern:  "testdata/**/*.yaml",
+			name:     "testdata/azure/storage/cassette.json",
+			expected: false,
+		},
+		"ShorterPath_DoesNotMatch": {
+			pattern:  "testdata/azure/*.yaml",
+			name:     "testdata/azure",
+			expected: false,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			match, err := matchGlob(c.pattern, c.name)
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(match).To(Equal(c.expected))
+		})
+	}
+}
+
+func TestValidateGlob_WithMalformedSegment_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	err := validateGlob("testdata/**/[azure/*.yaml")
+
+	g.Expect(err).To(MatchError(ContainSubstring("invalid glob segment")))
+}
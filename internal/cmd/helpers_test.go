@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
 )
 
 // toPtr returns a pointer to the given value.
@@ -13,11 +20,49 @@ func toPtr[T any](v T) *T {
 	return &v
 }
 
+// selecting returns a selection of the named strategies.
+func selecting(selected bool, names ...string) map[string]*bool {
+	result := make(map[string]*bool, len(names))
+	for _, name := range names {
+		result[name] = toPtr(selected)
+	}
+
+	return result
+}
+
+// shouldClean indicates whether the options select the named strategy.
+func shouldClean(opt *CleaningOptions, name string) bool {
+	for _, s := range vcrcleaner.Strategies() {
+		if s.Name == name {
+			return opt.ShouldClean(s)
+		}
+	}
+
+	return false
+}
+
+// cancelled returns a context that has already been cancelled.
+func cancelled(t *testing.T) context.Context {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	return ctx
+}
+
 // createTestRecording creates a sample cassette file in the given directory.
 func createTestRecording(t *testing.T, g Gomega, tmpDir, filename string) string {
 	t.Helper()
 
-	content, err := os.ReadFile(filepath.Join("testdata", "sample.yaml"))
+	return copyTestData(t, g, "sample.yaml", tmpDir, filename)
+}
+
+// copyTestData copies the named file from testdata into the given directory, returning the path of the copy.
+func copyTestData(t *testing.T, g Gomega, source, tmpDir, filename string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", source))
 	g.Expect(err).ToNot(HaveOccurred())
 
 	cassettePath := filepath.Join(tmpDir, filename)
@@ -26,3 +71,47 @@ func createTestRecording(t *testing.T, g Gomega, tmpDir, filename string) string
 
 	return cassettePath
 }
+
+// captureCleaningLog cleans a directory of sample recordings using the specified number of jobs, returning the log
+// output with timestamps and the directory name removed.
+func captureCleaningLog(t *testing.T, g Gomega, jobs int) string {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+
+	for i := range 8 {
+		copyTestData(t, g, "deletion.yaml", tmpDir, "deletion"+strconv.Itoa(i+1)+".yaml")
+	}
+
+	var buffer bytes.Buffer
+
+	handler := slog.NewTextHandler(
+		&buffer,
+		&slog.HandlerOptions{
+			Level: slog.LevelDebug,
+			ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+
+				return a
+			},
+		})
+
+	c := &CleanCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+		Globs: []string{filepath.Join(tmpDir, "*.yaml")},
+		Jobs:  jobs,
+	}
+
+	ctx := &Context{
+		Log: slog.New(handler),
+	}
+
+	err := c.Run(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	return strings.ReplaceAll(buffer.String(), tmpDir, "<dir>")
+}
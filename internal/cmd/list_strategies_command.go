@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
+
+// ListStrategiesCommand lists the registered cleaning strategies, along with the flags selecting them.
+type ListStrategiesCommand struct{}
+
+// Run writes a table of the registered strategies, in the order they're applied.
+func (*ListStrategiesCommand) Run(ctx *Context) error {
+	table := tabwriter.NewWriter(ctx.output(), 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(table, "STRATEGY\tFAMILY\tFLAG\tDESCRIPTION")
+
+	for _, s := range vcrcleaner.Strategies() {
+		fmt.Fprintf(table, "%s\t%s\t--clean-%s\t%s\n", s.Name, s.Family, flagName(s), s.Description)
+	}
+
+	if err := table.Flush(); err != nil {
+		return eris.Wrap(err, "writing strategies")
+	}
+
+	return nil
+}
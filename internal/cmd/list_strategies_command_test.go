@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
+
+func TestListStrategiesRun_ListsEachStrategyWithItsFlag(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	var output bytes.Buffer
+
+	ctx := &Context{
+		Log: slogt.New(t),
+		Out: &output,
+	}
+
+	c := &ListStrategiesCommand{}
+	err := c.Run(ctx)
+
+	g.Expect(err).ToNot(HaveOccurred())
+
+	for _, s := range vcrcleaner.Strategies() {
+		g.Expect(output.String()).To(MatchRegexp(`(?m)^%s\s+%s\s+--clean-%s\s+`, s.Name, s.Family, flagName(s)))
+	}
+}
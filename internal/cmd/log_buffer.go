@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// logBuffer captures log records so they can be replayed later. This allows the output of work done concurrently to
+// be written in a deterministic order.
+type logBuffer struct {
+	entries []logEntry
+	padlock sync.Mutex
+}
+
+// logEntry is a single captured log record, along with the handler that should eventually write it.
+type logEntry struct {
+	handler slog.Handler
+	record  slog.Record
+}
+
+// bufferingHandler is a slog.Handler that captures records into a logBuffer instead of writing them.
+type bufferingHandler struct {
+	inner  slog.Handler
+	buffer *logBuffer
+}
+
+var _ slog.Handler = &bufferingHandler{}
+
+// newBufferedLogger returns a logger that captures records into a new buffer, along with that buffer.
+// inner is the handler used to decide which records are enabled, and to write them when the buffer is replayed.
+func newBufferedLogger(inner slog.Handler) (*slog.Logger, *logBuffer) {
+	buffer := &logBuffer{}
+	handler := &bufferingHandler{
+		inner:  inner,
+		buffer: buffer,
+	}
+
+	return slog.New(handler), buffer
+}
+
+// Replay writes all captured records, in the order they were captured, and empties the buffer.
+func (b *logBuffer) Replay(ctx context.Context) {
+	b.padlock.Lock()
+	defer b.padlock.Unlock()
+
+	for _, e := range b.entries {
+		// Errors are ignored, consistent with slog.Logger
+		_ = e.handler.Handle(ctx, e.record)
+	}
+
+	b.entries = nil
+}
+
+// Enabled reports whether the inner handler handles records at the given level.
+func (h *bufferingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle captures the record for later replay.
+func (h *bufferingHandler) Handle(_ context.Context, record slog.Record) error {
+	h.buffer.padlock.Lock()
+	defer h.buffer.padlock.Unlock()
+
+	h.buffer.entries = append(
+		h.buffer.entries,
+		logEntry{
+			handler: h.inner,
+			record:  record.Clone(),
+		})
+
+	return nil
+}
+
+// WithAttrs returns a handler capturing into the same buffer, with the given attributes added.
+func (h *bufferingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &bufferingHandler{
+		inner:  h.inner.WithAttrs(attrs),
+		buffer: h.buffer,
+	}
+}
+
+// WithGroup returns a handler capturing into the same buffer, with the given group added.
+func (h *bufferingHandler) WithGroup(name string) slog.Handler {
+	return &bufferingHandler{
+		inner:  h.inner.WithGroup(name),
+		buffer: h.buffer,
+	}
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBufferedLogger_BeforeReplay_WritesNothing(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	var output bytes.Buffer
+
+	log, _ := newBufferedLogger(slog.NewTextHandler(&output, nil))
+	log.Info("Captured")
+
+	g.Expect(output.String()).To(BeEmpty())
+}
+
+func TestBufferedLogger_Replay_WritesRecordsInOrder(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	var output bytes.Buffer
+
+	log, buffer := newBufferedLogger(slog.NewTextHandler(&output, nil))
+	log.Info("First")
+	log.With("path", "cassette.yaml").Info("Second")
+	log.WithGroup("group").Info("Third", "key", "value")
+	log.Debug("Disabled")
+
+	buffer.Replay(context.Background())
+
+	g.Expect(output.String()).To(MatchRegexp(
+		`(?s)msg=First.*msg=Second path=cassette.yaml.*msg=Third group.key=value\n$`))
+	g.Expect(output.String()).ToNot(ContainSubstring("Disabled"))
+}
+
+func TestBufferedLogger_ReplayTwice_WritesRecordsOnce(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	var output bytes.Buffer
+
+	log, buffer := newBufferedLogger(slog.NewTextHandler(&output, nil))
+	log.Info("Only once")
+
+	buffer.Replay(context.Background())
+	buffer.Replay(context.Background())
+
+	g.Expect(bytes.Count(output.Bytes(), []byte("Only once"))).To(Equal(1))
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/rotisserie/eris"
+	"go.yaml.in/yaml/v3"
+)
+
+// ProjectConfigFileName is the name of the project configuration file, discovered by searching upwards from the
+// working directory.
+const ProjectConfigFileName = ".go-vcr-tidy.yaml"
+
+// ProjectConfig captures project-wide cleaning options, avoiding the need to repeat flags on every invocation.
+type ProjectConfig struct {
+	Clean     CleaningOptions  `yaml:"clean"`     // Cleaning options applying to all cassettes
+	Overrides []ConfigOverride `yaml:"overrides"` // Cleaning options applying to specific cassettes
+
+	path string // Path of the file the configuration was loaded from
+}
+
+// ConfigOverride captures cleaning options applying only to cassettes matching a glob.
+type ConfigOverride struct {
+	// Glob identifies the cassettes to which the override applies, relative to the directory containing the
+	// configuration file. Use ** to match any number of directories.
+	Glob  string          `yaml:"glob"`
+	Clean CleaningOptions `yaml:"clean"` // Cleaning options to apply
+}
+
+// FindProjectConfig searches for a project configuration file in the specified directory and its parents, returning
+// the path of the first found, or an empty string if there is none.
+func FindProjectConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", eris.Wrapf(err, "resolving directory %s", dir)
+	}
+
+	for {
+		path := filepath.Join(dir, ProjectConfigFileName)
+
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", eris.Wrapf(err, "checking for project configuration at %s", path)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			// Reached the root of the filesystem
+			return "", nil
+		}
+
+		dir = parent
+	}
+}
+
+// LoadProjectConfig loads the project configuration file at the specified path.
+// Unknown keys are reported as errors, so that typos don't silently disable cleaning.
+func LoadProjectConfig(path string) (*ProjectConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, eris.Wrapf(err, "reading project configuration %s", path)
+	}
+
+	var config ProjectConfig
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(&config)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, eris.Wrapf(err, "parsing project configuration %s", path)
+	}
+
+	for _, override := range config.Overrides {
+		if override.Glob == "" {
+			return nil, eris.Errorf("parsing project configuration %s: override is missing a glob", path)
+		}
+
+		err = validateGlob(override.Glob)
+		if err != nil {
+			return nil, eris.Wrapf(err, "parsing project configuration %s: invalid glob %q", path, override.Glob)
+		}
+	}
+
+	// Paths to rules files are relative to the configuration file
+	config.Clean.Rules = resolveRelativeTo(path, config.Clean.Rules)
+	for i := range config.Overrides {
+		config.Overrides[i].Clean.Rules = resolveRelativeTo(path, config.Overrides[i].Clean.Rules)
+	}
+
+	config.path = path
+
+	return &config, nil
+}
+
+// Path returns the path of the file the configuration was loaded from.
+func (c *ProjectConfig) Path() string {
+	return c.path
+}
+
+// OptionsFor returns the cleaning options configured for the cassette at the specified path.
+// Where multiple overrides match, later ones take precedence over earlier ones, and all take precedence over the
+// project-wide options.
+func (c *ProjectConfig) OptionsFor(path string) (*CleaningOptions, error) {
+	result := &c.Clean
+
+	rel, err := c.relativePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range c.Overrides {
+		override := &c.Overrides[i]
+
+		match, err := matchGlob(override.Glob, rel)
+		if err != nil {
+			return nil, eris.Wrapf(err, "matching %s against glob %q", path, override.Glob)
+		}
+
+		if match {
+			result = override.Clean.Override(result)
+		}
+	}
+
+	return result, nil
+}
+
+// relativePath returns the path of the cassette relative to the directory containing the configuration file, using
+// forward slashes as separators.
+func (c *ProjectConfig) relativePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", eris.Wrapf(err, "resolving path %s", path)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(c.path))
+	if err != nil {
+		return "", eris.Wrapf(err, "resolving directory of %s", c.path)
+	}
+
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil {
+		return "", eris.Wrapf(err, "finding path of %s relative to %s", path, dir)
+	}
+
+	return filepath.ToSlash(rel), nil
+}
+
+// resolveRelativeTo resolves a relative path found in the configuration file at configPath.
+// Empty and absolute paths are returned unchanged.
+func resolveRelativeTo(configPath string, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(filepath.Dir(configPath), path)
+}
+
+// checkKnownFields returns an error if the mapping node has any keys not matching the yaml tag of a field of the
+// struct type t, checking nested structs too. This mirrors the strict decoding of the configuration file for types
+// that decode themselves, as yaml doesn't pass the setting on.
+func checkKnownFields(node *yaml.Node, t reflect.Type) error {
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		key, value := node.Content[index], node.Content[index+1]
+
+		field, ok := fieldWithYAMLKey(t, key.Value)
+		if !ok {
+			return eris.Errorf("line %d: field %s not found", key.Line, key.Value)
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() == reflect.Struct && value.Kind == yaml.MappingNode {
+			if err := checkKnownFields(value, fieldType); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// fieldWithYAMLKey finds the field of the struct type t decoded from the specified yaml key.
+func fieldWithYAMLKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	for index := range t.NumField() {
+		field := t.Field(index)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == key && name != "-" {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
+
+// writeProjectConfig writes a project configuration file with the given content into the given directory.
+func writeProjectConfig(t *testing.T, g Gomega, dir, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, ProjectConfigFileName)
+	err := os.WriteFile(path, []byte(content), 0o600)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	return path
+}
+
+func TestFindProjectConfig_InParentDirectory_ReturnsPath(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	root := t.TempDir()
+	expected := writeProjectConfig(t, g, root, "clean:\n  deletes: true\n")
+
+	nested := filepath.Join(root, "testdata", "azure")
+	g.Expect(os.MkdirAll(nested, 0o750)).To(Succeed())
+
+	path, err := FindProjectConfig(nested)
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(path).To(Equal(expected))
+}
+
+func TestFindProjectConfig_WithNoConfig_ReturnsEmptyPath(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	// Assumes no configuration file exists in any parent of the temp directory
+	path, err := FindProjectConfig(t.TempDir())
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(path).To(BeEmpty())
+}
+
+func TestLoadProjectConfig_WithUnknownKey_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := writeProjectConfig(t, g, t.TempDir(), "clean:\n  delete: true\n")
+
+	_, err := LoadProjectConfig(path)
+
+	g.Expect(err).To(MatchError(ContainSubstring("parsing project configuration")))
+}
+
+func TestLoadProjectConfig_WithInvalidGlob_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := writeProjectConfig(t, g, t.TempDir(), `
+overrides:
+  - glob: "testdata/[azure/**"
+    clean:
+      deletes: true
+`)
+
+	_, err := LoadProjectConfig(path)
+
+	g.Expect(err).To(MatchError(ContainSubstring("invalid glob")))
+}
+
+func TestLoadProjectConfig_WithEmptyFile_SelectsNothing(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := writeProjectConfig(t, g, t.TempDir(), "")
+
+	config, err := LoadProjectConfig(path)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	options, err := config.OptionsFor(filepath.Join(t.TempDir(), "cassette.yaml"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(options.Options()).To(BeEmpty())
+}
+
+func TestProjectConfigOptionsFor_WithOverride_AppliesOnlyToMatchingCassettes(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	root := t.TempDir()
+	path := writeProjectConfig(t, g, root, `
+clean:
+  deletes: true
+overrides:
+  - glob: "testdata/azure/**"
+    clean:
+      azure:
+        all: true
+  - glob: "testdata/azure/legacy/*.yaml"
+    clean:
+      deletes: false
+`)
+
+	config, err := LoadProjectConfig(path)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cases := map[string]struct {
+		cassette      string
+		deletes       bool
+		azureDeletion bool
+	}{
+		"OutsideOverride": {
+			cassette:      "testdata/generic/cassette.yaml",
+			deletes:       true,
+			azureDeletion: false,
+		},
+		"InsideOverride": {
+			cassette:      "testdata/azure/storage/cassette.yaml",
+			deletes:       true,
+			azureDeletion: true,
+		},
+		"InsideNestedOverride": {
+			cassette:      "testdata/azure/legacy/cassette.yaml",
+			deletes:       false,
+			azureDeletion: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			options, err := config.OptionsFor(filepath.Join(root, filepath.FromSlash(c.cassette)))
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(shouldClean(options, vcrcleaner.StrategyDeletion)).To(Equal(c.deletes))
+			g.Expect(shouldClean(options, vcrcleaner.StrategyAzureResourceDeletion)).To(Equal(c.azureDeletion))
+		})
+	}
+}
+
+func TestCleanPath_WithProjectConfig_UsesConfiguredOptions(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	root := t.TempDir()
+	writeProjectConfig(t, g, root, "clean:\n  deletes: true\n")
+	cassettePath := copyTestData(t, g, "deletion.yaml", root, "deletion.yaml")
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	g.Expect(ctx.DiscoverProjectConfig(root)).To(Succeed())
+
+	// No flags set; cleaning options come from the project configuration
+	c := &CleanCommand{}
+
+	err := c.cleanFileWithLog(ctx, ctx.Log, cassettePath)
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ctx.FilesModified).To(Equal(1))
+}
+
+func TestCleanPath_WithFlagOverridingProjectConfig_UsesFlag(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	root := t.TempDir()
+	writeProjectConfig(t, g, root, "clean:\n  all: true\n")
+	cassettePath := copyTestData(t, g, "deletion.yaml", root, "deletion.yaml")
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	g.Expect(ctx.DiscoverProjectConfig(root)).To(Succeed())
+
+	c := &CleanCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(false, vcrcleaner.StrategyDeletion),
+		},
+	}
+
+	err := c.cleanFileWithLog(ctx, ctx.Log, cassettePath)
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ctx.FilesModified).To(Equal(0))
+}
+
+func TestLoadProjectConfig_WithRelativeRules_ResolvesAgainstConfigDirectory(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	root := t.TempDir()
+	path := writeProjectConfig(t, g, root, `
+clean:
+  rules: rules/polling.yaml
+overrides:
+  - glob: "testdata/**"
+    clean:
+      rules: other.yaml
+`)
+
+	config, err := LoadProjectConfig(path)
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(config.Clean.Rules).To(Equal(filepath.Join(root, "rules", "polling.yaml")))
+	g.Expect(config.Overrides[0].Clean.Rules).To(Equal(filepath.Join(root, "other.yaml")))
+}
+
+func TestLoadProjectConfig_WithCompressDurations_ParsesDuration(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := writeProjectConfig(t, g, t.TempDir(), "clean:\n  compressDurations: 100ms\n")
+
+	config, err := LoadProjectConfig(path)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(config.Clean.CompressDurations).To(HaveValue(Equal(100 * time.Millisecond)))
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
+
+//nolint:revive // Struct tags are clearer kept on a single line
+type RetentionOptions struct {
+	First      *int              `help:"Number of polls to retain at the start of each collapsed sequence (default 1)." placeholder:"N"               yaml:"first"`
+	Last       *int              `help:"Number of polls to retain at the end of each collapsed sequence (default 1)."   placeholder:"N"               yaml:"last"`
+	Every      *int              `help:"Also retain every Nth poll of each collapsed sequence."                         placeholder:"N"               yaml:"every"`
+	Strategies map[string]string `help:"Retention for specific strategies, e.g. deletion=first:2,last:2."               placeholder:"STRATEGY=POLICY" yaml:"strategies"`
+}
+
+// Options builds the vcrcleaner options based on the retention options.
+func (opt *RetentionOptions) Options() ([]vcrcleaner.Option, error) {
+	var result []vcrcleaner.Option
+
+	if opt.First != nil || opt.Last != nil || opt.Every != nil {
+		policy := vcrcleaner.DefaultRetentionPolicy()
+		if opt.First != nil {
+			policy.First = *opt.First
+		}
+
+		if opt.Last != nil {
+			policy.Last = *opt.Last
+		}
+
+		if opt.Every != nil {
+			policy.Every = *opt.Every
+		}
+
+		err := policy.Validate()
+		if err != nil {
+			return nil, eris.Wrap(err, "invalid retention")
+		}
+
+		result = append(result, vcrcleaner.WithRetention(policy))
+	}
+
+	// Sort for consistent behaviour
+	for _, strategy := range slices.Sorted(maps.Keys(opt.Strategies)) {
+		policy, err := vcrcleaner.ParseRetentionPolicy(opt.Strategies[strategy])
+		if err != nil {
+			return nil, eris.Wrapf(err, "invalid retention for strategy %s", strategy)
+		}
+
+		result = append(result, vcrcleaner.WithStrategyRetention(strategy, policy))
+	}
+
+	return result, nil
+}
+
+// override returns options where any values set by opt take precedence over those set by base.
+func (opt *RetentionOptions) override(base *RetentionOptions) RetentionOptions {
+	var strategies map[string]string
+	if len(opt.Strategies)+len(base.Strategies) > 0 {
+		strategies = maps.Clone(base.Strategies)
+		if strategies == nil {
+			strategies = make(map[string]string, len(opt.Strategies))
+		}
+
+		maps.Copy(strategies, opt.Strategies)
+	}
+
+	return RetentionOptions{
+		First:      firstSet(opt.First, base.First),
+		Last:       firstSet(opt.Last, base.Last),
+		Every:      firstSet(opt.Every, base.Every),
+		Strategies: strategies,
+	}
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
+
+func TestRetentionOptions_Options(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		options       RetentionOptions
+		expectedCount int
+		expectedError string
+	}{
+		"WithNothingSet_ReturnsNoOptions": {},
+		"WithFirstSet_ReturnsDefaultRetention": {
+			options:       RetentionOptions{First: toPtr(2)},
+			expectedCount: 1,
+		},
+		"WithAllSet_ReturnsDefaultRetention": {
+			options:       RetentionOptions{First: toPtr(2), Last: toPtr(2), Every: toPtr(5)},
+			expectedCount: 1,
+		},
+		"WithStrategies_ReturnsOptionForEach": {
+			options: RetentionOptions{
+				Strategies: map[string]string{
+					"deletion":          "first:2",
+					"deferred-creation": "last:3",
+				},
+			},
+			expectedCount: 2,
+		},
+		"WithNegativeValue_ReturnsError": {
+			options:       RetentionOptions{Last: toPtr(-1)},
+			expectedError: "must not be negative",
+		},
+		"WithInvalidStrategyPolicy_ReturnsError": {
+			options: RetentionOptions{
+				Strategies: map[string]string{
+					"deletion": "first=2",
+				},
+			},
+			expectedError: "invalid retention for strategy deletion",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			options, err := c.options.Options()
+
+			if c.expectedError != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(c.expectedError)))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(options).To(HaveLen(c.expectedCount))
+			}
+		})
+	}
+}
+
+func TestRetentionOptions_Override_PrefersFlags(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	flags := RetentionOptions{
+		First: toPtr(3),
+		Strategies: map[string]string{
+			"deletion": "first:4",
+		},
+	}
+
+	config := RetentionOptions{
+		First: toPtr(2),
+		Last:  toPtr(2),
+		Strategies: map[string]string{
+			"deletion":          "first:1",
+			"deferred-creation": "last:3",
+		},
+	}
+
+	result := flags.override(&config)
+
+	g.Expect(result.First).To(HaveValue(Equal(3)))
+	g.Expect(result.Last).To(HaveValue(Equal(2)))
+	g.Expect(result.Every).To(BeNil())
+	g.Expect(result.Strategies).To(Equal(map[string]string{
+		"deletion":          "first:4",
+		"deferred-creation": "last:3",
+	}))
+}
+
+func TestCleanPath_WithRetention_RetainsMoreInteractions(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		retain   RetentionOptions
+		modified int
+	}{
+		"DefaultRetention_RemovesIntermediateGETs": {
+			modified: 1,
+		},
+		"RetainingFirstTwoAndLastTwo_RemovesNothing": {
+			retain: RetentionOptions{
+				First: toPtr(2),
+				Last:  toPtr(2),
+			},
+			modified: 0,
+		},
+		"RetainingForDeletionStrategy_RemovesNothing": {
+			retain: RetentionOptions{
+				Strategies: map[string]string{
+					"deletion": "first:3",
+				},
+			},
+			modified: 0,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			cassettePath := copyTestData(t, g, "deletion.yaml", t.TempDir(), "deletion.yaml")
+
+			cmd := &CleanCommand{
+				Clean: CleaningOptions{
+					strategies: selecting(true, vcrcleaner.StrategyDeletion),
+					Retain:     c.retain,
+				},
+			}
+
+			ctx := &Context{
+				Log: slogt.New(t),
+			}
+
+			err := cmd.cleanFileWithLog(ctx, ctx.Log, cassettePath)
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ctx.FilesModified).To(Equal(c.modified))
+		})
+	}
+}
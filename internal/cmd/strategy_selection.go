@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/rotisserie/eris"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
+
+// selectionFlag binds a generated command line flag to the selection of a strategy or family.
+type selectionFlag struct {
+	value     **bool           // Field of the generated flags holding the value, once set
+	selection map[string]*bool // Selection to update
+	key       string           // Name of the strategy or family within the selection
+}
+
+// AddStrategyFlags generates a command line flag for each registered cleaning strategy, along with a flag for each
+// family of strategies (other than the generic family) selecting the entire family. Values set on the command line
+// are copied into the selections once parsing is complete.
+// Must be called before the options are passed to kong.
+func (opt *CleaningOptions) AddStrategyFlags() {
+	if opt.strategies == nil {
+		opt.strategies = make(map[string]*bool)
+	}
+
+	if opt.families == nil {
+		opt.families = make(map[string]*bool)
+	}
+
+	var (
+		fields   []reflect.StructField
+		bindings []selectionFlag
+	)
+
+	add := func(name string, help string, selection map[string]*bool, key string) {
+		fields = append(fields, reflect.StructField{
+			Name: "Flag" + strconv.Itoa(len(fields)),
+			Type: reflect.TypeFor[*bool](),
+			Tag:  reflect.StructTag("name:" + strconv.Quote(name) + " help:" + strconv.Quote(help)),
+		})
+		bindings = append(bindings, selectionFlag{selection: selection, key: key})
+	}
+
+	families := make(map[string]bool)
+	for _, s := range vcrcleaner.Strategies() {
+		if s.Family != vcrcleaner.FamilyGeneric && !families[s.Family] {
+			families[s.Family] = true
+			help := "Clean interactions using every " + vcrcleaner.FamilyDisplayName(s.Family) + " strategy."
+			add(s.Family+"-all", help, opt.families, s.Family)
+		}
+
+		add(flagName(s), s.Description, opt.strategies, s.Name)
+	}
+
+	flags := reflect.New(reflect.StructOf(fields))
+	for index := range bindings {
+		//nolint:forcetypeassert // Every generated field is a *bool
+		bindings[index].value = flags.Elem().Field(index).Addr().Interface().(**bool)
+	}
+
+	opt.Flags = append(opt.Flags, flags.Interface())
+	opt.selectionFlags = append(opt.selectionFlags, bindings...)
+}
+
+// AfterApply copies the strategy selections made on the command line into the selections of strategies and families.
+// Called by kong once the command line has been parsed.
+func (opt *CleaningOptions) AfterApply() error {
+	for _, f := range opt.selectionFlags {
+		if *f.value != nil {
+			f.selection[f.key] = *f.value
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalYAML reads cleaning options from a project configuration file.
+// Strategies are selected using the camelCase form of their flags; those outside the generic family are nested
+// under the name of their family, alongside an 'all' key selecting the entire family.
+// Unknown keys are reported as errors, so that typos don't silently disable cleaning.
+func (opt *CleaningOptions) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return eris.Errorf("line %d: expected a mapping of cleaning options", node.Line)
+	}
+
+	// Options other than strategy selections are decoded directly into the matching fields
+	fields := &yaml.Node{
+		Kind: yaml.MappingNode,
+		Tag:  "!!map",
+		Line: node.Line,
+	}
+
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		key, value := node.Content[index], node.Content[index+1]
+
+		if strategy, ok := strategyWithKey(vcrcleaner.FamilyGeneric, key.Value); ok {
+			if err := opt.selectStrategy(strategy, value); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if family, ok := familyWithKey(key.Value); ok {
+			if err := opt.selectFamily(family, value); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		fields.Content = append(fields.Content, key, value)
+	}
+
+	if err := checkKnownFields(fields, reflect.TypeFor[cleaningOptionFields]()); err != nil {
+		return err
+	}
+
+	//nolint:wrapcheck // Errors from yaml already identify the line at fault
+	return fields.Decode((*cleaningOptionFields)(opt))
+}
+
+// cleaningOptionFields has the same fields as CleaningOptions, allowing them to be decoded without recursing into
+// UnmarshalYAML.
+type cleaningOptionFields CleaningOptions
+
+// selectStrategy decodes the selection of a strategy from a project configuration file.
+func (opt *CleaningOptions) selectStrategy(strategy vcrcleaner.Strategy, node *yaml.Node) error {
+	var selected bool
+	if err := node.Decode(&selected); err != nil {
+		return eris.Wrapf(err, "selecting strategy %s", strategy.Name)
+	}
+
+	if opt.strategies == nil {
+		opt.strategies = make(map[string]*bool)
+	}
+
+	opt.strategies[strategy.Name] = &selected
+
+	return nil
+}
+
+// selectFamily decodes the selections made for a family of strategies from a project configuration file.
+func (opt *CleaningOptions) selectFamily(family string, node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return eris.Errorf("line %d: expected a mapping of %s strategies", node.Line, family)
+	}
+
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		key, value := node.Content[index], node.Content[index+1]
+
+		if strategy, ok := strategyWithKey(family, key.Value); ok {
+			if err := opt.selectStrategy(strategy, value); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if key.Value != "all" {
+			return eris.Errorf("line %d: unknown %s strategy %q", key.Line, family, key.Value)
+		}
+
+		var selected bool
+		if err := value.Decode(&selected); err != nil {
+			return eris.Wrapf(err, "selecting all %s strategies", family)
+		}
+
+		if opt.families == nil {
+			opt.families = make(map[string]*bool)
+		}
+
+		opt.families[family] = &selected
+	}
+
+	return nil
+}
+
+// strategyWithKey finds the registered strategy in the specified family selected by key in a project configuration
+// file.
+func strategyWithKey(family string, key string) (vcrcleaner.Strategy, bool) {
+	for _, s := range vcrcleaner.Strategies() {
+		if s.Family == family && configKey(s.Flag) == key {
+			return s, true
+		}
+	}
+
+	return vcrcleaner.Strategy{}, false
+}
+
+// familyWithKey finds the family of registered strategies (other than the generic family) selected by key in a
+// project configuration file.
+func familyWithKey(key string) (string, bool) {
+	for _, s := range vcrcleaner.Strategies() {
+		if s.Family != vcrcleaner.FamilyGeneric && configKey(s.Family) == key {
+			return s.Family, true
+		}
+	}
+
+	return "", false
+}
+
+// flagName returns the name of the command line flag selecting the strategy, without the common 'clean-' prefix.
+// Strategies outside the generic family are prefixed by the name of their family.
+func flagName(strategy vcrcleaner.Strategy) string {
+	if strategy.Family == vcrcleaner.FamilyGeneric {
+		return strategy.Flag
+	}
+
+	return strategy.Family + "-" + strategy.Flag
+}
+
+// configKey converts a kebab-case name into the camelCase form used in project configuration files.
+func configKey(name string) string {
+	words := strings.Split(name, "-")
+	for index := 1; index < len(words); index++ {
+		if words[index] != "" {
+			words[index] = strings.ToUpper(words[index][:1]) + words[index][1:]
+		}
+	}
+
+	return strings.Join(words, "")
+}
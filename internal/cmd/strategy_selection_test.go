@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
+
+// parseCheck parses the arguments of a check command, returning the cleaning options selected.
+func parseCheck(t *testing.T, g Gomega, args ...string) *CleaningOptions {
+	t.Helper()
+
+	cli := NewCLI()
+
+	parser, err := kong.New(cli)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	_, err = parser.Parse(append([]string{"check", "cassette.yaml"}, args...))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	return &cli.Check.Clean
+}
+
+// AddStrategyFlags Tests
+
+func TestAddStrategyFlags_ForEachStrategy_SelectsOnlyThatStrategy(t *testing.T) {
+	t.Parallel()
+
+	for _, strategy := range vcrcleaner.Strategies() {
+		t.Run(strategy.Name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			opt := parseCheck(t, g, "--clean-"+flagName(strategy))
+
+			for _, s := range vcrcleaner.Strategies() {
+				g.Expect(opt.ShouldClean(s)).To(Equal(s.Name == strategy.Name), s.Name)
+			}
+		})
+	}
+}
+
+func TestAddStrategyFlags_WithFamilyFlag_SelectsEntireFamily(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	opt := parseCheck(t, g, "--clean-azure-all", "--clean-azure-resource-deletions=false")
+
+	for _, s := range vcrcleaner.Strategies() {
+		expected := s.Family == vcrcleaner.FamilyAzure && s.Name != vcrcleaner.StrategyAzureResourceDeletion
+		g.Expect(opt.ShouldClean(s)).To(Equal(expected), s.Name)
+	}
+}
+
+func TestAddStrategyFlags_FamilyFlag_DescribesFamilyByDisplayName(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	parser, err := kong.New(NewCLI())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	help := make(map[string]string)
+
+	for _, command := range parser.Model.Children {
+		if command.Name == "check" {
+			for _, flag := range command.Flags {
+				help[flag.Name] = flag.Help
+			}
+		}
+	}
+
+	g.Expect(help).To(HaveKeyWithValue("clean-azure-all", "Clean interactions using every Azure strategy."))
+}
+
+func TestAddStrategyFlags_WithNoFlags_SelectsNothing(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	opt := parseCheck(t, g)
+
+	g.Expect(opt.Options()).To(BeEmpty())
+}
+
+// UnmarshalYAML Tests
+
+func TestLoadProjectConfig_WithFamilySelections_SelectsStrategies(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := writeProjectConfig(t, g, t.TempDir(), `
+clean:
+  deferredCreations: true
+  azure:
+    all: true
+    longRunningOperations: false
+`)
+
+	config, err := LoadProjectConfig(path)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	for _, s := range vcrcleaner.Strategies() {
+		expected := s.Name == vcrcleaner.StrategyDeferredCreation ||
+			(s.Family == vcrcleaner.FamilyAzure && s.Name != vcrcleaner.StrategyAzureLongRunningOperation)
+		g.Expect(config.Clean.ShouldClean(s)).To(Equal(expected), s.Name)
+	}
+}
+
+func TestLoadProjectConfig_WithUnknownKey_ReportsKey(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		content  string
+		expected string
+	}{
+		"UnknownOption": {
+			content:  "clean:\n  delete: true\n",
+			expected: "line 2: field delete not found",
+		},
+		"UnknownFamilyStrategy": {
+			content:  "clean:\n  azure:\n    deletes: true\n",
+			expected: `line 3: unknown azure strategy "deletes"`,
+		},
+		"UnknownRetention": {
+			content:  "clean:\n  retain:\n    frist: 2\n",
+			expected: "line 3: field frist not found",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			path := writeProjectConfig(t, g, t.TempDir(), c.content)
+
+			_, err := LoadProjectConfig(path)
+
+			g.Expect(err).To(MatchError(ContainSubstring(c.expected)))
+		})
+	}
+}
+
+// configKey Tests
+
+func TestConfigKey(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"deletes":                 "deletes",
+		"long-running-operations": "longRunningOperations",
+		"azure":                   "azure",
+	}
+
+	for name, expected := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			g.Expect(configKey(name)).To(Equal(expected))
+		})
+	}
+}
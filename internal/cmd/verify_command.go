@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"github.com/rotisserie/eris"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
+
+// VerifyCommand replays the requests of an original recording against a cleaned one, entirely offline, reporting any
+// request that no longer replays as it was recorded.
+//
+//nolint:revive // Struct tags are clearer kept on a single line
+type VerifyCommand struct {
+	Original string `arg:"" help:"Original recording, a go-vcr cassette or .har file." type:"existingfile"`
+	Cleaned  string `arg:"" help:"Cleaned recording to replay against."               type:"existingfile"`
+}
+
+// Run replays the original recording against the cleaned one, failing if any request doesn't replay as recorded.
+func (c *VerifyCommand) Run(ctx *Context) error {
+	verifyCtx, cancel := ctx.fileContext(0)
+	defer cancel()
+
+	verification, err := vcrcleaner.VerifyFiles(verifyCtx, ctx.Log, c.Original, c.Cleaned)
+	if err != nil {
+		return eris.Wrapf(err, "verifying %s against %s", c.Cleaned, c.Original)
+	}
+
+	for _, mismatch := range verification.Mismatches {
+		ctx.Log.Warn(
+			"Request didn't replay as recorded",
+			"id", mismatch.ID,
+			"method", mismatch.Method,
+			"url", mismatch.URL,
+			"reason", mismatch.Reason,
+		)
+	}
+
+	if !verification.Passed() {
+		return eris.Errorf(
+			"%d of %d requests from %s didn't replay as recorded against %s",
+			len(verification.Mismatches),
+			verification.Requests+verification.Skipped,
+			c.Original,
+			c.Cleaned)
+	}
+
+	ctx.Log.Info(
+		"Cleaned recording replays as recorded",
+		"cleaned", c.Cleaned,
+		"replayed", verification.Requests,
+		"skipped", verification.Skipped,
+	)
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
+
+func TestVerifyRun_AfterClean_Succeeds(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+	originalPath := copyTestData(t, g, "deletion.yaml", tmpDir, "original.yaml")
+	cleanedPath := copyTestData(t, g, "deletion.yaml", tmpDir, "cleaned.yaml")
+
+	clean := &CleanCommand{
+		Clean: CleaningOptions{
+			strategies: selecting(true, vcrcleaner.StrategyDeletion),
+		},
+		Globs: []string{cleanedPath},
+	}
+
+	ctx := &Context{
+		Log: slogt.New(t),
+	}
+
+	g.Expect(clean.Run(ctx)).To(Succeed())
+	g.Expect(ctx.FilesModified).To(Equal(1))
+
+	verify := &VerifyCommand{
+		Original: originalPath,
+		Cleaned:  cleanedPath,
+	}
+
+	g.Expect(verify.Run(ctx)).To(Succeed())
+}
+
+func TestVerifyRun_AgainstUnrelatedCassette_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tmpDir := t.TempDir()
+	originalPath := copyTestData(t, g, "deletion.yaml", tmpDir, "original.yaml")
+	unrelatedPath := createTestRecording(t, g, tmpDir, "unrelated.yaml")
+
+	verify := &VerifyCommand{
+		Original: originalPath,
+		Cleaned:  unrelatedPath,
+	}
+
+	err := verify.Run(&Context{Log: slogt.New(t)})
+
+	g.Expect(err).To(MatchError(ContainSubstring("6 of 6 requests")))
+}
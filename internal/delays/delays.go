@@ -0,0 +1,45 @@
+package delays
+
+import "github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+
+// HintHeaders are the response headers a client may use to decide how long to wait before polling again.
+// Retry-After is given in seconds; the others (used by Azure) are given in milliseconds.
+var HintHeaders = []string{
+	"Retry-After",
+	"Retry-After-Ms",
+	"X-Ms-Retry-After-Ms",
+}
+
+// Configurable is implemented by analyzers that can remove polling delays from the polling sequences they clean.
+// That covers the interaction starting the sequence and its final poll, as well as the polls retained.
+type Configurable interface {
+	// SetZeroDelays controls whether delay hints on the sequence are rewritten to zero.
+	SetZeroDelays(enabled bool)
+}
+
+// Zero rewrites any delay hints in the responses of the interactions to zero, so that a client replaying them
+// polls again immediately. Interactions without delay hints are left unchanged.
+// Nil interactions are skipped, so a monitor can pass a trigger or terminal poll it may not have.
+// Returns the number of headers rewritten.
+func Zero(interactions ...interaction.Interface) int {
+	rewritten := 0
+
+	for _, i := range interactions {
+		if i == nil {
+			continue
+		}
+
+		for _, name := range HintHeaders {
+			value, ok := i.Response().Header(name)
+			if !ok || value == "0" {
+				continue
+			}
+
+			i.Response().SetHeader(name, "0")
+
+			rewritten++
+		}
+	}
+
+	return rewritten
+}
@@ -0,0 +1,60 @@
+package delays
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/fake"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/must"
+)
+
+func TestZero_WithDelayHints_RewritesEachToZero(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	operationURL := must.ParseURL(t, "https://management.azure.com/operations/123")
+	i := fake.Interaction(operationURL, http.MethodGet, 202)
+	i.SetResponseHeader("Retry-After", "30")
+	i.SetResponseHeader("x-ms-retry-after-ms", "30000")
+
+	rewritten := Zero(i)
+
+	g.Expect(rewritten).To(Equal(2))
+
+	retryAfter, ok := i.Response().Header("Retry-After")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(retryAfter).To(Equal("0"))
+
+	retryAfterMs, ok := i.Response().Header("X-Ms-Retry-After-Ms")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(retryAfterMs).To(Equal("0"))
+}
+
+func TestZero_WithoutDelayHints_LeavesInteractionUnchanged(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	operationURL := must.ParseURL(t, "https://management.azure.com/operations/123")
+	i := fake.Interaction(operationURL, http.MethodGet, 202)
+	i.SetResponseHeader("Location", operationURL.String())
+
+	rewritten := Zero(i)
+
+	g.Expect(rewritten).To(Equal(0))
+
+	_, ok := i.Response().Header("Retry-After")
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestZero_WithZeroDelay_DoesNotCountRewrite(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	operationURL := must.ParseURL(t, "https://management.azure.com/operations/123")
+	i := fake.Interaction(operationURL, http.MethodGet, 202)
+	i.SetResponseHeader("Retry-After", "0")
+
+	g.Expect(Zero(i)).To(Equal(0))
+}
@@ -2,7 +2,9 @@ package fake
 
 import (
 	"fmt"
+	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -43,6 +45,7 @@ func Interaction(
 		fullURL: fullURL,
 		baseURL: baseURL,
 		method:  method,
+		headers: make(map[string][]string),
 	}
 
 	i.response = testResponse{
@@ -78,7 +81,27 @@ func (i *TestInteraction) SetResponseBody(body string) {
 	i.response.responseBody = body
 }
 
+// SetResponseDuration sets the recorded duration of the response for the fake interaction.
+func (i *TestInteraction) SetResponseDuration(duration time.Duration) {
+	i.response.duration = duration
+}
+
 // SetResponseHeader sets a response header for the fake interaction.
 func (i *TestInteraction) SetResponseHeader(name, value string) {
 	i.response.SetHeader(name, value)
 }
+
+// SetRequestBody sets the request body for the fake interaction.
+func (i *TestInteraction) SetRequestBody(body string) {
+	i.request.body = body
+}
+
+// SetRequestHeader sets a request header for the fake interaction.
+func (i *TestInteraction) SetRequestHeader(name, value string) {
+	if i.request.headers == nil {
+		i.request.headers = make(map[string][]string)
+	}
+
+	key := http.CanonicalHeaderKey(name)
+	i.request.headers[key] = []string{value}
+}
@@ -1,12 +1,17 @@
 package fake
 
-import "net/url"
+import (
+	"net/http"
+	"net/url"
+)
 
 // Request implementation.
 type testRequest struct {
 	fullURL *url.URL
 	baseURL *url.URL
 	method  string
+	headers http.Header
+	body    string
 }
 
 // FullURL returns the full URL of the request.
@@ -23,3 +28,33 @@ func (r *testRequest) BaseURL() *url.URL {
 func (r *testRequest) Method() string {
 	return r.method
 }
+
+// Header returns the value of the specified request header.
+func (r *testRequest) Header(name string) (string, bool) {
+	if r.headers == nil {
+		return "", false
+	}
+
+	key := http.CanonicalHeaderKey(name)
+
+	values, ok := r.headers[key]
+	if !ok || len(values) == 0 {
+		return "", false
+	}
+
+	return values[0], true
+}
+
+// Headers returns a copy of all the request headers.
+func (r *testRequest) Headers() http.Header {
+	if r.headers == nil {
+		return make(http.Header)
+	}
+
+	return r.headers.Clone()
+}
+
+// Body returns the body of the request.
+func (r *testRequest) Body() []byte {
+	return []byte(r.body)
+}
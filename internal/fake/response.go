@@ -1,12 +1,18 @@
 package fake
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/jsondoc"
+)
 
 // Response implementation.
 type testResponse struct {
 	statusCode      int
 	responseBody    string
 	responseHeaders map[string][]string
+	duration        time.Duration
 }
 
 // StatusCode returns the HTTP status code of the response.
@@ -54,3 +60,34 @@ func (r *testResponse) RemoveHeader(name string) {
 func (r *testResponse) Body() []byte {
 	return []byte(r.responseBody)
 }
+
+// Duration returns the recorded duration of the response.
+func (r *testResponse) Duration() time.Duration {
+	return r.duration
+}
+
+// SetDuration changes the recorded duration of the response.
+func (r *testResponse) SetDuration(duration time.Duration) {
+	r.duration = duration
+}
+
+// Timestamp returns when the response was sent, taken from its Date header, if present.
+func (r *testResponse) Timestamp() (time.Time, bool) {
+	value, ok := r.Header("Date")
+	if !ok {
+		return time.Time{}, false
+	}
+
+	result, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return result, true
+}
+
+// JSON returns the body of the response parsed as JSON.
+// Fake bodies can be changed after creation, so the document is parsed on every call.
+func (r *testResponse) JSON() *jsondoc.Document {
+	return jsondoc.Parse(r.Body())
+}
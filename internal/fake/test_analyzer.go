@@ -1,10 +1,12 @@
 package fake
 
 import (
+	"context"
 	"log/slog"
 
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/retention"
 )
 
 // TestAnalyzer is a mock analyzer for testing purposes.
@@ -13,6 +15,11 @@ type TestAnalyzer struct {
 	analyzeFunc     func(*slog.Logger, interaction.Interface) (analyzer.Result, error)
 	CallCount       int
 	LastInteraction interaction.Interface
+	Retention       *retention.Policy // Retention policy most recently set, if any
+	ZeroDelays      bool              // Whether delay hints should be rewritten to zero
+	FinishCount     int               // Number of times Finish has been called
+	finishResult    analyzer.Result   // Result returned by Finish
+	calls           *[]string         // Shared log of calls, if the order of calls is being recorded
 }
 
 // Analyzer creates a new TestAnalyzer with the given name.
@@ -26,13 +33,42 @@ func Analyzer(name string) *TestAnalyzer {
 }
 
 // Analyze processes an interaction and tracks call count and last interaction.
-func (f *TestAnalyzer) Analyze(log *slog.Logger, inter interaction.Interface) (analyzer.Result, error) {
+func (f *TestAnalyzer) Analyze(
+	_ context.Context,
+	log *slog.Logger,
+	inter interaction.Interface,
+) (analyzer.Result, error) {
 	f.CallCount++
 	f.LastInteraction = inter
 
+	if f.calls != nil {
+		*f.calls = append(*f.calls, f.name)
+	}
+
 	return f.analyzeFunc(log, inter)
 }
 
+// Finish records that the end of the recording was reached, returning the configured result.
+func (f *TestAnalyzer) Finish(context.Context, *slog.Logger) (analyzer.Result, error) {
+	f.FinishCount++
+
+	if f.calls != nil {
+		*f.calls = append(*f.calls, f.name)
+	}
+
+	return f.finishResult, nil
+}
+
+// SetRetention records the retention policy set for the analyzer.
+func (f *TestAnalyzer) SetRetention(policy retention.Policy) {
+	f.Retention = &policy
+}
+
+// SetZeroDelays records whether the analyzer should rewrite delay hints to zero.
+func (f *TestAnalyzer) SetZeroDelays(enabled bool) {
+	f.ZeroDelays = enabled
+}
+
 // WithResult configures the analyzer to return the specified result.
 func (f *TestAnalyzer) WithResult(result analyzer.Result) *TestAnalyzer {
 	f.analyzeFunc = func(*slog.Logger, interaction.Interface) (analyzer.Result, error) {
@@ -59,6 +95,21 @@ func (f *TestAnalyzer) WithResults(results ...analyzer.Result) *TestAnalyzer {
 	return f
 }
 
+// WithFinishResult configures the analyzer to return the specified result when finished.
+func (f *TestAnalyzer) WithFinishResult(result analyzer.Result) *TestAnalyzer {
+	f.finishResult = result
+
+	return f
+}
+
+// RecordingCallsTo configures the analyzer to append its name to calls each time it analyzes an interaction or is
+// finished, so the order in which several analyzers run can be observed.
+func (f *TestAnalyzer) RecordingCallsTo(calls *[]string) *TestAnalyzer {
+	f.calls = calls
+
+	return f
+}
+
 // WithError configures the analyzer to return an error.
 func (f *TestAnalyzer) WithError(err error) *TestAnalyzer {
 	f.analyzeFunc = func(*slog.Logger, interaction.Interface) (analyzer.Result, error) {
@@ -0,0 +1,51 @@
+package generic
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+)
+
+// CompressDurations is an analyzer that caps the recorded duration of every interaction at a limit, so that replays
+// using go-vcr latency simulation run faster. It never excludes interactions; any it compresses that are later
+// excluded by other analyzers are discarded regardless.
+type CompressDurations struct {
+	limit time.Duration
+}
+
+var _ analyzer.Interface = &CompressDurations{}
+
+// NewCompressDurations creates a new CompressDurations analyzer.
+// limit is the longest recorded duration to keep; zero removes simulated latency entirely.
+func NewCompressDurations(limit time.Duration) *CompressDurations {
+	return &CompressDurations{
+		limit: limit,
+	}
+}
+
+// Analyze processes another interaction in the sequence.
+func (c *CompressDurations) Analyze(
+	_ context.Context,
+	log *slog.Logger,
+	i interaction.Interface,
+) (analyzer.Result, error) {
+	duration := i.Response().Duration()
+	if duration <= c.limit {
+		// Already quick enough
+		return analyzer.Result{}, nil
+	}
+
+	log.Debug(
+		"Compressing recorded duration",
+		"url", i.Request().BaseURL().String(),
+		"duration", duration,
+		"limit", c.limit,
+	)
+
+	i.Response().SetDuration(c.limit)
+
+	return analyzer.Result{}, nil
+}
@@ -0,0 +1,58 @@
+package generic
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/fake"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/must"
+)
+
+func TestCompressDurations_CapsDurationAtLimit(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		duration time.Duration
+		limit    time.Duration
+		expected time.Duration
+	}{
+		"LongerThanLimit_Capped": {
+			duration: 5 * time.Second,
+			limit:    100 * time.Millisecond,
+			expected: 100 * time.Millisecond,
+		},
+		"ShorterThanLimit_Unchanged": {
+			duration: 50 * time.Millisecond,
+			limit:    100 * time.Millisecond,
+			expected: 50 * time.Millisecond,
+		},
+		"ZeroLimit_RemovesLatency": {
+			duration: 2 * time.Second,
+			limit:    0,
+			expected: 0,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+			i := fake.Interaction(baseURL, http.MethodGet, 200)
+			i.SetResponseDuration(c.duration)
+
+			result, err := NewCompressDurations(c.limit).Analyze(t.Context(), slogt.New(t), i)
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(result.Finished).To(BeFalse())
+			g.Expect(result.Excluded).To(BeEmpty())
+			g.Expect(i.Response().Duration()).To(Equal(c.expected))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package generic
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 
@@ -30,6 +31,7 @@ func NewDetectDeferredCreation() *DetectDeferredCreation {
 
 // Analyze processes another interaction in the sequence.
 func (d *DetectDeferredCreation) Analyze(
+	_ context.Context,
 	log *slog.Logger,
 	i interaction.Interface,
 ) (analyzer.Result, error) {
@@ -21,7 +21,7 @@ func TestDetectDeferredCreation_GET404_SpawnsMonitor(t *testing.T) {
 	log := slogt.New(t)
 
 	getInteraction := fake.Interaction(baseURL, http.MethodGet, 404)
-	result, err := detector.Analyze(log, getInteraction)
+	result, err := detector.Analyze(t.Context(), log, getInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeFalse())
@@ -53,7 +53,7 @@ func TestDetectDeferredCreation_Non404StatusCodes_DoesNotSpawn(t *testing.T) {
 			log := slogt.New(t)
 
 			getInteraction := fake.Interaction(baseURL, http.MethodGet, c.statusCode)
-			result, err := detector.Analyze(log, getInteraction)
+			result, err := detector.Analyze(t.Context(), log, getInteraction)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result).To(Equal(analyzer.Result{}))
@@ -84,7 +84,7 @@ func TestDetectDeferredCreation_NonGETMethodsWith404_DoesNotSpawn(t *testing.T)
 			log := slogt.New(t)
 
 			interaction := fake.Interaction(baseURL, c.method, 404)
-			result, err := detector.Analyze(log, interaction)
+			result, err := detector.Analyze(t.Context(), log, interaction)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result).To(Equal(analyzer.Result{}))
@@ -107,15 +107,15 @@ func TestDetectDeferredCreation_MultipleGET404s_SpawnsMultipleMonitors(t *testin
 	get2 := fake.Interaction(url2, http.MethodGet, 404)
 	get3 := fake.Interaction(url3, http.MethodGet, 404)
 
-	result1, err := detector.Analyze(log, get1)
+	result1, err := detector.Analyze(t.Context(), log, get1)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result1.Spawn).To(HaveLen(1))
 
-	result2, err := detector.Analyze(log, get2)
+	result2, err := detector.Analyze(t.Context(), log, get2)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result2.Spawn).To(HaveLen(1))
 
-	result3, err := detector.Analyze(log, get3)
+	result3, err := detector.Analyze(t.Context(), log, get3)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result3.Spawn).To(HaveLen(1))
 
@@ -141,7 +141,7 @@ func TestDetectDeferredCreation_NeverFinishes(t *testing.T) {
 	}
 
 	for _, inter := range interactions {
-		result, err := detector.Analyze(log, inter)
+		result, err := detector.Analyze(t.Context(), log, inter)
 		g.Expect(err).ToNot(HaveOccurred())
 		g.Expect(result.Finished).To(BeFalse(), "DetectDeferredCreation should never finish")
 	}
@@ -155,7 +155,7 @@ func TestDetectDeferredCreation_EmptyResult_WhenNoAction(t *testing.T) {
 	log := slogt.New(t)
 
 	getInteraction := fake.Interaction(baseURL, http.MethodGet, 200)
-	result, err := detector.Analyze(log, getInteraction)
+	result, err := detector.Analyze(t.Context(), log, getInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result).To(Equal(analyzer.Result{}))
@@ -1,6 +1,7 @@
 package generic
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 
@@ -23,6 +24,7 @@ func NewDetectDeletion() *DetectDeletion {
 // Analyze processes another interaction in the sequence.
 // interaction is the interaction to analyze.
 func (*DetectDeletion) Analyze(
+	_ context.Context,
 	log *slog.Logger,
 	i interaction.Interface,
 ) (analyzer.Result, error) {
@@ -35,7 +37,7 @@ func (*DetectDeletion) Analyze(
 			"url", reqURL.String(),
 		)
 
-		monitor := NewMonitorDeletion(reqURL)
+		monitor := NewMonitorDeletion(i)
 
 		return analyzer.Spawn(monitor), nil
 	}
@@ -22,7 +22,7 @@ func TestDetectDeletion_SuccessfulDELETE_SpawnsMonitor(t *testing.T) {
 
 	// Successful DELETE should spawn a MonitorDeletion analyzer
 	deleteInteraction := fake.Interaction(baseURL, http.MethodDelete, 200)
-	result, err := detector.Analyze(log, deleteInteraction)
+	result, err := detector.Analyze(t.Context(), log, deleteInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeFalse())
@@ -52,7 +52,7 @@ func TestDetectDeletion_Various2xxDELETEStatusCodes_SpawnsMonitor(t *testing.T)
 			log := slogt.New(t)
 
 			deleteInteraction := fake.Interaction(baseURL, http.MethodDelete, c.statusCode)
-			result, err := detector.Analyze(log, deleteInteraction)
+			result, err := detector.Analyze(t.Context(), log, deleteInteraction)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result.Spawn).To(HaveLen(1))
@@ -85,7 +85,7 @@ func TestDetectDeletion_FailedDELETE_DoesNotSpawn(t *testing.T) {
 			log := slogt.New(t)
 
 			deleteInteraction := fake.Interaction(baseURL, http.MethodDelete, c.statusCode)
-			result, err := detector.Analyze(log, deleteInteraction)
+			result, err := detector.Analyze(t.Context(), log, deleteInteraction)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result).To(Equal(analyzer.Result{}))
@@ -117,7 +117,7 @@ func TestDetectDeletion_NonDELETEMethods_DoesNotSpawn(t *testing.T) {
 			log := slogt.New(t)
 
 			interaction := fake.Interaction(baseURL, c.method, c.statusCode)
-			result, err := detector.Analyze(log, interaction)
+			result, err := detector.Analyze(t.Context(), log, interaction)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result).To(Equal(analyzer.Result{}))
@@ -141,15 +141,15 @@ func TestDetectDeletion_MultipleDELETEs_SpawnsMultipleMonitors(t *testing.T) {
 	delete2 := fake.Interaction(url2, http.MethodDelete, 204)
 	delete3 := fake.Interaction(url3, http.MethodDelete, 202)
 
-	result1, err := detector.Analyze(log, delete1)
+	result1, err := detector.Analyze(t.Context(), log, delete1)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result1.Spawn).To(HaveLen(1))
 
-	result2, err := detector.Analyze(log, delete2)
+	result2, err := detector.Analyze(t.Context(), log, delete2)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result2.Spawn).To(HaveLen(1))
 
-	result3, err := detector.Analyze(log, delete3)
+	result3, err := detector.Analyze(t.Context(), log, delete3)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result3.Spawn).To(HaveLen(1))
 
@@ -177,7 +177,7 @@ func TestDetectDeletion_NeverFinishes(t *testing.T) {
 	}
 
 	for _, inter := range interactions {
-		result, err := detector.Analyze(log, inter)
+		result, err := detector.Analyze(t.Context(), log, inter)
 		g.Expect(err).ToNot(HaveOccurred())
 		g.Expect(result.Finished).To(BeFalse(), "DetectDeletion should never finish")
 	}
@@ -191,7 +191,7 @@ func TestDetectDeletion_SpawnedMonitorHasCorrectURL(t *testing.T) {
 	log := slogt.New(t)
 
 	deleteInteraction := fake.Interaction(baseURL, http.MethodDelete, 200)
-	result, err := detector.Analyze(log, deleteInteraction)
+	result, err := detector.Analyze(t.Context(), log, deleteInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Spawn).To(HaveLen(1))
@@ -211,7 +211,7 @@ func TestDetectDeletion_EmptyResult_WhenNoAction(t *testing.T) {
 
 	// Non-DELETE interaction
 	getInteraction := fake.Interaction(baseURL, http.MethodGet, 200)
-	result, err := detector.Analyze(log, getInteraction)
+	result, err := detector.Analyze(t.Context(), log, getInteraction)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result).To(Equal(analyzer.Result{}))
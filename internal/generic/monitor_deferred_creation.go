@@ -1,12 +1,15 @@
 package generic
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"net/url"
 
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/delays"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/retention"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/urltool"
 )
 
@@ -14,16 +17,22 @@ import (
 // It watches for an uninterrupted sequence of GET requests returning 404 (Not Found) to a specific URL,
 // followed by a GET that returns a 2xx status (indicating the resource has been created).
 // Once creation is confirmed, the analyzer marks itself as Finished.
-// All 404 GET requests are accumulated, and when the 2xx is seen, the analyzer indicates all but
-// the first and last 404 are removable.
+// All 404 GET requests are accumulated, and when the 2xx is seen, the analyzer indicates those not kept by the
+// retention policy (by default, all but the first and last 404) are removable.
 // If any other requests to that URL are seen (e.g. a POST or PUT), or if a GET returns a non-404
 // and non-2xx status code, the analyzer abandons monitoring and marks itself as Finished.
+// If the recording ends before the 2xx is seen, the 404 GETs accumulated are collapsed in the same way.
 type MonitorDeferredCreation struct {
 	baseURL      *url.URL
 	interactions []interaction.Interface
+	retention    retention.Policy
+	zeroDelays   bool
 }
 
-var _ analyzer.Interface = (*MonitorDeferredCreation)(nil)
+var (
+	_ analyzer.Interface = (*MonitorDeferredCreation)(nil)
+	_ analyzer.Finisher  = (*MonitorDeferredCreation)(nil)
+)
 
 // NewMonitorDeferredCreation creates a new MonitorDeferredCreation analyzer.
 // firstInteraction is the initial GET→404 that triggered the detector.
@@ -33,11 +42,24 @@ func NewMonitorDeferredCreation(
 	return &MonitorDeferredCreation{
 		baseURL:      firstInteraction.Request().BaseURL(),
 		interactions: []interaction.Interface{firstInteraction},
+		retention:    retention.DefaultPolicy(),
 	}
 }
 
+// SetRetention changes the retention policy used when the creation is confirmed.
+func (m *MonitorDeferredCreation) SetRetention(policy retention.Policy) {
+	m.retention = policy
+}
+
+// SetZeroDelays controls whether delay hints on the polls retained and the GET confirming creation are rewritten
+// to zero.
+func (m *MonitorDeferredCreation) SetZeroDelays(enabled bool) {
+	m.zeroDelays = enabled
+}
+
 // Analyze processes another interaction in the sequence.
 func (m *MonitorDeferredCreation) Analyze(
+	_ context.Context,
 	log *slog.Logger,
 	i interaction.Interface,
 ) (analyzer.Result, error) {
@@ -50,7 +72,7 @@ func (m *MonitorDeferredCreation) Analyze(
 		return analyzer.Result{}, nil
 
 	case interaction.HasMethod(i, http.MethodGet) && interaction.WasSuccessful(i):
-		return m.creationConfirmed(log)
+		return m.creationConfirmed(log, i)
 
 	case interaction.HasMethod(i, http.MethodGet) && statusCode == http.StatusNotFound:
 		// Accumulate this 404 GET request.
@@ -81,11 +103,29 @@ func (m *MonitorDeferredCreation) Analyze(
 	}
 }
 
+// Finish handles the end of the recording before the creation was confirmed.
+func (m *MonitorDeferredCreation) Finish(_ context.Context, log *slog.Logger) (analyzer.Result, error) {
+	log.Debug(
+		"Recording ended before deferred creation was confirmed",
+		"url", m.baseURL.String(),
+	)
+
+	return m.creationConfirmed(log, nil)
+}
+
 // creationConfirmed handles the confirmation of creation via a 2xx GET response.
+// confirmation is the successful GET, or nil if the recording ended first.
 func (m *MonitorDeferredCreation) creationConfirmed(
 	log *slog.Logger,
+	confirmation interaction.Interface,
 ) (analyzer.Result, error) {
-	if len(m.interactions) < 3 {
+	retained, excluded := m.retention.Apply(m.interactions)
+	if m.zeroDelays {
+		delays.Zero(confirmation)
+		delays.Zero(retained...)
+	}
+
+	if len(excluded) == 0 {
 		// Not enough intermediate interactions to exclude.
 		log.Debug(
 			"Short deferred creation monitor, nothing to exclude",
@@ -98,10 +138,12 @@ func (m *MonitorDeferredCreation) creationConfirmed(
 	log.Debug(
 		"Long deferred creation found, excluding intermediate 404s",
 		"url", m.baseURL.String(),
-		"removed", len(m.interactions)-2,
+		"removed", len(excluded),
 	)
 
-	excluded := m.interactions[1 : len(m.interactions)-1]
+	provenance := analyzer.Because(
+		m.baseURL.String(),
+		"intermediate GET returning 404 while waiting for creation")
 
-	return analyzer.FinishedWithExclusions(excluded...), nil
+	return analyzer.FinishedWithExclusions(provenance, excluded...), nil
 }
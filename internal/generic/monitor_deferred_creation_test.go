@@ -24,7 +24,7 @@ func TestMonitorDeferredCreation_SingleGETThenSuccess_MarksFinished(t *testing.T
 	log := slogt.New(t)
 
 	get200 := fake.Interaction(baseURL, http.MethodGet, 200)
-	result, err := monitor.Analyze(log, get200)
+	result, err := monitor.Analyze(t.Context(), log, get200)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeTrue())
@@ -108,7 +108,7 @@ func TestMonitorDeferredCreation_DifferentURL_Ignored(t *testing.T) {
 	log := slogt.New(t)
 
 	i := fake.Interaction(differentURL, http.MethodGet, 200)
-	result, err := monitor.Analyze(log, i)
+	result, err := monitor.Analyze(t.Context(), log, i)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeFalse())
@@ -141,7 +141,7 @@ func TestMonitorDeferredCreation_AbandonsMonitoring(t *testing.T) {
 			log := slogt.New(t)
 
 			abandoningRequest := fake.Interaction(baseURL, c.method, c.statusCode)
-			result, err := monitor.Analyze(log, abandoningRequest)
+			result, err := monitor.Analyze(t.Context(), log, abandoningRequest)
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(result.Finished).To(BeTrue())
@@ -163,17 +163,17 @@ func TestMonitorDeferredCreation_Various2xxStatusCodes_ConfirmCreation(t *testin
 	second404 := fake.Interaction(baseURL, http.MethodGet, 404)
 	third404 := fake.Interaction(baseURL, http.MethodGet, 404)
 
-	result1, err := monitor.Analyze(log, second404)
+	result1, err := monitor.Analyze(t.Context(), log, second404)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result1.Finished).To(BeFalse())
 
-	result2, err := monitor.Analyze(log, third404)
+	result2, err := monitor.Analyze(t.Context(), log, third404)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result2.Finished).To(BeFalse())
 
 	// 201 should also confirm creation
 	get201 := fake.Interaction(baseURL, http.MethodGet, 201)
-	result3, err := monitor.Analyze(log, get201)
+	result3, err := monitor.Analyze(t.Context(), log, get201)
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result3.Finished).To(BeTrue())
 	g.Expect(result3.Excluded).To(HaveLen(1))
@@ -191,7 +191,7 @@ func TestMonitorDeferredCreation_URLWithQueryParameters_MonitorsBaseURL(t *testi
 
 	urlWithParams := must.ParseURL(t, "https://api.example.com/resource/123?param=value")
 	i := fake.Interaction(urlWithParams, http.MethodGet, 404)
-	result, err := monitor.Analyze(log, i)
+	result, err := monitor.Analyze(t.Context(), log, i)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeFalse())
@@ -208,8 +208,29 @@ func TestMonitorDeferredCreation_EmptyResult_WhenIgnoringInteraction(t *testing.
 	log := slogt.New(t)
 
 	i := fake.Interaction(differentURL, http.MethodGet, 200)
-	result, err := monitor.Analyze(log, i)
+	result, err := monitor.Analyze(t.Context(), log, i)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result).To(Equal(analyzer.Result{}))
 }
+
+func TestMonitorDeferredCreation_RecordingEndsBeforeSuccess_MiddleIsRemoved(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	first404 := fake.Interaction(baseURL, http.MethodGet, 404)
+	monitor := NewMonitorDeferredCreation(first404)
+	log := slogt.New(t)
+
+	// The recording ends before the resource is created
+	second404 := fake.Interaction(baseURL, http.MethodGet, 404)
+	third404 := fake.Interaction(baseURL, http.MethodGet, 404)
+
+	result := runAnalyzer(t, log, monitor, second404, third404)
+	g.Expect(result.Finished).To(BeFalse())
+
+	result, err := monitor.Finish(t.Context(), log)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.Excluded).To(ConsistOf(second404))
+}
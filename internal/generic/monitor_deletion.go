@@ -1,12 +1,15 @@
 package generic
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"net/url"
 
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/delays"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/retention"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/urltool"
 )
 
@@ -15,29 +18,51 @@ import (
 // followed by a GET that returns 404 (Not Found).
 // Once the DELETE is confirmed, the analyzer marks itself as Finished.
 // All the GET request returning 2xx are accumulated over multiple interactions, and when the 404 is seen, the analyzer
-// indicates all but the first and last are removable.
+// indicates those not kept by the retention policy (by default, all but the first and last) are removable.
 // If any other requests to that URL are seen (e.g. a POST or PUT), or if a GET returns a non-2xx and non-404 status
 // code, the analyzer abandons monitoring and marks itself as Finished.
+// If the recording ends before the 404 is seen (e.g. because the test stopped polling after a timeout), the GETs
+// accumulated are collapsed in the same way.
 type MonitorDeletion struct {
+	trigger      interaction.Interface
 	baseURL      *url.URL
 	interactions []interaction.Interface
+	retention    retention.Policy
+	zeroDelays   bool
 }
 
-var _ analyzer.Interface = (*MonitorDeletion)(nil)
+var (
+	_ analyzer.Interface = (*MonitorDeletion)(nil)
+	_ analyzer.Finisher  = (*MonitorDeletion)(nil)
+)
 
-// NewMonitorDeletion creates a new MonitorDeletion analyzer for the specified URL.
+// NewMonitorDeletion creates a new MonitorDeletion analyzer.
+// trigger is the successful DELETE that triggered the detector.
 func NewMonitorDeletion(
-	baseURL *url.URL,
+	trigger interaction.Interface,
 ) *MonitorDeletion {
 	return &MonitorDeletion{
-		baseURL: baseURL,
+		trigger:   trigger,
+		baseURL:   trigger.Request().BaseURL(),
+		retention: retention.DefaultPolicy(),
 	}
 }
 
+// SetRetention changes the retention policy used when the deletion is confirmed.
+func (m *MonitorDeletion) SetRetention(policy retention.Policy) {
+	m.retention = policy
+}
+
+// SetZeroDelays controls whether delay hints on the DELETE and the polls retained are rewritten to zero.
+func (m *MonitorDeletion) SetZeroDelays(enabled bool) {
+	m.zeroDelays = enabled
+}
+
 // Analyze processes another interaction in the sequence.
 //
 //nolint:cyclomatic // Complexity is acceptable for this method
 func (m *MonitorDeletion) Analyze(
+	_ context.Context,
 	log *slog.Logger,
 	i interaction.Interface,
 ) (analyzer.Result, error) {
@@ -51,7 +76,7 @@ func (m *MonitorDeletion) Analyze(
 		return analyzer.Result{}, nil
 
 	case interaction.HasMethod(i, http.MethodGet) && statusCode == http.StatusNotFound:
-		return m.deletionConfirmed(log)
+		return m.deletionConfirmed(log, i)
 
 	case interaction.HasMethod(i, http.MethodGet) && interaction.WasSuccessful(i):
 		// Accumulate this successful GET request.
@@ -82,11 +107,29 @@ func (m *MonitorDeletion) Analyze(
 	}
 }
 
+// Finish handles the end of the recording before the deletion was confirmed.
+func (m *MonitorDeletion) Finish(_ context.Context, log *slog.Logger) (analyzer.Result, error) {
+	log.Debug(
+		"Recording ended before DELETE was confirmed",
+		"url", m.baseURL.String(),
+	)
+
+	return m.deletionConfirmed(log, nil)
+}
+
 // deletionConfirmed handles the confirmation of deletion via a 404 GET response.
+// confirmation is the GET returning 404, or nil if the recording ended first.
 func (m *MonitorDeletion) deletionConfirmed(
 	log *slog.Logger,
+	confirmation interaction.Interface,
 ) (analyzer.Result, error) {
-	if len(m.interactions) < 2 {
+	retained, excluded := m.retention.Apply(m.interactions)
+	if m.zeroDelays {
+		delays.Zero(m.trigger, confirmation)
+		delays.Zero(retained...)
+	}
+
+	if len(excluded) == 0 {
 		// No intermediate interactions to exclude.
 		log.Debug(
 			"Short DELETE monitor, nothing to exclude",
@@ -99,10 +142,12 @@ func (m *MonitorDeletion) deletionConfirmed(
 	log.Debug(
 		"Long DELETE found, excluding intermediate GETs",
 		"url", m.baseURL.String(),
-		"removed", len(m.interactions)-2,
+		"removed", len(excluded),
 	)
 
-	excluded := m.interactions[1 : len(m.interactions)-1]
+	provenance := analyzer.Because(
+		m.baseURL.String(),
+		"intermediate GET while waiting for deletion to complete")
 
-	return analyzer.FinishedWithExclusions(excluded...), nil
+	return analyzer.FinishedWithExclusions(provenance, excluded...), nil
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/fake"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/must"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/retention"
 )
 
 func TestMonitorDeletion_SingleGETReturning404_MarksFinished(t *testing.T) {
@@ -19,13 +20,13 @@ func TestMonitorDeletion_SingleGETReturning404_MarksFinished(t *testing.T) {
 
 	g := NewWithT(t)
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
-	monitor := NewMonitorDeletion(baseURL)
+	monitor := NewMonitorDeletion(fake.Interaction(baseURL, http.MethodDelete, http.StatusOK))
 	log := slogt.New(t)
 
 	// Single GET returning 404 should finish immediately
 	i := fake.Interaction(baseURL, http.MethodGet, 404)
 
-	result, err := monitor.Analyze(log, i)
+	result, err := monitor.Analyze(t.Context(), log, i)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeTrue())
@@ -37,7 +38,7 @@ func TestMonitorDeletion_TwoGETsThenConfirmation_NothingIsRemoved(t *testing.T)
 
 	g := NewWithT(t)
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
-	monitor := NewMonitorDeletion(baseURL)
+	monitor := NewMonitorDeletion(fake.Interaction(baseURL, http.MethodDelete, http.StatusOK))
 	log := slogt.New(t)
 
 	// Two successful GETs followed by 404
@@ -57,7 +58,7 @@ func TestMonitorDeletion_ThreeGETsThenConfirmation_MiddleIsRemoved(t *testing.T)
 
 	g := NewWithT(t)
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
-	monitor := NewMonitorDeletion(baseURL)
+	monitor := NewMonitorDeletion(fake.Interaction(baseURL, http.MethodDelete, http.StatusOK))
 	log := slogt.New(t)
 
 	// Three successful GETs followed by 404
@@ -72,6 +73,8 @@ func TestMonitorDeletion_ThreeGETsThenConfirmation_MiddleIsRemoved(t *testing.T)
 	// First and last accumulated should remain, middle should be excluded
 	g.Expect(result.Excluded).To(HaveLen(1))
 	g.Expect(result.Excluded).To(ContainElement(get2))
+	g.Expect(result.Provenance.URL).To(Equal(baseURL.String()))
+	g.Expect(result.Provenance.Reason).ToNot(BeEmpty())
 }
 
 func TestMonitorDeletion_MultipleMiddleGETs_AllMiddleAreRemoved(t *testing.T) {
@@ -79,7 +82,7 @@ func TestMonitorDeletion_MultipleMiddleGETs_AllMiddleAreRemoved(t *testing.T) {
 	g := NewWithT(t)
 
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
-	monitor := NewMonitorDeletion(baseURL)
+	monitor := NewMonitorDeletion(fake.Interaction(baseURL, http.MethodDelete, http.StatusOK))
 	log := slogt.New(t)
 
 	// Many successful GETs followed by 404
@@ -110,12 +113,12 @@ func TestMonitorDeletion_DifferentURL_Ignored(t *testing.T) {
 
 	monitoredURL := must.ParseURL(t, "https://api.example.com/resource/123")
 	differentURL := must.ParseURL(t, "https://api.example.com/resource/456")
-	monitor := NewMonitorDeletion(monitoredURL)
+	monitor := NewMonitorDeletion(fake.Interaction(monitoredURL, http.MethodDelete, http.StatusOK))
 	log := slogt.New(t)
 
 	i := fake.Interaction(differentURL, http.MethodGet, 200)
 
-	result, err := monitor.Analyze(log, i)
+	result, err := monitor.Analyze(t.Context(), log, i)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeFalse())
@@ -141,7 +144,7 @@ func TestMonitorDeletion_AbandonsMonitoring(t *testing.T) {
 			g := NewWithT(t)
 
 			baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
-			monitor := NewMonitorDeletion(baseURL)
+			monitor := NewMonitorDeletion(fake.Interaction(baseURL, http.MethodDelete, http.StatusOK))
 			log := slogt.New(t)
 
 			// Start with some successful GETs
@@ -161,7 +164,7 @@ func TestMonitorDeletion_Various2xxStatusCodes_Accumulated(t *testing.T) {
 	g := NewWithT(t)
 
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
-	monitor := NewMonitorDeletion(baseURL)
+	monitor := NewMonitorDeletion(fake.Interaction(baseURL, http.MethodDelete, http.StatusOK))
 	log := slogt.New(t)
 
 	// Test various 2xx status codes
@@ -192,12 +195,12 @@ func TestMonitorDeletion_URLWithQueryParameters_MonitorsBaseURL(t *testing.T) {
 
 	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
 	urlWithParams := must.ParseURL(t, "https://api.example.com/resource/123?param=value")
-	monitor := NewMonitorDeletion(baseURL)
+	monitor := NewMonitorDeletion(fake.Interaction(baseURL, http.MethodDelete, http.StatusOK))
 	log := slogt.New(t)
 
 	// Interaction with query parameters should match base URL
 	i := fake.Interaction(urlWithParams, http.MethodGet, 200)
-	result, err := monitor.Analyze(log, i)
+	result, err := monitor.Analyze(t.Context(), log, i)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result.Finished).To(BeFalse())
@@ -209,12 +212,93 @@ func TestMonitorDeletion_EmptyResult_WhenIgnoringInteraction(t *testing.T) {
 
 	monitoredURL := must.ParseURL(t, "https://api.example.com/resource/123")
 	differentURL := must.ParseURL(t, "https://api.example.com/other")
-	monitor := NewMonitorDeletion(monitoredURL)
+	monitor := NewMonitorDeletion(fake.Interaction(monitoredURL, http.MethodDelete, http.StatusOK))
 	log := slogt.New(t)
 
 	i := fake.Interaction(differentURL, http.MethodGet, 200)
-	result, err := monitor.Analyze(log, i)
+	result, err := monitor.Analyze(t.Context(), log, i)
 
 	g.Expect(err).ToNot(HaveOccurred())
 	g.Expect(result).To(Equal(analyzer.Result{}))
 }
+
+func TestMonitorDeletion_WithRetentionPolicy_RetainsConfiguredGETs(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	monitor := NewMonitorDeletion(fake.Interaction(baseURL, http.MethodDelete, http.StatusOK))
+	monitor.SetRetention(retention.Policy{First: 2, Last: 1, Every: 3})
+	log := slogt.New(t)
+
+	interactions := make([]interaction.Interface, 0, 10)
+
+	for range 9 {
+		interactions = append(interactions, fake.Interaction(baseURL, http.MethodGet, 200))
+	}
+
+	interactions = append(interactions, fake.Interaction(baseURL, http.MethodGet, 404))
+
+	result := runAnalyzer(t, log, monitor, interactions...)
+	g.Expect(result.Finished).To(BeTrue())
+
+	// Retains 0 and 1 (first two), 3 and 6 (every third) and 8 (last)
+	g.Expect(result.Excluded).To(HaveExactElements(
+		interactions[2],
+		interactions[4],
+		interactions[5],
+		interactions[7],
+	))
+}
+
+func TestMonitorDeletion_RecordingEndsBeforeConfirmation_MiddleIsRemoved(t *testing.T) {
+	t.Parallel()
+
+	g := NewWithT(t)
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	monitor := NewMonitorDeletion(fake.Interaction(baseURL, http.MethodDelete, http.StatusOK))
+	log := slogt.New(t)
+
+	// Three successful GETs, but the recording ends before the 404
+	get1 := fake.Interaction(baseURL, http.MethodGet, 200)
+	get2 := fake.Interaction(baseURL, http.MethodGet, 200)
+	get3 := fake.Interaction(baseURL, http.MethodGet, 200)
+
+	result := runAnalyzer(t, log, monitor, get1, get2, get3)
+	g.Expect(result.Finished).To(BeFalse())
+
+	result, err := monitor.Finish(t.Context(), log)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.Excluded).To(ConsistOf(get2))
+	g.Expect(result.Provenance.URL).To(Equal(baseURL.String()))
+}
+
+func TestMonitorDeletion_WithZeroDelays_RewritesRetryAfterAcrossSequence(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	baseURL := must.ParseURL(t, "https://api.example.com/resource/123")
+	deletion := fake.Interaction(baseURL, http.MethodDelete, http.StatusAccepted)
+	monitor := NewMonitorDeletion(deletion)
+	monitor.SetZeroDelays(true)
+	log := slogt.New(t)
+
+	get1 := fake.Interaction(baseURL, http.MethodGet, 200)
+	get2 := fake.Interaction(baseURL, http.MethodGet, 200)
+	get3 := fake.Interaction(baseURL, http.MethodGet, 200)
+	get404 := fake.Interaction(baseURL, http.MethodGet, 404)
+
+	for _, i := range []*fake.TestInteraction{deletion, get1, get2, get3, get404} {
+		i.SetResponseHeader("Retry-After", "10")
+	}
+
+	result := runAnalyzer(t, log, monitor, get1, get2, get3, get404)
+	g.Expect(result.Excluded).To(ConsistOf(get2))
+
+	// Every interaction left in the sequence replays without a delay, including the DELETE and the 404
+	for _, i := range []*fake.TestInteraction{deletion, get1, get3, get404} {
+		retryAfter, ok := i.Response().Header("Retry-After")
+		g.Expect(ok).To(BeTrue())
+		g.Expect(retryAfter).To(Equal("0"), i.String())
+	}
+}
@@ -0,0 +1,349 @@
+package har
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+)
+
+const (
+	// Version is the version of the HAR format written.
+	Version = "1.2"
+	// CreatorName identifies archives written by this tool.
+	CreatorName = "go-vcr-tidy"
+	// base64Encoding marks content whose text is base64 encoded.
+	base64Encoding = "base64"
+)
+
+// New returns an empty HTTP Archive.
+func New() *HAR {
+	return &HAR{
+		Log: Log{
+			Version: Version,
+			Creator: Creator{
+				Name:    CreatorName,
+				Version: creatorVersion(),
+			},
+			Entries: []*Entry{},
+		},
+	}
+}
+
+// creatorVersion returns the version of this tool, as recorded in the build information.
+func creatorVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	return "(devel)"
+}
+
+// FromCassette converts a go-vcr cassette into an HTTP Archive, with one entry per interaction.
+// Cassettes don't record when each request was made, so entries are timestamped from the response Date header where
+// available.
+func FromCassette(cas *cassette.Cassette) *HAR {
+	result := New()
+
+	for _, i := range cas.Interactions {
+		if i.DiscardOnSave {
+			continue
+		}
+
+		result.Log.Entries = append(result.Log.Entries, entryFromInteraction(i))
+	}
+
+	return result
+}
+
+// ToCassette converts the HTTP Archive into a go-vcr cassette with the specified name, with one interaction per entry.
+func (h *HAR) ToCassette(name string) *cassette.Cassette {
+	result := cassette.New(name)
+
+	for index, entry := range h.Log.Entries {
+		i := entry.toInteraction()
+		i.ID = index
+		result.Interactions = append(result.Interactions, i)
+	}
+
+	return result
+}
+
+// entryFromInteraction converts a single cassette interaction into an archive entry.
+func entryFromInteraction(i *cassette.Interaction) *Entry {
+	elapsed := Milliseconds(i.Response.Duration)
+
+	started := time.Time{}
+	if date := i.Response.Headers.Get("Date"); date != "" {
+		if t, err := http.ParseTime(date); err == nil {
+			started = t
+		}
+	}
+
+	result := &Entry{
+		StartedDateTime: started.UTC().Format(time.RFC3339Nano),
+		Time:            elapsed,
+		Request: Request{
+			Method:      i.Request.Method,
+			URL:         i.Request.URL,
+			HTTPVersion: httpVersion(i.Request.Proto),
+			Cookies:     []Cookie{},
+			Headers:     fromHeader(i.Request.Headers),
+			QueryString: queryString(i.Request.URL),
+			HeadersSize: -1,
+			BodySize:    int64(len(i.Request.Body)),
+		},
+		Response: Response{
+			Status:      i.Response.Code,
+			StatusText:  statusText(i.Response.Code, i.Response.Status),
+			HTTPVersion: httpVersion(i.Response.Proto),
+			Cookies:     []Cookie{},
+			Headers:     fromHeader(i.Response.Headers),
+			Content:     content(i.Response.Body, i.Response.Headers.Get("Content-Type")),
+			RedirectURL: i.Response.Headers.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    int64(len(i.Response.Body)),
+		},
+		Timings: Timings{
+			Wait: elapsed,
+		},
+	}
+
+	if i.Request.Body != "" {
+		result.Request.PostData = &PostData{
+			MimeType: i.Request.Headers.Get("Content-Type"),
+			Text:     i.Request.Body,
+		}
+	}
+
+	return result
+}
+
+// toInteraction converts the entry into a cassette interaction; the caller is responsible for assigning an ID.
+func (e *Entry) toInteraction() *cassette.Interaction {
+	requestHeaders := e.Request.Header()
+	responseHeaders := e.Response.Header()
+	requestBody := e.Request.Body()
+	responseBody := string(e.Response.Body())
+	requestMajor, requestMinor := protoVersion(e.Request.HTTPVersion)
+	responseMajor, responseMinor := protoVersion(e.Response.HTTPVersion)
+
+	host := ""
+	if u, err := url.Parse(e.Request.URL); err == nil {
+		host = u.Host
+	}
+
+	return &cassette.Interaction{
+		Request: cassette.Request{
+			Proto:         e.Request.HTTPVersion,
+			ProtoMajor:    requestMajor,
+			ProtoMinor:    requestMinor,
+			ContentLength: int64(len(requestBody)),
+			Host:          host,
+			Body:          requestBody,
+			Headers:       requestHeaders,
+			URL:           e.Request.URL,
+			Method:        e.Request.Method,
+		},
+		Response: cassette.Response{
+			Proto:         e.Response.HTTPVersion,
+			ProtoMajor:    responseMajor,
+			ProtoMinor:    responseMinor,
+			ContentLength: int64(len(responseBody)),
+			Body:          responseBody,
+			Headers:       responseHeaders,
+			Status:        strings.TrimSpace(fmt.Sprintf("%d %s", e.Response.Status, e.Response.StatusText)),
+			Code:          e.Response.Status,
+			Duration:      e.Duration(),
+		},
+	}
+}
+
+// Duration returns the total elapsed time of the entry.
+func (e *Entry) Duration() time.Duration {
+	return time.Duration(e.Time * float64(time.Millisecond))
+}
+
+// Started returns when the request was started.
+// Returns false if the entry has no valid timestamp.
+func (e *Entry) Started() (time.Time, bool) {
+	result, err := time.Parse(time.RFC3339Nano, e.StartedDateTime)
+	if err != nil || result.IsZero() {
+		return time.Time{}, false
+	}
+
+	return result, true
+}
+
+// SetDuration changes the total elapsed time of the entry, scaling the individual timings to match.
+func (e *Entry) SetDuration(duration time.Duration) {
+	elapsed := Milliseconds(duration)
+	if e.Time > 0 {
+		e.Timings.scale(elapsed / e.Time)
+	} else {
+		e.Timings.Wait = elapsed
+	}
+
+	e.Time = elapsed
+}
+
+// scale multiplies each recorded phase by the specified factor; phases that don't apply are left untouched.
+func (t *Timings) scale(factor float64) {
+	// SSL time is already included in Connect time, but still needs scaling to stay consistent
+	for _, phase := range []*float64{t.Blocked, t.DNS, t.Connect, t.SSL, &t.Send, &t.Wait, &t.Receive} {
+		if phase != nil && *phase > 0 {
+			*phase *= factor
+		}
+	}
+}
+
+// Header returns the request headers, excluding HTTP/2 pseudo-headers.
+func (r *Request) Header() http.Header {
+	return toHeader(r.Headers)
+}
+
+// Body returns the body of the request.
+func (r *Request) Body() string {
+	if r.PostData == nil {
+		return ""
+	}
+
+	return r.PostData.Text
+}
+
+// Header returns the response headers, excluding HTTP/2 pseudo-headers.
+func (r *Response) Header() http.Header {
+	return toHeader(r.Headers)
+}
+
+// Body returns the body of the response, decoding it if needed.
+func (r *Response) Body() []byte {
+	if r.Content.Encoding == base64Encoding {
+		decoded, err := base64.StdEncoding.DecodeString(r.Content.Text)
+		if err == nil {
+			return decoded
+		}
+	}
+
+	return []byte(r.Content.Text)
+}
+
+// Milliseconds converts a duration into the fractional milliseconds used by HAR timings.
+func Milliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
+
+// fromHeader converts headers into a list of name/value pairs, sorted by name for stable output.
+func fromHeader(header http.Header) []NameValue {
+	names := slices.Sorted(func(yield func(string) bool) {
+		for name := range header {
+			if !yield(name) {
+				return
+			}
+		}
+	})
+
+	result := make([]NameValue, 0, len(header))
+	for _, name := range names {
+		for _, value := range header[name] {
+			result = append(result, NameValue{Name: name, Value: value})
+		}
+	}
+
+	return result
+}
+
+// toHeader converts a list of name/value pairs into headers.
+func toHeader(pairs []NameValue) http.Header {
+	result := make(http.Header, len(pairs))
+	for _, pair := range pairs {
+		if strings.HasPrefix(pair.Name, ":") {
+			// HTTP/2 pseudo-headers such as :authority aren't real headers
+			continue
+		}
+
+		result.Add(pair.Name, pair.Value)
+	}
+
+	return result
+}
+
+// queryString returns the query parameters of the URL as name/value pairs.
+func queryString(rawURL string) []NameValue {
+	result := []NameValue{}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return result
+	}
+
+	for name, values := range u.Query() {
+		for _, value := range values {
+			result = append(result, NameValue{Name: name, Value: value})
+		}
+	}
+
+	slices.SortStableFunc(result, func(left NameValue, right NameValue) int {
+		return strings.Compare(left.Name, right.Name)
+	})
+
+	return result
+}
+
+// content describes a response body, base64 encoding it if it isn't valid text.
+func content(body string, mimeType string) Content {
+	result := Content{
+		Size:     int64(len(body)),
+		MimeType: mimeType,
+		Text:     body,
+	}
+
+	if !utf8.ValidString(body) {
+		result.Text = base64.StdEncoding.EncodeToString([]byte(body))
+		result.Encoding = base64Encoding
+	}
+
+	return result
+}
+
+// statusText returns the reason phrase from a status line such as "200 OK".
+func statusText(code int, status string) string {
+	result := strings.TrimSpace(strings.TrimPrefix(status, strconv.Itoa(code)))
+	if result == "" {
+		return http.StatusText(code)
+	}
+
+	return result
+}
+
+// httpVersion returns the protocol version, defaulting to HTTP/1.1 if not recorded.
+func httpVersion(proto string) string {
+	if proto == "" {
+		return "HTTP/1.1"
+	}
+
+	return proto
+}
+
+// protoVersion parses the major and minor versions from a protocol such as "HTTP/1.1".
+func protoVersion(proto string) (int, int) {
+	// Browsers often record HTTP/2 without a minor version, which the standard parser rejects
+	if strings.EqualFold(proto, "h2") || strings.EqualFold(proto, "HTTP/2") {
+		return 2, 0
+	}
+
+	major, minor, ok := http.ParseHTTPVersion(strings.ToUpper(proto))
+	if !ok {
+		return 1, 1
+	}
+
+	return major, minor
+}
@@ -0,0 +1,204 @@
+package har
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+)
+
+func TestFromCassette_GivenInteraction_ConvertsEntry(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	cas := sampleCassette()
+	archive := FromCassette(cas)
+
+	g.Expect(archive.Log.Version).To(Equal(Version))
+	g.Expect(archive.Log.Creator.Name).To(Equal(CreatorName))
+	g.Expect(archive.Log.Entries).To(HaveLen(1))
+
+	entry := archive.Log.Entries[0]
+	g.Expect(entry.Request.Method).To(Equal(http.MethodPut))
+	g.Expect(entry.Request.URL).To(Equal("https://api.example.com/widgets/alpha?version=2"))
+	g.Expect(entry.Request.QueryString).To(ConsistOf(NameValue{Name: "version", Value: "2"}))
+	g.Expect(entry.Request.PostData).ToNot(BeNil())
+	g.Expect(entry.Request.PostData.Text).To(Equal(`{"size":3}`))
+	g.Expect(entry.Response.Status).To(Equal(http.StatusCreated))
+	g.Expect(entry.Response.StatusText).To(Equal("Created"))
+	g.Expect(entry.Response.Content.Text).To(Equal(`{"name":"alpha"}`))
+	g.Expect(entry.Response.Headers).To(ContainElement(NameValue{Name: "Retry-After", Value: "5"}))
+	g.Expect(entry.Time).To(Equal(1500.0))
+	g.Expect(entry.StartedDateTime).To(Equal("2024-01-02T03:04:05Z"))
+}
+
+func TestFromCassette_GivenDiscardedInteraction_OmitsEntry(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	cas := sampleCassette()
+	cas.Interactions[0].DiscardOnSave = true
+
+	archive := FromCassette(cas)
+
+	g.Expect(archive.Log.Entries).To(BeEmpty())
+}
+
+func TestFromCassette_GivenBinaryBody_EncodesAsBase64(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	cas := sampleCassette()
+	cas.Interactions[0].Response.Body = "\xff\xfe\x00\x01"
+
+	archive := FromCassette(cas)
+	content := archive.Log.Entries[0].Response.Content
+
+	g.Expect(content.Encoding).To(Equal("base64"))
+	g.Expect(archive.Log.Entries[0].Response.Body()).To(Equal([]byte("\xff\xfe\x00\x01")))
+}
+
+func TestToCassette_GivenArchive_RoundTripsInteraction(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	original := sampleCassette().Interactions[0]
+	cas := FromCassette(sampleCassette()).ToCassette("converted")
+
+	g.Expect(cas.Interactions).To(HaveLen(1))
+
+	i := cas.Interactions[0]
+	g.Expect(i.ID).To(Equal(0))
+	g.Expect(i.Request.Method).To(Equal(original.Request.Method))
+	g.Expect(i.Request.URL).To(Equal(original.Request.URL))
+	g.Expect(i.Request.Host).To(Equal("api.example.com"))
+	g.Expect(i.Request.Body).To(Equal(original.Request.Body))
+	g.Expect(i.Request.Headers).To(Equal(original.Request.Headers))
+	g.Expect(i.Response.Code).To(Equal(original.Response.Code))
+	g.Expect(i.Response.Status).To(Equal(original.Response.Status))
+	g.Expect(i.Response.Body).To(Equal(original.Response.Body))
+	g.Expect(i.Response.Headers).To(Equal(original.Response.Headers))
+	g.Expect(i.Response.Duration).To(Equal(original.Response.Duration))
+	g.Expect(i.Response.ProtoMajor).To(Equal(1))
+	g.Expect(i.Response.ProtoMinor).To(Equal(1))
+}
+
+func TestToCassette_GivenPseudoHeaders_OmitsThem(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	archive := New()
+	archive.Log.Entries = append(archive.Log.Entries, &Entry{
+		Request: Request{
+			Method:      http.MethodGet,
+			URL:         "https://api.example.com/widgets",
+			HTTPVersion: "h2",
+			Headers: []NameValue{
+				{Name: ":authority", Value: "api.example.com"},
+				{Name: "accept", Value: "application/json"},
+			},
+		},
+		Response: Response{
+			Status: http.StatusOK,
+		},
+	})
+
+	i := archive.ToCassette("converted").Interactions[0]
+
+	g.Expect(i.Request.Headers).To(Equal(http.Header{"Accept": {"application/json"}}))
+	g.Expect(i.Request.ProtoMajor).To(Equal(2))
+	g.Expect(i.Response.Status).To(Equal("200"))
+}
+
+func TestEntrySetDuration_GivenTimings_ScalesPhases(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	connect := 40.0
+	entry := &Entry{
+		Time: 200,
+		Timings: Timings{
+			Connect: &connect,
+			Send:    10,
+			Wait:    140,
+			Receive: 10,
+		},
+	}
+
+	entry.SetDuration(50 * time.Millisecond)
+
+	g.Expect(entry.Time).To(Equal(50.0))
+	g.Expect(*entry.Timings.Connect).To(Equal(10.0))
+	g.Expect(entry.Timings.Send).To(Equal(2.5))
+	g.Expect(entry.Timings.Wait).To(Equal(35.0))
+	g.Expect(entry.Timings.Receive).To(Equal(2.5))
+	g.Expect(entry.Duration()).To(Equal(50 * time.Millisecond))
+}
+
+func TestEntryStarted(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		started  string
+		expected time.Time
+		ok       bool
+	}{
+		"Valid": {
+			started:  "2024-01-02T03:04:05.500+01:00",
+			expected: time.Date(2024, 1, 2, 2, 4, 5, 500*int(time.Millisecond), time.UTC),
+			ok:       true,
+		},
+		"Zero": {
+			started: "0001-01-01T00:00:00Z",
+		},
+		"Invalid": {
+			started: "yesterday",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			entry := &Entry{StartedDateTime: c.started}
+			started, ok := entry.Started()
+
+			g.Expect(ok).To(Equal(c.ok))
+			g.Expect(started.Equal(c.expected)).To(BeTrue())
+		})
+	}
+}
+
+// sampleCassette returns a cassette containing a single interaction exercising most fields.
+func sampleCassette() *cassette.Cassette {
+	result := cassette.New("sample")
+	result.Interactions = append(result.Interactions, &cassette.Interaction{
+		Request: cassette.Request{
+			Proto:  "HTTP/1.1",
+			Method: http.MethodPut,
+			URL:    "https://api.example.com/widgets/alpha?version=2",
+			Body:   `{"size":3}`,
+			Headers: http.Header{
+				"Content-Type": {"application/json"},
+			},
+		},
+		Response: cassette.Response{
+			Proto:  "HTTP/1.1",
+			Code:   http.StatusCreated,
+			Status: "201 Created",
+			Body:   `{"name":"alpha"}`,
+			Headers: http.Header{
+				"Content-Type": {"application/json"},
+				"Date":         {"Tue, 02 Jan 2024 03:04:05 GMT"},
+				"Retry-After":  {"5"},
+			},
+			Duration: 1500 * time.Millisecond,
+		},
+	})
+
+	return result
+}
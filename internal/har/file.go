@@ -0,0 +1,83 @@
+package har
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/atomicfile"
+)
+
+// Extension is the file extension used for HTTP Archive files.
+const Extension = ".har"
+
+// IsHAR returns true if the path names an HTTP Archive file, based on its extension.
+func IsHAR(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), Extension)
+}
+
+// Load reads the HTTP Archive at the specified path.
+// Returns an error if the file can't be read, isn't an HTTP Archive, or has an entry with an invalid request URL.
+func Load(path string) (*HAR, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, eris.Wrapf(err, "reading HAR file %s", path)
+	}
+
+	var result HAR
+
+	err = json.Unmarshal(content, &result)
+	if err != nil {
+		return nil, eris.Wrapf(err, "parsing HAR file %s", path)
+	}
+
+	if result.Log.Version == "" && result.Log.Entries == nil {
+		return nil, eris.Errorf("file %s is not an HTTP Archive, no log found", path)
+	}
+
+	err = result.validate()
+	if err != nil {
+		return nil, eris.Wrapf(err, "validating HAR file %s", path)
+	}
+
+	return &result, nil
+}
+
+// validate checks that every entry has a request URL that can be parsed, returning an error naming the first that
+// doesn't.
+func (h *HAR) validate() error {
+	for index, entry := range h.Log.Entries {
+		if _, err := url.Parse(entry.Request.URL); err != nil {
+			return eris.Wrapf(err, "entry %d has an invalid request URL", index)
+		}
+	}
+
+	return nil
+}
+
+// Save writes the HTTP Archive to the specified path as indented JSON, replacing any existing file atomically.
+func (h *HAR) Save(path string) error {
+	var buffer bytes.Buffer
+
+	// Bodies often contain markup; escaping it would make them harder to read
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(h)
+	if err != nil {
+		return eris.Wrap(err, "encoding HAR")
+	}
+
+	err = atomicfile.WriteFile(path, buffer.Bytes())
+	if err != nil {
+		return eris.Wrapf(err, "writing HAR file %s", path)
+	}
+
+	return nil
+}
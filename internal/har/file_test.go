@@ -0,0 +1,89 @@
+package har
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestIsHAR(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]bool{
+		"recording.har":     true,
+		"dir/Recording.HAR": true,
+		"recording.yaml":    false,
+		"recording.har.bak": false,
+		"har":               false,
+	}
+
+	for path, expected := range cases {
+		t.Run(path, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			g.Expect(IsHAR(path)).To(Equal(expected))
+		})
+	}
+}
+
+func TestSave_ThenLoad_RoundTripsArchive(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "sample.har")
+	archive := FromCassette(sampleCassette())
+
+	g.Expect(archive.Save(path)).To(Succeed())
+
+	loaded, err := Load(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(loaded).To(Equal(archive))
+}
+
+func TestSave_GivenMarkupInBody_DoesNotEscapeIt(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	cas := sampleCassette()
+	cas.Interactions[0].Response.Body = "<p>a & b</p>"
+
+	path := filepath.Join(t.TempDir(), "sample.har")
+	g.Expect(FromCassette(cas).Save(path)).To(Succeed())
+
+	content, err := os.ReadFile(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(content)).To(ContainSubstring(`"text": "<p>a & b</p>"`))
+}
+
+func TestLoad_GivenNonArchive_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "other.har")
+	g.Expect(os.WriteFile(path, []byte(`{"name":"not an archive"}`), 0o600)).To(Succeed())
+
+	_, err := Load(path)
+
+	g.Expect(err).To(MatchError(ContainSubstring("is not an HTTP Archive")))
+}
+
+func TestLoad_GivenEntryWithInvalidURL_ReturnsErrorNamingEntry(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	archive := New()
+	archive.Log.Entries = []*Entry{
+		{Request: Request{Method: "GET", URL: "https://example.com/valid"}},
+		{Request: Request{Method: "GET", URL: "https://example.com/%zz"}},
+	}
+
+	path := filepath.Join(t.TempDir(), "invalid.har")
+	g.Expect(archive.Save(path)).To(Succeed())
+
+	_, err := Load(path)
+
+	g.Expect(err).To(MatchError(ContainSubstring("entry 1 has an invalid request URL")))
+}
@@ -1,9 +1,13 @@
 package interaction
 
 import (
+	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/jsondoc"
 )
 
 // Interface is an abstract representation of an HTTP request/response pair.
@@ -28,6 +32,12 @@ type Request interface {
 	BaseURL() *url.URL
 	// The HTTP method of the request, e.g. "GET", "POST", etc.
 	Method() string
+	// Header returns the first value of the specified request header.
+	Header(name string) (string, bool)
+	// Headers returns a copy of all the request headers.
+	Headers() http.Header
+	// Body returns the body of the request.
+	Body() []byte
 }
 
 // Response is an abstract representation of an HTTP response.
@@ -45,4 +55,15 @@ type Response interface {
 	RemoveHeader(name string)
 	// Body returns the body of the response.
 	Body() []byte
+	// Duration returns how long the server took to respond when the interaction was recorded.
+	Duration() time.Duration
+	// SetDuration changes the recorded duration of the response.
+	// Replays using go-vcr latency simulation wait for this long before returning the response.
+	SetDuration(duration time.Duration)
+	// Timestamp returns when the response was sent, taken from its Date header, if present.
+	// go-vcr doesn't record when each request was made, so this is the closest approximation available.
+	Timestamp() (time.Time, bool)
+	// JSON returns the body of the response parsed as JSON.
+	// The document is parsed on first use and cached, so all analyzers share a single parse.
+	JSON() *jsondoc.Document
 }
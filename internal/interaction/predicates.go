@@ -2,7 +2,10 @@ package interaction
 
 // Predicates to make common checks on interactions.
 
-import "slices"
+import (
+	"bytes"
+	"slices"
+)
 
 // HasMethod checks if the interaction uses the specified HTTP method.
 func HasMethod(
@@ -28,3 +31,49 @@ func WasSuccessful(
 
 	return statusCode >= 200 && statusCode < 300
 }
+
+// HasRequestHeader checks if the interaction's request includes the specified header.
+func HasRequestHeader(
+	i Interface,
+	name string,
+) bool {
+	_, ok := i.Request().Header(name)
+
+	return ok
+}
+
+// HasRequestHeaderValue checks if the interaction's request header has exactly the specified value.
+func HasRequestHeaderValue(
+	i Interface,
+	name string,
+	value string,
+) bool {
+	actual, ok := i.Request().Header(name)
+
+	return ok && actual == value
+}
+
+// ShareRequestHeader checks if both interactions have the same non-empty value for the specified request header.
+// Useful for identifying retries of the same logical request, e.g. via x-ms-client-request-id.
+func ShareRequestHeader(
+	left Interface,
+	right Interface,
+	name string,
+) bool {
+	l, ok := left.Request().Header(name)
+	if !ok || l == "" {
+		return false
+	}
+
+	r, ok := right.Request().Header(name)
+
+	return ok && l == r
+}
+
+// RequestBodyContains checks if the interaction's request body contains the specified fragment.
+func RequestBodyContains(
+	i Interface,
+	fragment string,
+) bool {
+	return bytes.Contains(i.Request().Body(), []byte(fragment))
+}
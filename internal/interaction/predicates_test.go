@@ -158,3 +158,181 @@ func TestWasSuccessful(t *testing.T) {
 		})
 	}
 }
+
+// HasRequestHeader Tests
+
+func TestHasRequestHeader(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		header   string
+		expected bool
+	}{
+		"WithExactName_ReturnsTrue": {
+			header:   "X-Ms-Client-Request-Id",
+			expected: true,
+		},
+		"WithDifferentCase_ReturnsTrue": {
+			header:   "x-ms-client-request-id",
+			expected: true,
+		},
+		"WithMissingHeader_ReturnsFalse": {
+			header:   "Authorization",
+			expected: false,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			baseURL := must.ParseURL(t, "https://example.com/resource")
+			i := fake.Interaction(baseURL, http.MethodGet, 200)
+			i.SetRequestHeader("x-ms-client-request-id", "abc")
+
+			result := interaction.HasRequestHeader(i, c.header)
+
+			g.Expect(result).To(Equal(c.expected))
+		})
+	}
+}
+
+// HasRequestHeaderValue Tests
+
+func TestHasRequestHeaderValue(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		header   string
+		value    string
+		expected bool
+	}{
+		"WithMatchingValue_ReturnsTrue": {
+			header:   "Content-Type",
+			value:    "application/json",
+			expected: true,
+		},
+		"WithDifferentValue_ReturnsFalse": {
+			header:   "Content-Type",
+			value:    "text/plain",
+			expected: false,
+		},
+		"WithMissingHeader_ReturnsFalse": {
+			header:   "Accept",
+			value:    "application/json",
+			expected: false,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			baseURL := must.ParseURL(t, "https://example.com/resource")
+			i := fake.Interaction(baseURL, http.MethodPost, 200)
+			i.SetRequestHeader("Content-Type", "application/json")
+
+			result := interaction.HasRequestHeaderValue(i, c.header, c.value)
+
+			g.Expect(result).To(Equal(c.expected))
+		})
+	}
+}
+
+// ShareRequestHeader Tests
+
+func TestShareRequestHeader(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		left     string
+		right    string
+		expected bool
+	}{
+		"WithSameValue_ReturnsTrue": {
+			left:     "abc",
+			right:    "abc",
+			expected: true,
+		},
+		"WithDifferentValues_ReturnsFalse": {
+			left:     "abc",
+			right:    "def",
+			expected: false,
+		},
+		"WithBothMissing_ReturnsFalse": {
+			expected: false,
+		},
+		"WithRightMissing_ReturnsFalse": {
+			left:     "abc",
+			expected: false,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			baseURL := must.ParseURL(t, "https://example.com/resource")
+			left := fake.Interaction(baseURL, http.MethodPut, 500)
+			right := fake.Interaction(baseURL, http.MethodPut, 200)
+
+			if c.left != "" {
+				left.SetRequestHeader("x-ms-client-request-id", c.left)
+			}
+
+			if c.right != "" {
+				right.SetRequestHeader("x-ms-client-request-id", c.right)
+			}
+
+			result := interaction.ShareRequestHeader(left, right, "x-ms-client-request-id")
+
+			g.Expect(result).To(Equal(c.expected))
+		})
+	}
+}
+
+// RequestBodyContains Tests
+
+func TestRequestBodyContains(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		body     string
+		fragment string
+		expected bool
+	}{
+		"WithMatchingFragment_ReturnsTrue": {
+			body:     "Action=DescribeInstances&Version=2016-11-15",
+			fragment: "Action=DescribeInstances",
+			expected: true,
+		},
+		"WithMissingFragment_ReturnsFalse": {
+			body:     "Action=DescribeInstances&Version=2016-11-15",
+			fragment: "Action=RunInstances",
+			expected: false,
+		},
+		"WithEmptyBody_ReturnsFalse": {
+			body:     "",
+			fragment: "query",
+			expected: false,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			baseURL := must.ParseURL(t, "https://example.com/resource")
+			i := fake.Interaction(baseURL, http.MethodPost, 200)
+			i.SetRequestBody(c.body)
+
+			result := interaction.RequestBodyContains(i, c.fragment)
+
+			g.Expect(result).To(Equal(c.expected))
+		})
+	}
+}
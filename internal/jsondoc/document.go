@@ -0,0 +1,95 @@
+package jsondoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Document is a parsed JSON document that can be queried with simple dotted paths such as
+// "properties.provisioningState", "status" or "error.code".
+// Numeric path segments index into arrays, e.g. "value.0.name".
+// A Document is immutable once parsed, so it is safe to share between analyzers.
+type Document struct {
+	root  any
+	valid bool
+}
+
+// Parse parses the body as JSON.
+// A body that isn't valid JSON yields a Document for which Valid returns false and every lookup fails.
+func Parse(body []byte) *Document {
+	var root any
+
+	err := json.Unmarshal(body, &root)
+	if err != nil {
+		return &Document{}
+	}
+
+	return &Document{
+		root:  root,
+		valid: true,
+	}
+}
+
+// Valid returns true if the body was parsed successfully as JSON.
+func (d *Document) Valid() bool {
+	return d.valid
+}
+
+// Lookup finds the value at the specified path.
+// Returns false if the document isn't valid or the path doesn't exist.
+// Objects are returned as map[string]any, arrays as []any, and numbers as float64.
+func (d *Document) Lookup(path string) (any, bool) {
+	if !d.valid {
+		return nil, false
+	}
+
+	current := d.root
+	if path == "" {
+		return current, true
+	}
+
+	for step := range strings.SplitSeq(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[step]
+			if !ok {
+				return nil, false
+			}
+
+			current = value
+
+		case []any:
+			index, err := strconv.Atoi(step)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+
+			current = node[index]
+
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// Text finds the scalar value at the specified path and renders it as a string.
+// Returns false if the path doesn't exist, or if the value is an object, an array or null.
+func (d *Document) Text(path string) (string, bool) {
+	value, ok := d.Lookup(path)
+	if !ok {
+		return "", false
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64, bool:
+		return fmt.Sprint(v), true
+	default:
+		return "", false
+	}
+}
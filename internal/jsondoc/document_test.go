@@ -0,0 +1,126 @@
+package jsondoc
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDocumentText(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		body     string
+		path     string
+		expected string
+		found    bool
+	}{
+		"TopLevelString": {
+			body:     `{"state":"Running"}`,
+			path:     "state",
+			expected: "Running",
+			found:    true,
+		},
+		"NestedString": {
+			body:     `{"properties":{"provisioningState":"Creating"}}`,
+			path:     "properties.provisioningState",
+			expected: "Creating",
+			found:    true,
+		},
+		"Number": {
+			body:     `{"progress":{"percent":50}}`,
+			path:     "progress.percent",
+			expected: "50",
+			found:    true,
+		},
+		"Boolean": {
+			body:     `{"done":false}`,
+			path:     "done",
+			expected: "false",
+			found:    true,
+		},
+		"Object": {
+			body: `{"status":{"phase":"Ready"}}`,
+			path: "status",
+		},
+		"Missing": {
+			body: `{"status":{"phase":"Ready"}}`,
+			path: "status.reason",
+		},
+		"ThroughScalar": {
+			body: `{"status":"Ready"}`,
+			path: "status.phase",
+		},
+		"ArrayIndex": {
+			body:     `{"value":[{"name":"first"},{"name":"second"}]}`,
+			path:     "value.1.name",
+			expected: "second",
+			found:    true,
+		},
+		"ArrayIndexOutOfRange": {
+			body: `{"value":[{"name":"first"}]}`,
+			path: "value.1.name",
+		},
+		"Null": {
+			body: `{"error":null}`,
+			path: "error",
+		},
+		"InvalidJSON": {
+			body: `not json`,
+			path: "status",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			value, found := Parse([]byte(c.body)).Text(c.path)
+
+			g.Expect(found).To(Equal(c.found))
+			g.Expect(value).To(Equal(c.expected))
+		})
+	}
+}
+
+func TestDocumentValid(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		body     string
+		expected bool
+	}{
+		"Object": {
+			body:     `{"status":"Succeeded"}`,
+			expected: true,
+		},
+		"Empty": {
+			body:     ``,
+			expected: false,
+		},
+		"Text": {
+			body:     `Accepted`,
+			expected: false,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			g.Expect(Parse([]byte(c.body)).Valid()).To(Equal(c.expected))
+		})
+	}
+}
+
+func TestDocumentLookup_WithEmptyPath_ReturnsRoot(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	value, found := Parse([]byte(`["a","b"]`)).Lookup("")
+
+	g.Expect(found).To(BeTrue())
+	g.Expect(value).To(Equal([]any{"a", "b"}))
+}
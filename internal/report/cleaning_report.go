@@ -0,0 +1,177 @@
+package report
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/atomicfile"
+)
+
+// CassetteSummary captures the effect of cleaning a single cassette.
+type CassetteSummary struct {
+	Path               string         // Path to the cassette file
+	InteractionsBefore int            // Number of interactions before cleaning
+	InteractionsAfter  int            // Number of interactions after cleaning
+	BytesBefore        int64          // Size of the cassette file before cleaning
+	BytesAfter         int64          // Size of the cassette file after cleaning
+	DurationBefore     time.Duration  // Total recorded duration of interactions before cleaning
+	DurationAfter      time.Duration  // Total recorded duration of interactions after cleaning
+	RemovedByStrategy  map[string]int // Number of interactions removed, keyed by cleaning strategy
+	// Recorded duration of the interactions removed, keyed by cleaning strategy
+	RemovedDurationByStrategy map[string]time.Duration
+}
+
+// CleaningReport accumulates summaries of cleaned cassettes, for rendering as a Markdown table.
+type CleaningReport struct {
+	summaries []CassetteSummary
+	omitSizes bool // Whether file sizes are left out, as when no files are rewritten
+}
+
+// NewCleaningReport returns a new, empty, CleaningReport.
+func NewCleaningReport() *CleaningReport {
+	return &CleaningReport{}
+}
+
+// OmitSizes leaves the sizes of cassette files out of the report, as when cleaning is only planned and no files are
+// rewritten, so the size after cleaning isn't known.
+func (r *CleaningReport) OmitSizes() {
+	r.omitSizes = true
+}
+
+// Add includes the summary of another cassette in the report.
+func (r *CleaningReport) Add(summary CassetteSummary) {
+	r.summaries = append(r.summaries, summary)
+}
+
+// WriteTo renders the report as a Markdown table into the specified buffer.
+// Cassettes are listed in path order, with one column of removals for each strategy, followed by a totals row.
+// Recorded durations show how long a replay simulating latency would spend waiting; each strategy's column includes
+// the recorded duration of the interactions it removed.
+func (r *CleaningReport) WriteTo(buffer *strings.Builder) {
+	strategies := r.strategies()
+
+	headers := []string{
+		"Cassette",
+		"Interactions Before",
+		"Interactions After",
+	}
+
+	if !r.omitSizes {
+		headers = append(headers, "Bytes Before", "Bytes After")
+	}
+
+	headers = append(headers, "Duration Before", "Duration After")
+	headers = append(headers, strategies...)
+	tbl := NewMarkdownTable(headers...)
+
+	summaries := slices.Clone(r.summaries)
+	slices.SortFunc(summaries, func(left CassetteSummary, right CassetteSummary) int {
+		return strings.Compare(left.Path, right.Path)
+	})
+
+	total := CassetteSummary{
+		Path:                      "**Total**",
+		RemovedByStrategy:         make(map[string]int),
+		RemovedDurationByStrategy: make(map[string]time.Duration),
+	}
+
+	for _, s := range summaries {
+		tbl.AddRow(r.row(s, strategies)...)
+
+		total.InteractionsBefore += s.InteractionsBefore
+		total.InteractionsAfter += s.InteractionsAfter
+		total.BytesBefore += s.BytesBefore
+		total.BytesAfter += s.BytesAfter
+		total.DurationBefore += s.DurationBefore
+		total.DurationAfter += s.DurationAfter
+
+		for strategy, count := range s.RemovedByStrategy {
+			total.RemovedByStrategy[strategy] += count
+		}
+
+		for strategy, duration := range s.RemovedDurationByStrategy {
+			total.RemovedDurationByStrategy[strategy] += duration
+		}
+	}
+
+	tbl.AddRow(r.row(total, strategies)...)
+	tbl.WriteTo(buffer)
+}
+
+// SaveTo writes the report to the specified file, replacing any existing file atomically.
+func (r *CleaningReport) SaveTo(path string) error {
+	var builder strings.Builder
+	r.WriteTo(&builder)
+
+	err := atomicfile.WriteFile(path, []byte(builder.String()))
+	if err != nil {
+		return eris.Wrapf(err, "writing report to %s", path)
+	}
+
+	return nil
+}
+
+// strategies returns the names of all strategies that removed interactions, in alphabetical order.
+func (r *CleaningReport) strategies() []string {
+	set := make(map[string]struct{})
+
+	for _, s := range r.summaries {
+		for strategy := range s.RemovedByStrategy {
+			set[strategy] = struct{}{}
+		}
+	}
+
+	return slices.Sorted(maps.Keys(set))
+}
+
+// row returns the table row for a single summary.
+func (r *CleaningReport) row(
+	summary CassetteSummary,
+	strategies []string,
+) []string {
+	result := []string{
+		summary.Path,
+		strconv.Itoa(summary.InteractionsBefore),
+		strconv.Itoa(summary.InteractionsAfter),
+	}
+
+	if !r.omitSizes {
+		result = append(
+			result,
+			strconv.FormatInt(summary.BytesBefore, 10),
+			strconv.FormatInt(summary.BytesAfter, 10))
+	}
+
+	result = append(
+		result,
+		formatDuration(summary.DurationBefore),
+		formatDuration(summary.DurationAfter))
+
+	for _, strategy := range strategies {
+		cell := strconv.Itoa(summary.RemovedByStrategy[strategy])
+		if duration := summary.RemovedDurationByStrategy[strategy]; duration > 0 {
+			cell += " (" + formatDuration(duration) + ")"
+		}
+
+		result = append(result, cell)
+	}
+
+	return result
+}
+
+// formatDuration renders a duration for display, rounded to a precision appropriate for its size.
+func formatDuration(duration time.Duration) string {
+	switch {
+	case duration >= time.Minute:
+		return duration.Round(time.Second).String()
+	case duration >= time.Second:
+		return duration.Round(100 * time.Millisecond).String()
+	default:
+		return duration.Round(time.Millisecond).String()
+	}
+}
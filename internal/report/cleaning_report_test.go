@@ -0,0 +1,112 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/sebdah/goldie/v2"
+)
+
+func TestCleaningReport_GivesExpectedResults(t *testing.T) {
+	t.Parallel()
+	g := goldie.New(t)
+
+	rpt := NewCleaningReport()
+	rpt.Add(CassetteSummary{
+		Path:               "testdata/recordings/zeta.yaml",
+		InteractionsBefore: 40,
+		InteractionsAfter:  25,
+		BytesBefore:        120000,
+		BytesAfter:         76000,
+		DurationBefore:     15 * time.Minute,
+		DurationAfter:      75 * time.Second,
+		RemovedByStrategy: map[string]int{
+			"azure-long-running-operation": 10,
+			"deletion":                     5,
+		},
+		RemovedDurationByStrategy: map[string]time.Duration{
+			"azure-long-running-operation": 13 * time.Minute,
+			"deletion":                     45 * time.Second,
+		},
+	})
+	rpt.Add(CassetteSummary{
+		Path:               "testdata/recordings/alpha.yaml",
+		InteractionsBefore: 12,
+		InteractionsAfter:  9,
+		BytesBefore:        30000,
+		BytesAfter:         22500,
+		DurationBefore:     1500 * time.Millisecond,
+		DurationAfter:      1200 * time.Millisecond,
+		RemovedByStrategy: map[string]int{
+			"deletion": 3,
+		},
+		RemovedDurationByStrategy: map[string]time.Duration{
+			"deletion": 300 * time.Millisecond,
+		},
+	})
+
+	var buff strings.Builder
+	rpt.WriteTo(&buff)
+
+	g.Assert(t, t.Name(), []byte(buff.String()))
+}
+
+func TestCleaningReport_SaveTo_WritesFile(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	rpt := NewCleaningReport()
+	rpt.Add(CassetteSummary{
+		Path:               "recording.yaml",
+		InteractionsBefore: 3,
+		InteractionsAfter:  3,
+	})
+
+	path := filepath.Join(t.TempDir(), "report.md")
+	g.Expect(rpt.SaveTo(path)).To(Succeed())
+
+	content, err := os.ReadFile(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(content)).To(ContainSubstring("| recording.yaml |"))
+	g.Expect(string(content)).To(ContainSubstring("| **Total**"))
+}
+
+func TestCleaningReport_SaveTo_IsReadableByEveryone(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "report.md")
+	g.Expect(NewCleaningReport().SaveTo(path)).To(Succeed())
+
+	info, err := os.Stat(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o644)))
+}
+
+func TestCleaningReport_OmitSizes_LeavesOutByteColumns(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	rpt := NewCleaningReport()
+	rpt.OmitSizes()
+	rpt.Add(CassetteSummary{
+		Path:               "recording.yaml",
+		InteractionsBefore: 6,
+		InteractionsAfter:  4,
+		BytesBefore:        3000,
+		DurationBefore:     1500 * time.Millisecond,
+		DurationAfter:      time.Second,
+	})
+
+	var buff strings.Builder
+	rpt.WriteTo(&buff)
+
+	g.Expect(buff.String()).ToNot(ContainSubstring("Bytes"))
+	g.Expect(buff.String()).ToNot(ContainSubstring("3000"))
+	g.Expect(buff.String()).To(MatchRegexp(`\| recording\.yaml +\| 6 +\| 4 +\| 1\.5s +\| 1s +\|`))
+}
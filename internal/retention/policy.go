@@ -0,0 +1,128 @@
+package retention
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+)
+
+// Policy determines which interactions of a collapsible polling sequence are retained.
+// Interactions retained under any of the rules are kept; all others are excluded.
+type Policy struct {
+	// First is the number of interactions retained at the start of the sequence.
+	First int
+	// Last is the number of interactions retained at the end of the sequence.
+	Last int
+	// Every, if positive, additionally retains every Kth interaction, counting from the start of the sequence.
+	Every int
+}
+
+// Configurable is implemented by analyzers whose retention policy can be changed.
+type Configurable interface {
+	// SetRetention changes the retention policy used by the analyzer.
+	SetRetention(policy Policy)
+}
+
+// DefaultPolicy returns the default retention policy, keeping only the first and last interactions of the sequence.
+func DefaultPolicy() Policy {
+	return Policy{
+		First: 1,
+		Last:  1,
+	}
+}
+
+// Apply splits a polling sequence into the interactions to retain and the interactions to exclude, preserving order.
+func (p Policy) Apply(
+	sequence []interaction.Interface,
+) ([]interaction.Interface, []interaction.Interface) {
+	var retained, excluded []interaction.Interface
+
+	for index, i := range sequence {
+		if p.retains(index, len(sequence)) {
+			retained = append(retained, i)
+		} else {
+			excluded = append(excluded, i)
+		}
+	}
+
+	return retained, excluded
+}
+
+// retains checks whether the interaction at the specified index of a sequence of the specified length is retained.
+func (p Policy) retains(index int, length int) bool {
+	switch {
+	case index < p.First:
+		return true
+	case index >= length-p.Last:
+		return true
+	case p.Every > 0 && index%p.Every == 0:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate checks the policy is well formed.
+// A policy must retain at least one interaction of each sequence, as removing every poll leaves no evidence that
+// polling happened at all.
+func (p Policy) Validate() error {
+	if p.First < 0 || p.Last < 0 || p.Every < 0 {
+		return eris.Errorf("retention policy %s must not be negative", p)
+	}
+
+	if p.First == 0 && p.Last == 0 && p.Every == 0 {
+		return eris.Errorf("retention policy %s must retain at least one interaction", p)
+	}
+
+	return nil
+}
+
+// String returns a representation of the policy that can be read by Parse.
+func (p Policy) String() string {
+	result := fmt.Sprintf("first:%d,last:%d", p.First, p.Last)
+	if p.Every > 0 {
+		result += fmt.Sprintf(",every:%d", p.Every)
+	}
+
+	return result
+}
+
+// Parse reads a retention policy from a specification such as "first:2,last:1,every:5".
+// Any values not specified are taken from the DefaultPolicy.
+func Parse(spec string) (Policy, error) {
+	result := DefaultPolicy()
+
+	for _, part := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return Policy{}, eris.Errorf("invalid retention %q, expected key:value", part)
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return Policy{}, eris.Wrapf(err, "invalid retention %q", part)
+		}
+
+		switch strings.TrimSpace(key) {
+		case "first":
+			result.First = n
+		case "last":
+			result.Last = n
+		case "every":
+			result.Every = n
+		default:
+			return Policy{}, eris.Errorf("invalid retention %q, expected first, last or every", part)
+		}
+	}
+
+	err := result.Validate()
+	if err != nil {
+		return Policy{}, err
+	}
+
+	return result, nil
+}
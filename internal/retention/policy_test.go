@@ -0,0 +1,140 @@
+package retention_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/fake"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/retention"
+)
+
+func TestPolicyApply(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		policy   retention.Policy
+		length   int
+		retained []int
+	}{
+		"Default_RetainsFirstAndLast": {
+			policy:   retention.DefaultPolicy(),
+			length:   6,
+			retained: []int{0, 5},
+		},
+		"Default_ShortSequence_RetainsAll": {
+			policy:   retention.DefaultPolicy(),
+			length:   2,
+			retained: []int{0, 1},
+		},
+		"Default_SingleInteraction_RetainsIt": {
+			policy:   retention.DefaultPolicy(),
+			length:   1,
+			retained: []int{0},
+		},
+		"FirstTwoLastOne_RetainsThree": {
+			policy:   retention.Policy{First: 2, Last: 1},
+			length:   6,
+			retained: []int{0, 1, 5},
+		},
+		"OverlappingFirstAndLast_RetainsAll": {
+			policy:   retention.Policy{First: 3, Last: 3},
+			length:   5,
+			retained: []int{0, 1, 2, 3, 4},
+		},
+		"EveryThird_RetainsMultiplesAndEnds": {
+			policy:   retention.Policy{First: 1, Last: 1, Every: 3},
+			length:   8,
+			retained: []int{0, 3, 6, 7},
+		},
+		"Zero_RetainsNothing": {
+			policy: retention.Policy{},
+			length: 3,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			sequence := make([]interaction.Interface, c.length)
+			for i := range sequence {
+				sequence[i] = fake.Interaction(&url.URL{Scheme: "https", Host: "example.com"}, http.MethodGet, 200)
+			}
+
+			retained, excluded := c.policy.Apply(sequence)
+
+			expected := make([]interaction.Interface, 0, len(c.retained))
+			for _, index := range c.retained {
+				expected = append(expected, sequence[index])
+			}
+
+			g.Expect(retained).To(HaveExactElements(expected))
+			g.Expect(excluded).To(HaveLen(c.length - len(c.retained)))
+			g.Expect(append(retained, excluded...)).To(ConsistOf(sequence))
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		spec     string
+		expected retention.Policy
+		err      string
+	}{
+		"FirstOnly_DefaultsLast": {
+			spec:     "first:2",
+			expected: retention.Policy{First: 2, Last: 1},
+		},
+		"AllValues": {
+			spec:     "first:2, last:3, every:5",
+			expected: retention.Policy{First: 2, Last: 3, Every: 5},
+		},
+		"RoundTrip": {
+			spec:     retention.Policy{First: 0, Last: 4, Every: 2}.String(),
+			expected: retention.Policy{First: 0, Last: 4, Every: 2},
+		},
+		"MissingValue": {
+			spec: "first",
+			err:  "expected key:value",
+		},
+		"UnknownKey": {
+			spec: "middle:2",
+			err:  "expected first, last or every",
+		},
+		"NotANumber": {
+			spec: "first:two",
+			err:  "invalid retention",
+		},
+		"Negative": {
+			spec: "last:-1",
+			err:  "must not be negative",
+		},
+		"RetainsNothing": {
+			spec: "first:0,last:0",
+			err:  "must retain at least one interaction",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			policy, err := retention.Parse(c.spec)
+
+			if c.err != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(c.err)))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(policy).To(Equal(c.expected))
+			}
+		})
+	}
+}
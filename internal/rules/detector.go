@@ -0,0 +1,111 @@
+package rules
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"slices"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/urltool"
+)
+
+// Detector is an analyzer compiled from a Rule.
+// It watches for interactions matching the trigger of the rule, and spawns a Monitor to track the polling that
+// follows each one.
+type Detector struct {
+	name       string         // Name of the rule
+	methods    []string       // HTTP methods of triggering requests
+	triggerURL *regexp.Regexp // Optional pattern for the full URL of triggering requests
+	statuses   []int          // Optional status codes of triggering responses
+	poll       pollShape      // Shape of the polling that follows
+}
+
+// pollShape describes the polling requests that follow a trigger.
+type pollShape struct {
+	method  string   // HTTP method used for polling
+	header  string   // Optional header of the triggering response containing the URL to poll
+	field   string   // Path to the field in the JSON body of polling responses
+	waiting []string // Values of field indicating polling is still waiting
+	done    []string // Values of field indicating the operation is complete
+}
+
+var _ analyzer.Interface = &Detector{}
+
+// Name returns the name of the rule from which the detector was compiled.
+func (d *Detector) Name() string {
+	return d.name
+}
+
+// Analyze processes another interaction in the sequence.
+func (d *Detector) Analyze(
+	_ context.Context,
+	log *slog.Logger,
+	i interaction.Interface,
+) (analyzer.Result, error) {
+	if !d.triggeredBy(i) {
+		return analyzer.Result{}, nil
+	}
+
+	pollURL, ok := d.pollURL(i)
+	if !ok {
+		return analyzer.Result{}, nil
+	}
+
+	log.Debug(
+		"Found interaction matching polling rule",
+		"rule", d.name,
+		"url", pollURL.String(),
+		"method", i.Request().Method(),
+	)
+
+	monitor := newMonitor(d.name, i, pollURL, d.poll)
+
+	return analyzer.Spawn(monitor), nil
+}
+
+// triggeredBy checks whether the interaction matches the trigger of the rule.
+func (d *Detector) triggeredBy(i interaction.Interface) bool {
+	if !interaction.HasAnyMethod(i, d.methods...) {
+		return false
+	}
+
+	if len(d.statuses) > 0 {
+		if !slices.Contains(d.statuses, i.Response().StatusCode()) {
+			return false
+		}
+	} else if !interaction.WasSuccessful(i) {
+		return false
+	}
+
+	if d.triggerURL != nil && !d.triggerURL.MatchString(i.Request().FullURL().String()) {
+		return false
+	}
+
+	return true
+}
+
+// pollURL returns the URL that will be polled following the triggering interaction.
+func (d *Detector) pollURL(i interaction.Interface) (*url.URL, bool) {
+	if d.poll.header == "" {
+		return i.Request().BaseURL(), true
+	}
+
+	value, ok := i.Response().Header(d.poll.header)
+	if !ok || value == "" {
+		return nil, false
+	}
+
+	location, err := url.Parse(value)
+	if err != nil {
+		// Invalid URL means this isn't an interaction we're interested in
+		return nil, false
+	}
+
+	// Allow for relative URLs
+	resolved := i.Request().FullURL().ResolveReference(location)
+
+	return urltool.BaseURL(resolved), true
+}
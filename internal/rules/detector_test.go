@@ -0,0 +1,144 @@
+package rules
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/fake"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/must"
+)
+
+func TestDetector_GivenTriggeringInteraction_SpawnsMonitor(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	rule := widgetRule()
+	detector, err := rule.Compile()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	widgetURL := must.ParseURL(t, "https://widgets.example.com/widgets/1?api-version=2")
+	put := fake.Interaction(widgetURL, http.MethodPut, http.StatusCreated)
+
+	result, err := detector.Analyze(t.Context(), slogt.New(t), put)
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.Finished).To(BeFalse())
+	g.Expect(result.Spawn).To(HaveLen(1))
+
+	monitor, ok := result.Spawn[0].(*Monitor)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(monitor.pollURL.String()).To(Equal("https://widgets.example.com/widgets/1"))
+}
+
+func TestDetector_GivenNonTriggeringInteraction_DoesNothing(t *testing.T) {
+	t.Parallel()
+
+	widgetURL := "https://widgets.example.com/widgets/1"
+
+	cases := map[string]struct {
+		url    string
+		method string
+		status int
+	}{
+		"WrongMethod": {
+			url:    widgetURL,
+			method: http.MethodPost,
+			status: http.StatusCreated,
+		},
+		"Unsuccessful": {
+			url:    widgetURL,
+			method: http.MethodPut,
+			status: http.StatusBadRequest,
+		},
+		"DifferentHost": {
+			url:    "https://gadgets.example.com/gadgets/1",
+			method: http.MethodPut,
+			status: http.StatusCreated,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			rule := widgetRule()
+			detector, err := rule.Compile()
+			g.Expect(err).ToNot(HaveOccurred())
+
+			i := fake.Interaction(must.ParseURL(t, c.url), c.method, c.status)
+
+			result, err := detector.Analyze(t.Context(), slogt.New(t), i)
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(result.Spawn).To(BeEmpty())
+		})
+	}
+}
+
+func TestDetector_GivenStatuses_TriggersOnlyOnListedStatus(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	rule := widgetRule()
+	rule.Trigger.Statuses = []int{http.StatusAccepted}
+	detector, err := rule.Compile()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	widgetURL := must.ParseURL(t, "https://widgets.example.com/widgets/1")
+	created := fake.Interaction(widgetURL, http.MethodPut, http.StatusCreated)
+	accepted := fake.Interaction(widgetURL, http.MethodPut, http.StatusAccepted)
+
+	result, err := detector.Analyze(t.Context(), slogt.New(t), created)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.Spawn).To(BeEmpty())
+
+	result, err = detector.Analyze(t.Context(), slogt.New(t), accepted)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.Spawn).To(HaveLen(1))
+}
+
+func TestDetector_GivenHeader_PollsURLFromHeader(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	rule := widgetRule()
+	rule.Poll.Header = "Operation-Location"
+	detector, err := rule.Compile()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	widgetURL := must.ParseURL(t, "https://widgets.example.com/widgets/1")
+	put := fake.Interaction(widgetURL, http.MethodPut, http.StatusAccepted)
+	put.SetResponseHeader("Operation-Location", "/operations/42?token=abc")
+
+	result, err := detector.Analyze(t.Context(), slogt.New(t), put)
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.Spawn).To(HaveLen(1))
+
+	monitor, ok := result.Spawn[0].(*Monitor)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(monitor.pollURL.String()).To(Equal("https://widgets.example.com/operations/42"))
+}
+
+func TestDetector_GivenMissingHeader_DoesNothing(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	rule := widgetRule()
+	rule.Poll.Header = "Operation-Location"
+	detector, err := rule.Compile()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	widgetURL := must.ParseURL(t, "https://widgets.example.com/widgets/1")
+	put := fake.Interaction(widgetURL, http.MethodPut, http.StatusAccepted)
+
+	result, err := detector.Analyze(t.Context(), slogt.New(t), put)
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.Spawn).To(BeEmpty())
+}
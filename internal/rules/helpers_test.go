@@ -0,0 +1,63 @@
+package rules
+
+import (
+	"log/slog"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+)
+
+/*
+ * Helper functions for testing
+ */
+
+// runAnalyzer runs the analyzer with the provided interactions and returns the final result.
+// It fails the test if any errors occur or if the analyzer finishes prematurely.
+// t is the current test (don't pass a parent).
+// a is the analyzer to run.
+// interactions are the interactions to feed to the analyzer.
+func runAnalyzer(
+	t *testing.T,
+	log *slog.Logger,
+	a analyzer.Interface,
+	interactions ...interaction.Interface,
+) analyzer.Result {
+	t.Helper()
+	g := NewWithT(t)
+
+	var (
+		result analyzer.Result
+		err    error
+	)
+
+	limit := len(interactions) - 1
+	for index, inter := range interactions {
+		result, err = a.Analyze(t.Context(), log, inter)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		if index < limit {
+			g.Expect(result.Finished).To(BeFalse(), "Analyzer finished prematurely")
+		}
+	}
+
+	return result
+}
+
+// widgetRule returns a rule describing the polling of a widget while it is provisioned.
+func widgetRule() Rule {
+	return Rule{
+		Name: "widget-provisioning",
+		Trigger: Trigger{
+			Methods: []string{"put"},
+			URL:     `^https://widgets\.example\.com/`,
+		},
+		Poll: Poll{
+			Field:   "status.phase",
+			Waiting: []string{"Pending", "Provisioning"},
+			Done:    []string{"Ready"},
+		},
+	}
+}
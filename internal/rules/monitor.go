@@ -0,0 +1,201 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/delays"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/retention"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/urltool"
+)
+
+// Monitor is an analyzer for tracking the polling described by a Rule.
+// It watches for polls of a specific URL where the configured JSON field has a 'waiting' value, accumulating them
+// until a poll returns a 'done' value. The monitor then finishes and excludes the accumulated interactions not kept by
+// the retention policy (by default, all but the first and last).
+// If any other method is used on the URL, if a poll fails, or if the field is missing or has an unexpected value,
+// the monitor abandons monitoring and marks itself as Finished.
+// If the recording ends before a done value is seen, the polls are deliberately kept: a rule only describes how
+// polling completes, so there's no way to tell whether an unfinished sequence is safe to collapse.
+type Monitor struct {
+	rule         string                  // Name of the rule
+	trigger      interaction.Interface   // Interaction matching the trigger of the rule
+	pollURL      *url.URL                // Base URL being polled
+	poll         pollShape               // Shape of the polling
+	interactions []interaction.Interface // Accumulated polls with a waiting value
+	retention    retention.Policy        // Policy for which accumulated polls to retain
+	zeroDelays   bool                    // Whether to rewrite delay hints on the polling sequence to zero
+}
+
+var (
+	_ analyzer.Interface = (*Monitor)(nil)
+	_ analyzer.Finisher  = (*Monitor)(nil)
+)
+
+// newMonitor creates a new Monitor for polling of the specified URL.
+// rule is the name of the rule being applied.
+// trigger is the interaction matching the trigger of the rule.
+// pollURL is the URL being polled.
+// poll describes the shape of the polling.
+func newMonitor(
+	rule string,
+	trigger interaction.Interface,
+	pollURL *url.URL,
+	poll pollShape,
+) *Monitor {
+	return &Monitor{
+		rule:      rule,
+		trigger:   trigger,
+		pollURL:   pollURL,
+		poll:      poll,
+		retention: retention.DefaultPolicy(),
+	}
+}
+
+// SetRetention changes the retention policy used when polling finishes.
+func (m *Monitor) SetRetention(policy retention.Policy) {
+	m.retention = policy
+}
+
+// SetZeroDelays controls whether delay hints on the trigger, the polls retained and the final poll are rewritten to
+// zero.
+func (m *Monitor) SetZeroDelays(enabled bool) {
+	m.zeroDelays = enabled
+}
+
+// Analyze processes another interaction in the sequence.
+func (m *Monitor) Analyze(
+	_ context.Context,
+	log *slog.Logger,
+	i interaction.Interface,
+) (analyzer.Result, error) {
+	switch {
+	case !urltool.SameBaseURL(i.Request().BaseURL(), m.pollURL):
+		// Not the URL we're monitoring, ignore.
+		return analyzer.Result{}, nil
+
+	case !interaction.HasMethod(i, m.poll.method):
+		log.Debug(
+			"Abandoning polling rule monitor, unexpected method",
+			"rule", m.rule,
+			"url", m.pollURL.String(),
+			"method", i.Request().Method(),
+		)
+
+		return analyzer.Finished(), nil
+
+	case !interaction.WasSuccessful(i):
+		log.Debug(
+			"Abandoning polling rule monitor due to unexpected status",
+			"rule", m.rule,
+			"url", m.pollURL.String(),
+			"statusCode", i.Response().StatusCode(),
+		)
+
+		return analyzer.Finished(), nil
+
+	default:
+		return m.checkField(log, i)
+	}
+}
+
+// checkField examines the configured field in the response body.
+func (m *Monitor) checkField(
+	log *slog.Logger,
+	i interaction.Interface,
+) (analyzer.Result, error) {
+	value, ok := i.Response().JSON().Text(m.poll.field)
+	switch {
+	case !ok:
+		log.Debug(
+			"Abandoning polling rule monitor, field not found",
+			"rule", m.rule,
+			"url", m.pollURL.String(),
+			"field", m.poll.field,
+		)
+
+		return analyzer.Finished(), nil
+
+	case containsFold(m.poll.waiting, value):
+		// Still waiting; accumulate this interaction and continue monitoring
+		m.interactions = append(m.interactions, i)
+
+		return analyzer.Result{}, nil
+
+	case containsFold(m.poll.done, value):
+		return m.pollingFinished(log, i)
+
+	default:
+		log.Debug(
+			"Abandoning polling rule monitor, unexpected value",
+			"rule", m.rule,
+			"url", m.pollURL.String(),
+			"field", m.poll.field,
+			"value", value,
+		)
+
+		return analyzer.Finished(), nil
+	}
+}
+
+// Finish handles the end of the recording before polling finished, keeping every poll.
+func (m *Monitor) Finish(_ context.Context, log *slog.Logger) (analyzer.Result, error) {
+	log.Debug(
+		"Recording ended before polling finished, keeping polls",
+		"rule", m.rule,
+		"url", m.pollURL.String(),
+		"polls", len(m.interactions),
+	)
+
+	return analyzer.Finished(), nil
+}
+
+// pollingFinished handles the case where the field has reached a done value.
+// final is the poll returning the done value.
+func (m *Monitor) pollingFinished(
+	log *slog.Logger,
+	final interaction.Interface,
+) (analyzer.Result, error) {
+	retained, excluded := m.retention.Apply(m.interactions)
+	if m.zeroDelays {
+		delays.Zero(m.trigger, final)
+		delays.Zero(retained...)
+	}
+
+	if len(excluded) == 0 {
+		// No intermediate interactions to exclude.
+		log.Debug(
+			"Short polling sequence, nothing to exclude",
+			"rule", m.rule,
+			"url", m.pollURL.String(),
+		)
+
+		return analyzer.Finished(), nil
+	}
+
+	log.Debug(
+		"Polling finished, excluding intermediate polls",
+		"rule", m.rule,
+		"url", m.pollURL.String(),
+		"removed", len(excluded),
+	)
+
+	provenance := analyzer.Because(
+		m.pollURL.String(),
+		fmt.Sprintf("intermediate poll while %s was still waiting (rule %s)", m.poll.field, m.rule))
+
+	return analyzer.FinishedWithExclusions(provenance, excluded...), nil
+}
+
+// containsFold checks whether the values include the specified value, ignoring case.
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool {
+		return strings.EqualFold(v, value)
+	})
+}
@@ -0,0 +1,195 @@
+package rules
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/fake"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/must"
+)
+
+// newWidgetMonitor creates a monitor for the widget rule, polling the specified URL.
+func newWidgetMonitor(t *testing.T, pollURL *url.URL) *Monitor {
+	t.Helper()
+	g := NewWithT(t)
+
+	rule := widgetRule()
+	detector, err := rule.Compile()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	trigger := fake.Interaction(pollURL, http.MethodPut, http.StatusAccepted)
+
+	return newMonitor(detector.name, trigger, pollURL, detector.poll)
+}
+
+// poll creates a fake GET of the URL returning the specified phase.
+func poll(pollURL *url.URL, phase string) *fake.TestInteraction {
+	i := fake.Interaction(pollURL, http.MethodGet, http.StatusOK)
+	i.SetResponseBody(`{"status":{"phase":"` + phase + `"}}`)
+
+	return i
+}
+
+func TestMonitor_WaitingThenDone_ExcludesIntermediatePolls(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	pollURL := must.ParseURL(t, "https://widgets.example.com/widgets/1")
+	monitor := newWidgetMonitor(t, pollURL)
+
+	poll1 := poll(pollURL, "Pending")
+	poll2 := poll(pollURL, "Provisioning")
+	poll3 := poll(pollURL, "provisioning")
+	poll4 := poll(pollURL, "Provisioning")
+	done := poll(pollURL, "Ready")
+
+	result := runAnalyzer(t, slogt.New(t), monitor, poll1, poll2, poll3, poll4, done)
+
+	g.Expect(result.Finished).To(BeTrue())
+	g.Expect(result.Excluded).To(ConsistOf(poll2, poll3))
+	g.Expect(result.Provenance.URL).To(Equal(pollURL.String()))
+	g.Expect(result.Provenance.Reason).To(ContainSubstring("status.phase"))
+}
+
+func TestMonitor_WithZeroDelays_RewritesRetryAfterAcrossSequence(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	rule := widgetRule()
+	detector, err := rule.Compile()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	pollURL := must.ParseURL(t, "https://widgets.example.com/widgets/1")
+	trigger := fake.Interaction(pollURL, http.MethodPut, http.StatusAccepted)
+	monitor := newMonitor(detector.name, trigger, pollURL, detector.poll)
+	monitor.SetZeroDelays(true)
+
+	poll1 := poll(pollURL, "Pending")
+	poll2 := poll(pollURL, "Provisioning")
+	poll3 := poll(pollURL, "Provisioning")
+	done := poll(pollURL, "Ready")
+
+	for _, i := range []*fake.TestInteraction{trigger, poll1, poll2, poll3, done} {
+		i.SetResponseHeader("Retry-After", "10")
+	}
+
+	result := runAnalyzer(t, slogt.New(t), monitor, poll1, poll2, poll3, done)
+	g.Expect(result.Excluded).To(ConsistOf(poll2))
+
+	// Every interaction left in the sequence replays without a delay, including the trigger and the final poll
+	for _, i := range []*fake.TestInteraction{trigger, poll1, poll3, done} {
+		retryAfter, ok := i.Response().Header("Retry-After")
+		g.Expect(ok).To(BeTrue())
+		g.Expect(retryAfter).To(Equal("0"), i.String())
+	}
+}
+
+func TestMonitor_ShortSequence_ExcludesNothing(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	pollURL := must.ParseURL(t, "https://widgets.example.com/widgets/1")
+	monitor := newWidgetMonitor(t, pollURL)
+
+	result := runAnalyzer(
+		t,
+		slogt.New(t),
+		monitor,
+		poll(pollURL, "Pending"),
+		poll(pollURL, "Pending"),
+		poll(pollURL, "Ready"))
+
+	g.Expect(result.Finished).To(BeTrue())
+	g.Expect(result.Excluded).To(BeEmpty())
+}
+
+func TestMonitor_OtherURL_IsIgnored(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	pollURL := must.ParseURL(t, "https://widgets.example.com/widgets/1")
+	otherURL := must.ParseURL(t, "https://widgets.example.com/widgets/2")
+	monitor := newWidgetMonitor(t, pollURL)
+
+	result, err := monitor.Analyze(t.Context(), slogt.New(t), poll(otherURL, "Ready"))
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.Finished).To(BeFalse())
+}
+
+func TestMonitor_UnexpectedPoll_Abandons(t *testing.T) {
+	t.Parallel()
+
+	pollURL := must.ParseURL(t, "https://widgets.example.com/widgets/1")
+
+	cases := map[string]struct {
+		interaction interaction.Interface
+	}{
+		"OtherMethod": {
+			interaction: fake.Interaction(pollURL, http.MethodDelete, http.StatusAccepted),
+		},
+		"Unsuccessful": {
+			interaction: fake.Interaction(pollURL, http.MethodGet, http.StatusNotFound),
+		},
+		"NotJSON": {
+			interaction: fake.Interaction(pollURL, http.MethodGet, http.StatusOK),
+		},
+		"MissingField": {
+			interaction: func() interaction.Interface {
+				i := fake.Interaction(pollURL, http.MethodGet, http.StatusOK)
+				i.SetResponseBody(`{"status":{}}`)
+
+				return i
+			}(),
+		},
+		"UnexpectedValue": {
+			interaction: poll(pollURL, "Failed"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			monitor := newWidgetMonitor(t, pollURL)
+
+			_, err := monitor.Analyze(t.Context(), slogt.New(t), poll(pollURL, "Pending"))
+			g.Expect(err).ToNot(HaveOccurred())
+
+			result, err := monitor.Analyze(t.Context(), slogt.New(t), c.interaction)
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(result.Finished).To(BeTrue())
+			g.Expect(result.Excluded).To(BeEmpty())
+		})
+	}
+}
+
+func TestMonitor_RecordingEndsWhileWaiting_KeepsPolls(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	pollURL := must.ParseURL(t, "https://widgets.example.com/widgets/1")
+	monitor := newWidgetMonitor(t, pollURL)
+	log := slogt.New(t)
+
+	result := runAnalyzer(
+		t,
+		log,
+		monitor,
+		poll(pollURL, "Pending"),
+		poll(pollURL, "Provisioning"),
+		poll(pollURL, "Provisioning"))
+	g.Expect(result.Finished).To(BeFalse())
+
+	result, err := monitor.Finish(t.Context(), log)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.Excluded).To(BeEmpty())
+}
@@ -0,0 +1,98 @@
+package rules
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// Rule declaratively describes a polling pattern: an interaction that triggers polling, and the shape of the polls
+// that follow it. Rules are compiled into a Detector that spawns a Monitor for each triggering interaction.
+type Rule struct {
+	// Name identifies the rule in logs and provenance.
+	Name string `yaml:"name"`
+	// Trigger identifies the interactions that start polling.
+	Trigger Trigger `yaml:"trigger"`
+	// Poll describes the polling requests that follow the trigger.
+	Poll Poll `yaml:"poll"`
+}
+
+// Trigger identifies the interactions that start polling.
+type Trigger struct {
+	// Methods lists the HTTP methods of triggering requests, e.g. PUT or POST.
+	Methods []string `yaml:"methods"`
+	// URL is an optional regular expression that the full URL of triggering requests must match.
+	URL string `yaml:"url"`
+	// Statuses optionally lists the status codes of triggering responses; if omitted, any 2xx status triggers.
+	Statuses []int `yaml:"statuses"`
+}
+
+// Poll describes the polling requests that follow a trigger.
+type Poll struct {
+	// Method is the HTTP method used for polling; defaults to GET.
+	Method string `yaml:"method"`
+	// Header optionally names a header of the triggering response containing the URL to poll, e.g. Location.
+	// If omitted, the URL of the triggering request is polled.
+	Header string `yaml:"header"`
+	// Field is the path to a field in the JSON body of polling responses, e.g. status.phase.
+	Field string `yaml:"field"`
+	// Waiting lists the values of Field indicating polling is still waiting for the operation to complete.
+	Waiting []string `yaml:"waiting"`
+	// Done lists the values of Field indicating the operation is complete.
+	Done []string `yaml:"done"`
+}
+
+// Compile validates the rule and compiles it into a Detector.
+func (r *Rule) Compile() (*Detector, error) {
+	if r.Name == "" {
+		return nil, eris.New("polling rule is missing a name")
+	}
+
+	if len(r.Trigger.Methods) == 0 {
+		return nil, eris.Errorf("polling rule %q: trigger must specify at least one method", r.Name)
+	}
+
+	if r.Poll.Field == "" {
+		return nil, eris.Errorf("polling rule %q: poll must specify a field", r.Name)
+	}
+
+	if len(r.Poll.Waiting) == 0 || len(r.Poll.Done) == 0 {
+		return nil, eris.Errorf("polling rule %q: poll must specify both waiting and done values", r.Name)
+	}
+
+	var triggerURL *regexp.Regexp
+	if r.Trigger.URL != "" {
+		var err error
+
+		triggerURL, err = regexp.Compile(r.Trigger.URL)
+		if err != nil {
+			return nil, eris.Wrapf(err, "polling rule %q: invalid trigger url", r.Name)
+		}
+	}
+
+	methods := make([]string, 0, len(r.Trigger.Methods))
+	for _, m := range r.Trigger.Methods {
+		methods = append(methods, strings.ToUpper(m))
+	}
+
+	pollMethod := http.MethodGet
+	if r.Poll.Method != "" {
+		pollMethod = strings.ToUpper(r.Poll.Method)
+	}
+
+	return &Detector{
+		name:       r.Name,
+		methods:    methods,
+		triggerURL: triggerURL,
+		statuses:   r.Trigger.Statuses,
+		poll: pollShape{
+			method:  pollMethod,
+			header:  r.Poll.Header,
+			field:   r.Poll.Field,
+			waiting: r.Poll.Waiting,
+			done:    r.Poll.Done,
+		},
+	}, nil
+}
@@ -0,0 +1,74 @@
+package rules
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/rotisserie/eris"
+	"go.yaml.in/yaml/v3"
+)
+
+// RuleSet is a collection of polling rules, typically loaded from a YAML file.
+type RuleSet struct {
+	Rules     []Rule      `yaml:"rules"`
+	detectors []*Detector // Compiled from Rules when the set is parsed
+}
+
+// Load reads a set of polling rules from the YAML file at the specified path.
+func Load(path string) (*RuleSet, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, eris.Wrapf(err, "reading polling rules %s", path)
+	}
+
+	result, err := Parse(content)
+	if err != nil {
+		return nil, eris.Wrapf(err, "loading polling rules %s", path)
+	}
+
+	return result, nil
+}
+
+// Parse reads a set of polling rules from YAML content.
+// Unknown keys are reported as errors, so that typos don't silently change the meaning of a rule.
+func Parse(content []byte) (*RuleSet, error) {
+	var result RuleSet
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	err := decoder.Decode(&result)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, eris.Wrap(err, "parsing polling rules")
+	}
+
+	// Compile each rule now, so errors are reported up front
+	seen := make(map[string]bool, len(result.Rules))
+	result.detectors = make([]*Detector, 0, len(result.Rules))
+
+	for i := range result.Rules {
+		rule := &result.Rules[i]
+		if seen[rule.Name] {
+			return nil, eris.Errorf("polling rule %q is defined more than once", rule.Name)
+		}
+
+		seen[rule.Name] = true
+
+		detector, err := rule.Compile()
+		if err != nil {
+			return nil, err
+		}
+
+		result.detectors = append(result.detectors, detector)
+	}
+
+	return &result, nil
+}
+
+// Detectors returns the Detector compiled from each rule in the set when it was parsed, in the same order.
+// Detectors hold no state of their own, so they may be shared between cleaners.
+func (s *RuleSet) Detectors() []*Detector {
+	return s.detectors
+}
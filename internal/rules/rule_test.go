@@ -0,0 +1,129 @@
+package rules
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRuleCompile_GivenValidRule_ReturnsDetector(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	rule := widgetRule()
+
+	detector, err := rule.Compile()
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(detector.Name()).To(Equal("widget-provisioning"))
+	g.Expect(detector.methods).To(ConsistOf(http.MethodPut))
+	g.Expect(detector.poll.method).To(Equal(http.MethodGet))
+}
+
+func TestRuleCompile_GivenInvalidRule_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		modify   func(r *Rule)
+		expected string
+	}{
+		"MissingName": {
+			modify:   func(r *Rule) { r.Name = "" },
+			expected: "missing a name",
+		},
+		"MissingMethods": {
+			modify:   func(r *Rule) { r.Trigger.Methods = nil },
+			expected: "at least one method",
+		},
+		"InvalidURL": {
+			modify:   func(r *Rule) { r.Trigger.URL = "(" },
+			expected: "invalid trigger url",
+		},
+		"MissingField": {
+			modify:   func(r *Rule) { r.Poll.Field = "" },
+			expected: "must specify a field",
+		},
+		"MissingWaiting": {
+			modify:   func(r *Rule) { r.Poll.Waiting = nil },
+			expected: "both waiting and done",
+		},
+		"MissingDone": {
+			modify:   func(r *Rule) { r.Poll.Done = nil },
+			expected: "both waiting and done",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			rule := widgetRule()
+			c.modify(&rule)
+
+			_, err := rule.Compile()
+
+			g.Expect(err).To(MatchError(ContainSubstring(c.expected)))
+		})
+	}
+}
+
+func TestParse_GivenRules_ReturnsRuleSet(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	set, err := Parse([]byte(`
+rules:
+  - name: widget-provisioning
+    trigger:
+      methods: [PUT]
+      url: ^https://widgets\.example\.com/
+      statuses: [201]
+    poll:
+      header: Location
+      field: status.phase
+      waiting: [Pending]
+      done: [Ready]
+`))
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(set.Rules).To(HaveLen(1))
+	g.Expect(set.Rules[0].Trigger.Statuses).To(ConsistOf(201))
+	g.Expect(set.Rules[0].Poll.Header).To(Equal("Location"))
+	g.Expect(set.Detectors()).To(HaveExactElements(
+		HaveField("Name()", "widget-provisioning")))
+}
+
+func TestParse_GivenUnknownKey_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	_, err := Parse([]byte(`
+rules:
+  - name: widget-provisioning
+    trigger:
+      method: PUT
+`))
+
+	g.Expect(err).To(MatchError(ContainSubstring("parsing polling rules")))
+}
+
+func TestParse_GivenDuplicateNames_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	rule := `
+  - name: widget
+    trigger:
+      methods: [PUT]
+    poll:
+      field: status
+      waiting: [Pending]
+      done: [Ready]
+`
+
+	_, err := Parse([]byte("rules:" + rule + rule))
+
+	g.Expect(err).To(MatchError(ContainSubstring("defined more than once")))
+}
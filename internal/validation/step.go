@@ -0,0 +1,28 @@
+package validation
+
+import (
+	"net/http"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/retention"
+)
+
+// Step summarizes a single interaction of a cleaned sequence, capturing only what's needed to validate it.
+// Keeping steps small allows very large cassettes to be validated without holding every interaction in memory.
+type Step struct {
+	// ID identifies the interaction in any violations reported, e.g. its ID within the cassette.
+	ID int
+	// Method is the HTTP method of the request.
+	Method string
+	// URL is the full URL of the request.
+	URL string
+	// Headers are the response headers after cleaning; only those linking to other requests are examined.
+	Headers http.Header
+	// Removed is true if the interaction was selected for removal.
+	Removed bool
+	// Provenance explains why the interaction was removed, if it was.
+	Provenance analyzer.Provenance
+	// Retention is the retention policy of the strategy that removed the interaction, if it was removed.
+	// Where the policy doesn't retain the first (or last) polls of a sequence, their removal is expected.
+	Retention retention.Policy
+}
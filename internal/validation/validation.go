@@ -0,0 +1,199 @@
+package validation
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/urltool"
+)
+
+// LinkHeaders are the response headers that direct a client to the next request of a sequence.
+var LinkHeaders = []string{
+	"Location",
+	"Azure-AsyncOperation",
+}
+
+// triggerMethods are the methods of requests that change state, triggering any polling that follows.
+var triggerMethods = []string{
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// Check validates a cleaned sequence of interactions, in the order they were recorded, returning an error describing
+// every violation found. A well-behaved set of analyzers never violates these invariants, so any violation indicates
+// a bug that would corrupt the recording if it were saved:
+//   - no request triggering a change of state (PUT, PATCH or DELETE) is removed;
+//   - every polled URL keeps the first and terminal polls of each sequence, unless the retention policy of the
+//     strategy removing them says otherwise; and
+//   - every link (a Location or Azure-AsyncOperation header) to a URL that was requested later in the original
+//     sequence still leads to a URL requested later in the cleaned sequence.
+func Check(steps []Step) error {
+	var violations []string
+
+	violations = append(violations, checkTriggers(steps)...)
+	violations = append(violations, checkPolls(steps)...)
+	violations = append(violations, checkLinks(steps)...)
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return eris.Errorf(
+		"cleaned interactions failed validation (%d violations): %s",
+		len(violations),
+		strings.Join(violations, "; "))
+}
+
+// checkTriggers returns a violation for each request triggering a change of state that was removed.
+func checkTriggers(steps []Step) []string {
+	var result []string
+
+	for _, step := range steps {
+		if step.Removed && slices.Contains(triggerMethods, step.Method) {
+			result = append(result, fmt.Sprintf("trigger %s was removed by %s", describe(step), removedBy(step)))
+		}
+	}
+
+	return result
+}
+
+// checkPolls returns a violation for each polling sequence that lost its first or terminal poll.
+func checkPolls(steps []Step) []string {
+	var result []string
+
+	for _, run := range pollingRuns(steps) {
+		if len(run) < 2 {
+			// A single request isn't polling
+			continue
+		}
+
+		first := run[0]
+		if first.Removed && first.Retention.First > 0 {
+			result = append(result, fmt.Sprintf("first poll %s was removed by %s", describe(*first), removedBy(*first)))
+		}
+
+		last := run[len(run)-1]
+		if last.Removed && last.Retention.Last > 0 {
+			result = append(result, fmt.Sprintf("terminal poll %s was removed by %s", describe(*last), removedBy(*last)))
+		}
+	}
+
+	return result
+}
+
+// checkLinks returns a violation for each retained link that no longer leads anywhere.
+func checkLinks(steps []Step) []string {
+	var result []string
+
+	// For each URL, find where it was last requested, both originally and after cleaning
+	lastRequested := make(map[string]int, len(steps))
+	lastRetained := make(map[string]int, len(steps))
+
+	for index, step := range steps {
+		u, err := url.Parse(step.URL)
+		if err != nil {
+			continue
+		}
+
+		lastRequested[u.String()] = index
+		if !step.Removed {
+			lastRetained[u.String()] = index
+		}
+	}
+
+	requestedAfter := func(requests map[string]int, target string, index int) bool {
+		last, ok := requests[target]
+
+		return ok && last > index
+	}
+
+	for index, step := range steps {
+		if step.Removed {
+			continue
+		}
+
+		base, err := url.Parse(step.URL)
+		if err != nil {
+			continue
+		}
+
+		for _, header := range LinkHeaders {
+			value := step.Headers.Get(header)
+			if value == "" {
+				continue
+			}
+
+			target, err := base.Parse(value)
+			if err != nil {
+				continue
+			}
+
+			if requestedAfter(lastRequested, target.String(), index) &&
+				!requestedAfter(lastRetained, target.String(), index) {
+				result = append(result, fmt.Sprintf(
+					"%s header of %s links to %s, which is no longer requested",
+					header,
+					describe(step),
+					target))
+			}
+		}
+	}
+
+	return result
+}
+
+// pollingRuns groups the steps into runs of repeated requests: consecutive requests to the same base URL with the
+// same method, with no request to that URL using a different method in between.
+// Runs are returned in the order they started.
+func pollingRuns(steps []Step) [][]*Step {
+	var result [][]*Step
+
+	// open maps each base URL to the index of its current run within result
+	open := make(map[string]int)
+
+	for index := range steps {
+		step := &steps[index]
+
+		u, err := url.Parse(step.URL)
+		if err != nil {
+			continue
+		}
+
+		key := urltool.BaseURL(u).String()
+
+		current, ok := open[key]
+		if !ok || result[current][0].Method != step.Method {
+			current = len(result)
+			open[key] = current
+			result = append(result, nil)
+		}
+
+		result[current] = append(result[current], step)
+	}
+
+	return result
+}
+
+// describe returns a short description of the step for use in a violation.
+func describe(step Step) string {
+	return fmt.Sprintf("%s %s (interaction %d)", step.Method, step.URL, step.ID)
+}
+
+// removedBy returns a short description of what removed the step, for use in a violation.
+func removedBy(step Step) string {
+	p := step.Provenance
+	switch {
+	case p.Strategy != "" && p.Analyzer != "":
+		return fmt.Sprintf("%s (strategy %s)", p.Analyzer, p.Strategy)
+	case p.Analyzer != "":
+		return p.Analyzer
+	default:
+		return "an unknown analyzer"
+	}
+}
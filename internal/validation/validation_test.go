@@ -0,0 +1,159 @@
+package validation
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/retention"
+)
+
+const (
+	resourceURL  = "https://api.example.com/widgets/1"
+	operationURL = "https://api.example.com/operations/7"
+)
+
+// request creates a step for a request to the specified URL, numbered by its position in steps.
+func request(steps []Step, method string, u string) []Step {
+	return append(steps, Step{
+		ID:     len(steps),
+		Method: method,
+		URL:    u,
+	})
+}
+
+// remove marks the steps with the specified IDs as removed under the default retention policy.
+func remove(steps []Step, ids ...int) []Step {
+	for _, id := range ids {
+		steps[id].Removed = true
+		steps[id].Provenance = analyzer.Provenance{Analyzer: "test.Monitor", Strategy: "testing"}
+		steps[id].Retention = retention.DefaultPolicy()
+	}
+
+	return steps
+}
+
+// deletion returns a sequence where a DELETE is followed by polls confirming the resource has gone.
+func deletion() []Step {
+	steps := request(nil, http.MethodDelete, resourceURL)
+	for range 4 {
+		steps = request(steps, http.MethodGet, resourceURL)
+	}
+
+	return steps
+}
+
+func TestCheck_IntermediatePollsRemoved_Succeeds(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	steps := remove(deletion(), 2, 3)
+
+	g.Expect(Check(steps)).To(Succeed())
+}
+
+func TestCheck_TriggerRemoved_ReportsViolation(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	steps := remove(deletion(), 0)
+
+	g.Expect(Check(steps)).To(MatchError(And(
+		ContainSubstring("trigger DELETE "+resourceURL+" (interaction 0)"),
+		ContainSubstring("test.Monitor (strategy testing)"))))
+}
+
+func TestCheck_FirstPollRemoved_ReportsViolation(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	steps := remove(deletion(), 1, 2)
+
+	g.Expect(Check(steps)).To(MatchError(ContainSubstring("first poll GET " + resourceURL + " (interaction 1)")))
+}
+
+func TestCheck_TerminalPollRemoved_ReportsViolation(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	steps := remove(deletion(), 3, 4)
+
+	g.Expect(Check(steps)).To(MatchError(ContainSubstring("terminal poll GET " + resourceURL + " (interaction 4)")))
+}
+
+func TestCheck_FirstPollRemovedWhenPolicyRetainsNone_Succeeds(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	steps := remove(deletion(), 1, 2)
+	steps[1].Retention = retention.Policy{First: 0, Last: 1}
+
+	g.Expect(Check(steps)).To(Succeed())
+}
+
+func TestCheck_RequestsWithDifferentMethods_FormSeparateRuns(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	// Polls before and after the DELETE are separate runs, each with its own first and terminal poll
+	steps := request(nil, http.MethodGet, resourceURL)
+	steps = request(steps, http.MethodGet, resourceURL)
+	steps = append(steps, deletion()...)
+	for index := range steps {
+		steps[index].ID = index
+	}
+
+	steps = remove(steps, 1)
+
+	g.Expect(Check(steps)).To(MatchError(ContainSubstring("terminal poll GET " + resourceURL + " (interaction 1)")))
+}
+
+func TestCheck_LinkToRemovedRequest_ReportsViolation(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	steps := request(nil, http.MethodPut, resourceURL)
+	steps[0].Headers = http.Header{"Azure-Asyncoperation": {operationURL}}
+	steps = request(steps, http.MethodGet, operationURL)
+	steps = request(steps, http.MethodGet, resourceURL)
+	steps = remove(steps, 1)
+
+	g.Expect(Check(steps)).To(MatchError(ContainSubstring(
+		"Azure-AsyncOperation header of PUT " + resourceURL + " (interaction 0) links to " + operationURL)))
+}
+
+func TestCheck_RelativeLinkToRetainedRequest_Succeeds(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	steps := request(nil, http.MethodPut, resourceURL)
+	steps[0].Headers = http.Header{"Location": {"/operations/7?t=2"}}
+	steps = request(steps, http.MethodGet, operationURL+"?t=1")
+	steps = request(steps, http.MethodGet, operationURL+"?t=2")
+	steps = remove(steps, 1)
+	steps[1].Retention = retention.Policy{First: 0, Last: 1}
+
+	g.Expect(Check(steps)).To(Succeed())
+}
+
+func TestCheck_LinkNeverFollowed_Succeeds(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	steps := request(nil, http.MethodPut, resourceURL)
+	steps[0].Headers = http.Header{"Location": {operationURL}}
+	steps = request(steps, http.MethodGet, resourceURL)
+
+	g.Expect(Check(steps)).To(Succeed())
+}
+
+func TestCheck_SeveralViolations_ReportsAll(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	steps := remove(deletion(), 0, 1, 4)
+
+	g.Expect(Check(steps)).To(MatchError(ContainSubstring("(3 violations)")))
+}
@@ -8,9 +8,9 @@ import (
 
 func main() {
 	// Entry point for the application.
-	var cli cmd.CleanCommand
+	cli := cmd.NewCLI()
 
-	ctx := kong.Parse(&cli,
+	ctx := kong.Parse(cli,
 		kong.UsageOnError())
 
 	log := cli.CreateLogger()
@@ -21,7 +21,18 @@ func main() {
 		Log:     log,
 	}
 
-	err := ctx.Run(cmdCtx)
+	err := cmdCtx.DiscoverProjectConfig(".")
+	if err != nil {
+		cmdCtx.Log.Error("Error loading project configuration", "error", err)
+		ctx.Exit(1)
+	}
+
+	// Ctrl-C finishes the files in progress before stopping, rather than leaving them half written
+	release := cmdCtx.HandleInterrupts()
+	err = ctx.Run(cmdCtx)
+
+	release()
+
 	if err != nil {
 		cmdCtx.Log.Error("Error executing command", "error", err)
 		ctx.Exit(1)
@@ -0,0 +1,29 @@
+package vcrcleaner
+
+import (
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+)
+
+// Analyzer examines each interaction of a cassette in turn, deciding which to remove (or keep).
+// Implement this to provide a cleaning strategy of your own, adding it to a Cleaner with WithAnalyzer.
+// Analyzers that also implement AnalyzerFinisher are told when the recording ends.
+type Analyzer = analyzer.Interface
+
+// AnalyzerFinisher is implemented by analyzers that need to know when the recording ends.
+type AnalyzerFinisher = analyzer.Finisher
+
+// AnalyzerResult is returned by an Analyzer after examining an interaction, listing any interactions to remove or
+// keep, any new analyzers to spawn, and whether the analyzer has finished.
+type AnalyzerResult = analyzer.Result
+
+// Interaction is a single request and response, as seen by an Analyzer.
+type Interaction = interaction.Interface
+
+// WithAnalyzer adds analyzers to the Cleaner as the named cleaning strategy, reported in the Provenance of each
+// interaction they remove. Return this from the New function of a registered Strategy to make it selectable by name.
+func WithAnalyzer(strategy string, analyzers ...Analyzer) Option {
+	return func(c *Cleaner) {
+		c.core.AddStrategy(strategy, analyzers...)
+	}
+}
@@ -0,0 +1,60 @@
+package vcrcleaner_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/pkg/vcrcleaner"
+)
+
+// removeHealthChecks is an analyzer written using only the exported API, removing every HEAD of a health endpoint.
+type removeHealthChecks struct{}
+
+func (removeHealthChecks) Analyze(
+	_ context.Context,
+	_ *slog.Logger,
+	i vcrcleaner.Interaction,
+) (vcrcleaner.AnalyzerResult, error) {
+	if i.Request().Method() != http.MethodHead || i.Request().FullURL().Path != "/health" {
+		return vcrcleaner.AnalyzerResult{}, nil
+	}
+
+	return vcrcleaner.AnalyzerResult{
+		Excluded:   []vcrcleaner.Interaction{i},
+		Provenance: vcrcleaner.Provenance{Reason: "health check"},
+	}, nil
+}
+
+func TestWithAnalyzer_GivenAnalyzerFromAnotherPackage_RemovesInteractions(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	cas := cassette.New("health-checks")
+	for _, method := range []string{http.MethodHead, http.MethodGet, http.MethodHead} {
+		cas.AddInteraction(&cassette.Interaction{
+			Request:  cassette.Request{Method: method, URL: "https://api.example.com/health"},
+			Response: cassette.Response{Code: http.StatusOK},
+		})
+	}
+
+	cleaner := vcrcleaner.New(slogt.New(t), vcrcleaner.WithAnalyzer("health-checks", removeHealthChecks{}))
+
+	modified, err := cleaner.CleanCassette(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	for _, i := range cas.Interactions {
+		g.Expect(i.DiscardOnSave).To(Equal(i.ID != 1), "interaction %d", i.ID)
+	}
+
+	provenance, ok := cleaner.Provenance(0)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(provenance.Strategy).To(Equal("health-checks"))
+}
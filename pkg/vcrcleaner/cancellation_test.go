@@ -0,0 +1,142 @@
+package vcrcleaner
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+)
+
+// cancelAfter is an analyzer that cancels analysis once it has seen a given number of interactions, as a user
+// interrupting a long run would.
+type cancelAfter struct {
+	remaining int
+	cancel    context.CancelFunc
+}
+
+func (c *cancelAfter) Analyze(context.Context, *slog.Logger, interaction.Interface) (analyzer.Result, error) {
+	c.remaining--
+	if c.remaining == 0 {
+		c.cancel()
+	}
+
+	return analyzer.Result{}, nil
+}
+
+// cancelOnFinish is an analyzer that cancels once analysis is complete, just before the cleaned cassette is saved.
+type cancelOnFinish struct {
+	cancel context.CancelFunc
+}
+
+func (*cancelOnFinish) Analyze(context.Context, *slog.Logger, interaction.Interface) (analyzer.Result, error) {
+	return analyzer.Result{}, nil
+}
+
+func (c *cancelOnFinish) Finish(context.Context, *slog.Logger) (analyzer.Result, error) {
+	c.cancel()
+
+	return analyzer.Result{}, nil
+}
+
+func TestCleanFile_Cancelled_LeavesCassetteUntouched(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		options []Option
+		// canceller returns an analyzer cancelling the run at the point of interest
+		canceller func(cancel context.CancelFunc) analyzer.Interface
+	}{
+		"DuringAnalysis": {
+			canceller: func(cancel context.CancelFunc) analyzer.Interface {
+				return &cancelAfter{remaining: 5, cancel: cancel}
+			},
+		},
+		"DuringStreamedAnalysis": {
+			options: []Option{Streaming()},
+			canceller: func(cancel context.CancelFunc) analyzer.Interface {
+				return &cancelAfter{remaining: 5, cancel: cancel}
+			},
+		},
+		"BeforeSaving": {
+			canceller: func(cancel context.CancelFunc) analyzer.Interface {
+				return &cancelOnFinish{cancel: cancel}
+			},
+		},
+		"BeforeStreamedRewrite": {
+			options: []Option{Streaming()},
+			canceller: func(cancel context.CancelFunc) analyzer.Interface {
+				return &cancelOnFinish{cancel: cancel}
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewGomegaWithT(t)
+
+			path := copyRecording(t, g, "Test_EventHub_Namespace_v20240101_CRUD", "cassette.yaml")
+			original, err := os.ReadFile(path)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			options := append([]Option{ReduceAzureResourceModificationMonitoring()}, c.options...)
+			cleaner := New(slogt.New(t), options...)
+			cleaner.core.AddStrategy("cancelling", c.canceller(cancel))
+
+			modified, err := cleaner.CleanFile(ctx, path)
+			g.Expect(err).To(MatchError(context.Canceled))
+			g.Expect(modified).To(BeFalse())
+
+			content, err := os.ReadFile(path)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(content).To(Equal(original))
+
+			// No temporary files are left behind
+			entries, err := os.ReadDir(filepath.Dir(path))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(entries).To(HaveLen(1))
+		})
+	}
+}
+
+func TestCleanFile_NotCancelled_SavesCleanedCassette(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	path := copyRecording(t, g, "Test_EventHub_Namespace_v20240101_CRUD", "cassette.yaml")
+	original, err := os.ReadFile(path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cleaner := New(slogt.New(t), ReduceAzureResourceModificationMonitoring())
+	modified, err := cleaner.CleanFile(t.Context(), path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	content, err := os.ReadFile(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(content).NotTo(Equal(original))
+}
+
+func TestPlan_Cancelled_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	cleaner := New(slogt.New(t), ReduceDeleteMonitoring())
+
+	_, err := cleaner.Plan(ctx, unconfirmedDeletion())
+	g.Expect(err).To(MatchError(context.Canceled))
+}
@@ -2,24 +2,46 @@ package vcrcleaner
 
 import (
 	"context"
+	"iter"
 	"log/slog"
-	"strings"
 	"sync"
 
 	"github.com/rotisserie/eris"
-	"go.yaml.in/yaml/v3"
 	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
 
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/cassettefile"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/cleaner"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/har"
 )
 
+// Provenance records which analyzer removed an interaction, the cleaning strategy responsible, the URL being
+// monitored, and a human-readable reason.
+type Provenance = analyzer.Provenance
+
 // LevelVerbose is a custom log level between INFO and DEBUG.
 const LevelVerbose = slog.Level(-2)
 
 // Cleaner is a tool for cleaning go-vcr recordings.
 type Cleaner struct {
-	core    *cleaner.Cleaner
-	mapping map[int]*vcrInteraction
+	core *cleaner.Cleaner
+	// mapping tracks each interaction in the cassette, keyed by its current ID
+	// (for an HTTP Archive, entries are keyed by their index when analyzed)
+	mapping map[int]*record
+	// discarded tracks interactions physically removed from the cassette, keyed by the ID they had when analyzed
+	discarded map[int]*record
+	// renumber indicates whether discarded interactions should be physically removed, renumbering those remaining
+	renumber bool
+	// streaming indicates whether YAML cassettes should be streamed rather than loaded into memory
+	streaming bool
+	// upgrade indicates whether cassettes in older formats should be rewritten in the current format
+	upgrade bool
+	// hooked lists the interactions captured by AfterCaptureHook, in order, so they can be validated before saving
+	hooked []*cassette.Interaction
+	// saving indicates BeforeSaveHook has been called, so analysis is complete
+	saving bool
+	// saveErr is any error found when analysis completed, returned by every call to BeforeSaveHook
+	saveErr error
 	log     *slog.Logger
 	padlock sync.Mutex
 }
@@ -29,94 +51,215 @@ func New(
 	options ...Option,
 ) *Cleaner {
 	result := &Cleaner{
-		core:    cleaner.New(),
-		mapping: make(map[int]*vcrInteraction),
-		log:     log,
+		core:      cleaner.New(),
+		mapping:   make(map[int]*record),
+		discarded: make(map[int]*record),
+		log:       log,
 	}
 
 	for _, option := range options {
-		option(result.core)
+		option(result)
 	}
 
 	return result
 }
 
 // CleanFile processes a single cassette file, removing unnecessary interactions.
-// The path parameter should be the full path to the cassette file, including the .yaml extension.
+// The path parameter should be the full path to the cassette file, including any extension.
+// YAML and JSON cassettes are supported, optionally compressed with gzip (e.g. .yaml.gz). The format is detected from
+// the extension or, failing that, the content, and is preserved when the cassette is saved.
+// HTTP Archives (.har) are also supported, with unnecessary entries removed.
+// With the Streaming option, YAML cassettes are processed without loading them into memory.
+// Files are replaced atomically, so an interrupted save leaves the original in place.
 // Returns true if the file was modified and saved, false if no changes were made.
-// Returns an error if the file cannot be processed.
+// Returns an error if the file cannot be processed, or if ctx is cancelled before it has been saved, in which case
+// the file is left untouched.
 func (c *Cleaner) CleanFile(
+	ctx context.Context,
 	path string,
 ) (bool, error) {
-	// Remove .yaml from the path if present, as go-vcr expects just the base name
-	cassetteName := strings.TrimSuffix(path, ".yaml")
+	if har.IsHAR(path) {
+		return c.cleanHARFile(ctx, path)
+	}
 
-	// Attempt to load a cassette from the specified path
-	// This might fail if we are given a different kind of YAML file, so we need to handle that gracefully
-	cas, err := cassette.Load(cassetteName)
-	if err != nil {
-		c.log.Warn("Skipping non-cassette file", "path", path, "error", err)
+	if c.streams(path) {
+		return c.cleanStream(ctx, path)
+	}
 
+	file, ok := c.loadCassette(path)
+	if !ok {
 		return false, nil
 	}
 
 	c.log.Info("Checking cassette", "path", path)
 
 	// Clean the cassette
-	modified, err := c.CleanCassette(cas)
+	modified, err := c.CleanCassette(ctx, file.Cassette)
 	if err != nil {
 		return false, eris.Wrapf(err, "cleaning cassette from %s", path)
 	}
 
-	// If modified, save the cassette back
+	// Rewrite cassettes recorded by older versions of go-vcr, if requested
+	version := file.Version
+	if c.upgrade && file.Upgrade() {
+		c.log.Info("Upgrading cassette format", "path", path, "from", version, "to", file.Version)
+
+		modified = true
+	}
+
+	// If modified, save the cassette back in the same format
 	if modified {
-		cas.MarshalFunc = yaml.Marshal // Odd to need to do this explicitly
+		if err := ctx.Err(); err != nil {
+			return false, eris.Wrapf(err, "cancelled before saving cleaned cassette to %s", path)
+		}
 
-		err = cas.Save()
+		err = file.Save()
 		if err != nil {
 			return false, eris.Wrapf(err, "saving cleaned cassette to %s", path)
 		}
 
 		c.log.Info("Modified cassette", "path", path)
 	} else {
-		c.log.Log(context.Background(), LevelVerbose, "No change to cassette", "path", path)
+		c.log.Log(ctx, LevelVerbose, "No change to cassette", "path", path)
 	}
 
 	return modified, nil
 }
 
+// loadCassette attempts to load a cassette from the specified path, detecting its format.
+// Returns false if the file can't be loaded as a cassette, logging a warning.
+func (c *Cleaner) loadCassette(
+	path string,
+) (*cassettefile.File, bool) {
+	// Attempt to load a cassette from the specified path
+	// This might fail if we are given a different kind of YAML file, so we need to handle that gracefully
+	file, err := cassettefile.Load(path)
+	if err != nil {
+		c.log.Warn("Skipping non-cassette file", "path", path, "error", err)
+
+		return nil, false
+	}
+
+	c.log.Debug("Loaded cassette", "path", path, "encoding", file.Encoding.String())
+
+	return file, true
+}
+
 // CleanCassette processes a cassette, marking interactions for removal as needed.
-// Returns true if any interactions were marked for removal, false otherwise, along with any error encountered.
-func (c *Cleaner) CleanCassette(cas *cassette.Cassette) (bool, error) {
+// Analysis happens on copies of the interactions, and the cleaned sequence is validated before any interactions are
+// marked or changed; if validation fails, an error is returned and the cassette is left untouched.
+// Returns true if any interactions were marked for removal or otherwise changed (e.g. by rewriting delay hints),
+// false otherwise, along with any error encountered.
+// If ctx is cancelled, analysis stops at the next interaction and an error is returned, with nothing marked.
+func (c *Cleaner) CleanCassette(ctx context.Context, cas *cassette.Cassette) (bool, error) {
 	c.padlock.Lock()
 	defer c.padlock.Unlock()
 
-	// Scan all interactions
+	copies := make([]*cassette.Interaction, 0, len(cas.Interactions))
+
+	// Scan copies of all interactions, as analyzers may modify headers
 	for _, i := range cas.Interactions {
-		if err := c.inspect(i); err != nil {
+		copied := copyInteraction(i)
+		copies = append(copies, copied)
+
+		if err := c.inspect(ctx, copied); err != nil {
 			return false, eris.Wrapf(err, "inspecting interaction %d", i.ID)
 		}
 	}
 
+	if err := c.finish(ctx); err != nil {
+		return false, err
+	}
+
+	// Check the cleaned interactions before changing any, so a buggy analyzer can't corrupt the cassette
+	if err := c.validate(c.cassetteSteps(copies)); err != nil {
+		return false, err
+	}
+
+	for _, i := range cas.Interactions {
+		if rec, ok := c.mapping[i.ID]; ok {
+			applyChanges(i, rec)
+		}
+	}
+
 	// If any interactions are to be marked for removal, mark them now
 	if c.core.InteractionsToRemove() > 0 {
 		for _, i := range cas.Interactions {
 			c.markIfExcluded(i)
 		}
 
+		if c.renumber {
+			c.removeDiscarded(cas)
+		}
+
 		return true, nil
 	}
 
-	// No interactions were marked for removal
-	return false, nil
+	// No interactions were marked for removal, but some may have been changed
+	return c.anyChanged(cas), nil
+}
+
+// anyChanged returns true if any of the cassette's interactions were changed by an analyzer.
+func (c *Cleaner) anyChanged(cas *cassette.Cassette) bool {
+	for _, i := range cas.Interactions {
+		if rec, ok := c.mapping[i.ID]; ok && rec.modified {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeDiscarded physically removes interactions marked DiscardOnSave from the cassette, renumbering the remaining
+// interactions so their IDs are contiguous. The mapping is rekeyed to match, while discarded interactions are kept
+// (by their original ID) so their provenance remains available.
+func (c *Cleaner) removeDiscarded(cas *cassette.Cassette) {
+	retained := make([]*cassette.Interaction, 0, len(cas.Interactions))
+	mapping := make(map[int]*record, len(cas.Interactions))
+
+	for _, i := range cas.Interactions {
+		rec, known := c.mapping[i.ID]
+
+		if i.DiscardOnSave {
+			if known {
+				c.discarded[i.ID] = rec
+			}
+
+			continue
+		}
+
+		i.ID = len(retained)
+		retained = append(retained, i)
+
+		if known {
+			mapping[i.ID] = rec
+		}
+	}
+
+	c.log.Debug(
+		"Renumbered interactions",
+		"removed", len(cas.Interactions)-len(retained),
+		"remaining", len(retained),
+	)
+
+	cas.Interactions = retained
+	c.mapping = mapping
+}
+
+// applyChanges copies any changes made to an interaction during analysis (of a copy) onto the interaction itself.
+func applyChanges(i *cassette.Interaction, rec *record) {
+	if rec.modified {
+		i.Response.Headers = rec.headers
+		i.Response.Duration = rec.duration
+	}
 }
 
 // inspect processes a single interaction through the cleaner.
-func (c *Cleaner) inspect(i *cassette.Interaction) error {
+func (c *Cleaner) inspect(ctx context.Context, i *cassette.Interaction) error {
 	vi := newVCRInteraction(i)
-	c.mapping[i.ID] = vi
+	c.mapping[i.ID] = vi.record
 
-	err := c.core.Analyze(c.log, vi)
+	err := c.core.Analyze(ctx, c.log, vi)
 	if err != nil {
 		return eris.Wrapf(err, "analyzing interaction ID %d", i.ID)
 	}
@@ -124,33 +267,124 @@ func (c *Cleaner) inspect(i *cassette.Interaction) error {
 	return nil
 }
 
+// finish tells any analyzers still active that there are no more interactions to come.
+func (c *Cleaner) finish(ctx context.Context) error {
+	err := c.core.Finish(ctx, c.log)
+	if err != nil {
+		return eris.Wrap(err, "finishing analysis")
+	}
+
+	return nil
+}
+
 // markIfExcluded marks an interaction for removal, if needed.
 func (c *Cleaner) markIfExcluded(i *cassette.Interaction) {
-	vi, ok := c.mapping[i.ID]
+	rec, ok := c.mapping[i.ID]
 	if !ok {
 		// Not an interaction we know about; nothing to do.
 		return
 	}
 
-	if c.core.ShouldRemove(vi) {
+	if c.excluded(i.ID, rec) {
 		i.DiscardOnSave = true
 	}
 }
 
+// excluded returns true if the interaction with the specified ID has been selected for removal, logging why.
+func (c *Cleaner) excluded(id int, rec *record) bool {
+	provenance, ok := c.core.ProvenanceOf(rec.id)
+	if !ok {
+		return false
+	}
+
+	c.log.Debug(
+		"Removing interaction",
+		"id", id,
+		"analyzer", provenance.Analyzer,
+		"strategy", provenance.Strategy,
+		"url", provenance.URL,
+		"reason", provenance.Reason,
+	)
+
+	return true
+}
+
+// Provenance returns the provenance explaining why the interaction with the specified ID was removed.
+// id is the ID of the interaction within the cassette, as it was when analyzed.
+// Returns false if the interaction is unknown, or was not selected for removal.
+func (c *Cleaner) Provenance(id int) (Provenance, bool) {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	if rec, ok := c.discarded[id]; ok {
+		return c.core.ProvenanceOf(rec.id)
+	}
+
+	rec, ok := c.mapping[id]
+	if !ok {
+		return Provenance{}, false
+	}
+
+	return c.core.ProvenanceOf(rec.id)
+}
+
 // AfterCaptureHook is the hook to be called after an interaction is captured.
 func (c *Cleaner) AfterCaptureHook(i *cassette.Interaction) error {
 	c.padlock.Lock()
 	defer c.padlock.Unlock()
 
-	return c.inspect(i)
+	c.hooked = append(c.hooked, i)
+
+	// go-vcr hooks don't supply a context, so recording can't be cancelled here
+	return c.inspect(context.Background(), i)
 }
 
 // BeforeSaveHook is the hook to be called before an interaction is saved.
+// Saving means the recording has ended, so on the first call analyzers still active are finished and the cleaned
+// sequence of captured interactions validated. If validation fails, every call returns the error and nothing is
+// marked for removal, so the recorder doesn't save the cassette.
 func (c *Cleaner) BeforeSaveHook(i *cassette.Interaction) error {
 	c.padlock.Lock()
 	defer c.padlock.Unlock()
 
+	if !c.saving {
+		c.saving = true
+		c.saveErr = c.completeRecording()
+	}
+
+	if c.saveErr != nil {
+		return c.saveErr
+	}
+
 	c.markIfExcluded(i)
 
 	return nil
 }
+
+// completeRecording finishes analysis of a live recording and validates the interactions captured.
+func (c *Cleaner) completeRecording() error {
+	// go-vcr hooks don't supply a context, so saving can't be cancelled here
+	if err := c.finish(context.Background()); err != nil {
+		return err
+	}
+
+	return c.validate(c.cassetteSteps(c.hooked))
+}
+
+// interactions iterates over every interaction analyzed by the cleaner, including any physically removed from the
+// cassette.
+func (c *Cleaner) interactions() iter.Seq[*record] {
+	return func(yield func(*record) bool) {
+		for _, rec := range c.mapping {
+			if !yield(rec) {
+				return
+			}
+		}
+
+		for _, rec := range c.discarded {
+			if !yield(rec) {
+				return
+			}
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package vcrcleaner
 
 import (
-	"encoding/json"
+	"bytes"
+	"log/slog"
+	"net/http"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
@@ -11,7 +16,7 @@ import (
 	"github.com/sebdah/goldie/v2"
 	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
 
-	"github.com/theunrepentantgeek/go-vcr-tidy/internal/azure"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/jsondoc"
 )
 
 //nolint:funlen // length comes from multiple test cases
@@ -83,7 +88,7 @@ func TestGolden_CleanerClean_givenRecording_removesExpectedInteractions(t *testi
 			// Clean it
 			cleaner := New(log, c.option)
 
-			_, err = cleaner.CleanCassette(cas)
+			_, err = cleaner.CleanCassette(t.Context(), cas)
 			g.Expect(err).NotTo(HaveOccurred(), "cleaning cassette from %s", c.recordingPath)
 
 			// Get summary for the cleaned cassette
@@ -102,25 +107,347 @@ func TestGolden_CleanerClean_givenRecording_removesExpectedInteractions(t *testi
 }
 
 func provisioningState(i *cassette.Interaction) string {
-	var resp azure.ResourceResponse
+	state, _ := jsondoc.Parse([]byte(i.Response.Body)).Text("properties.provisioningState")
 
-	if err := json.Unmarshal([]byte(i.Response.Body), &resp); err != nil {
-		// Not a JSON payload, return empty string
-		return ""
+	return state
+}
+
+func operationStatus(i *cassette.Interaction) string {
+	status, _ := jsondoc.Parse([]byte(i.Response.Body)).Text("status")
+
+	return status
+}
+
+func TestCleanerProvenance_GivenRecording_ExplainsEachRemoval(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	log := slogt.New(t)
+	fp := filepath.Join("testdata", "Test_EventHub_Namespace_v20240101_CRUD")
+
+	cas, err := cassette.Load(fp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cleaner := New(log, ReduceAzureResourceModificationMonitoring())
+
+	modified, err := cleaner.CleanCassette(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	for _, i := range cas.Interactions {
+		provenance, ok := cleaner.Provenance(i.ID)
+		g.Expect(ok).To(Equal(i.DiscardOnSave))
+
+		if ok {
+			g.Expect(provenance.Analyzer).To(Equal("azure.MonitorProvisioningState"))
+			g.Expect(provenance.Strategy).To(Equal(StrategyAzureResourceModification))
+			g.Expect(provenance.URL).To(Equal(baseURL(i)))
+			g.Expect(provenance.Reason).ToNot(BeEmpty())
+		}
 	}
+}
 
-	return resp.Properties.ProvisioningState
+// baseURL returns the URL of the interaction's request without any query parameters.
+func baseURL(i *cassette.Interaction) string {
+	u, _, _ := strings.Cut(i.Request.URL, "?")
+
+	return u
 }
 
-func operationStatus(i *cassette.Interaction) string {
-	var resp struct {
-		Status string `json:"status"`
+func TestCleanerStatistics_GivenRecording_CountsRemovalsByStrategy(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	log := slogt.New(t)
+	fp := filepath.Join("testdata", "Test_Apimanagement_v1api20220801_CreationAndDeletion")
+
+	cas, err := cassette.Load(fp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cleaner := New(log, ReduceAzureLongRunningOperationPolling(), ReduceAzureAsynchronousOperationPolling())
+
+	_, err = cleaner.CleanCassette(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	discarded := 0
+
+	var duration, discardedDuration time.Duration
+
+	for _, i := range cas.Interactions {
+		duration += i.Response.Duration
+
+		if i.DiscardOnSave {
+			discarded++
+			discardedDuration += i.Response.Duration
+		}
+	}
+
+	stats := cleaner.Statistics()
+	g.Expect(stats.Interactions).To(Equal(len(cas.Interactions)))
+	g.Expect(stats.Removed).To(Equal(discarded))
+	g.Expect(stats.Duration).To(Equal(duration))
+	g.Expect(stats.RemovedDuration).To(Equal(discardedDuration))
+	g.Expect(stats.RemainingDuration()).To(Equal(duration - discardedDuration))
+	g.Expect(stats.RemovedByStrategy).To(HaveKey(StrategyAzureLongRunningOperation))
+	g.Expect(stats.RemovedByStrategy).To(HaveKey(StrategyAzureAsynchronousOperation))
+	g.Expect(stats.RemovedByStrategy[StrategyAzureLongRunningOperation] +
+		stats.RemovedByStrategy[StrategyAzureAsynchronousOperation]).To(Equal(discarded))
+}
+
+func TestCleanerZeroPollingDelays_GivenRecording_ZeroesRetryAfterOnRetainedPolls(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	log := slogt.New(t)
+	fp := filepath.Join("testdata", "Test_Apimanagement_v1api20220801_CreationAndDeletion")
+
+	cas, err := cassette.Load(fp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cleaner := New(
+		log,
+		ReduceAzureLongRunningOperationPolling(),
+		ReduceAzureAsynchronousOperationPolling(),
+		ZeroPollingDelays())
+
+	modified, err := cleaner.CleanCassette(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	zeroed := 0
+
+	for _, i := range cas.Interactions {
+		if !i.DiscardOnSave && i.Response.Headers.Get("Retry-After") == "0" {
+			zeroed++
+		}
+	}
+
+	g.Expect(zeroed).To(BeNumerically(">", 0))
+}
+
+func TestCleanerCompressDurations_GivenRecording_CapsRetainedDurations(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	log := slogt.New(t)
+	fp := filepath.Join("testdata", "Test_Apimanagement_v1api20220801_CreationAndDeletion")
+
+	cas, err := cassette.Load(fp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	limit := 50 * time.Millisecond
+	cleaner := New(log, ReduceAzureLongRunningOperationPolling(), CompressDurations(limit))
+
+	modified, err := cleaner.CleanCassette(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	for _, i := range cas.Interactions {
+		g.Expect(i.Response.Duration).To(BeNumerically("<=", limit))
 	}
 
-	if err := json.Unmarshal([]byte(i.Response.Body), &resp); err != nil {
-		// Not a JSON payload, return empty string
-		return ""
+	stats := cleaner.Statistics()
+	g.Expect(stats.CompressedDuration).To(BeNumerically(">", 0))
+	g.Expect(stats.RemainingDuration()).To(BeNumerically("<=", time.Duration(stats.Interactions-stats.Removed)*limit))
+}
+
+func TestCleanerRenumberInteractions_GivenRecording_RemovesAndRenumbers(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	log := slogt.New(t)
+	fp := filepath.Join("testdata", "Test_EventHub_Namespace_v20240101_CRUD")
+
+	cas, err := cassette.Load(fp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	before := len(cas.Interactions)
+	originalIDs := make(map[*cassette.Interaction]int, before)
+
+	for _, i := range cas.Interactions {
+		originalIDs[i] = i.ID
 	}
 
-	return resp.Status
+	cleaner := New(log, ReduceAzureResourceModificationMonitoring(), RenumberInteractions())
+
+	modified, err := cleaner.CleanCassette(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	stats := cleaner.Statistics()
+	g.Expect(stats.Interactions).To(Equal(before))
+	g.Expect(cas.Interactions).To(HaveLen(before - stats.Removed))
+
+	// Remaining interactions are numbered contiguously, in their original order
+	previous := -1
+
+	for index, i := range cas.Interactions {
+		g.Expect(i.ID).To(Equal(index))
+		g.Expect(i.DiscardOnSave).To(BeFalse())
+		g.Expect(originalIDs[i]).To(BeNumerically(">", previous))
+
+		previous = originalIDs[i]
+		delete(originalIDs, i)
+	}
+
+	// Removed interactions can still be explained using their original IDs
+	g.Expect(originalIDs).To(HaveLen(stats.Removed))
+
+	for _, id := range originalIDs {
+		provenance, ok := cleaner.Provenance(id)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(provenance.Strategy).To(Equal(StrategyAzureResourceModification))
+	}
+}
+
+// cleanWithCapturedLog cleans a copy of the named recording with every strategy enabled, returning the debug log
+// output (without timestamps) and the content of the cleaned cassette.
+func cleanWithCapturedLog(t *testing.T, g Gomega, recording string) (string, []byte) {
+	t.Helper()
+
+	path := copyRecording(t, g, recording, "cassette.yaml")
+
+	var buffer bytes.Buffer
+
+	handler := slog.NewTextHandler(
+		&buffer,
+		&slog.HandlerOptions{
+			Level: slog.LevelDebug,
+			ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+
+				return a
+			},
+		})
+
+	cleaner := New(
+		slog.New(handler),
+		ReduceDeferredCreationMonitoring(),
+		ReduceDeleteMonitoring(),
+		ReduceAzureLongRunningOperationPolling(),
+		ReduceAzureAsynchronousOperationPolling(),
+		ReduceAzureResourceModificationMonitoring(),
+		ReduceAzureResourceDeletionMonitoring(),
+		ZeroPollingDelays(),
+	)
+
+	_, err := cleaner.CleanFile(t.Context(), path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	content, err := os.ReadFile(path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	return strings.ReplaceAll(buffer.String(), filepath.Dir(path), "<dir>"), content
+}
+
+func TestCleanFile_RepeatedRuns_ProduceIdenticalLogsAndOutput(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	recording := "Test_AKS_ManagedCluster_20231001_CRUD"
+	expectedLog, expectedContent := cleanWithCapturedLog(t, g, recording)
+
+	for range 5 {
+		log, content := cleanWithCapturedLog(t, g, recording)
+		g.Expect(log).To(Equal(expectedLog))
+		g.Expect(content).To(Equal(expectedContent))
+	}
+}
+
+// unconfirmedDeletion returns a cassette where a DELETE is followed by GETs still finding the resource, with the
+// recording ending before deletion was confirmed.
+func unconfirmedDeletion() *cassette.Cassette {
+	const resourceURL = "https://api.example.com/widgets/1"
+
+	cas := cassette.New("unconfirmed-deletion")
+	cas.AddInteraction(&cassette.Interaction{
+		Request:  cassette.Request{Method: http.MethodDelete, URL: resourceURL},
+		Response: cassette.Response{Code: http.StatusAccepted},
+	})
+
+	for range 4 {
+		cas.AddInteraction(&cassette.Interaction{
+			Request:  cassette.Request{Method: http.MethodGet, URL: resourceURL},
+			Response: cassette.Response{Code: http.StatusOK},
+		})
+	}
+
+	return cas
+}
+
+func TestCleanCassette_RecordingEndsBeforeDeletionConfirmed_CollapsesPolls(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	cas := unconfirmedDeletion()
+	cleaner := New(slogt.New(t), ReduceDeleteMonitoring())
+
+	modified, err := cleaner.CleanCassette(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	// The DELETE and the first and last GETs are retained
+	discarded := make([]int, 0, len(cas.Interactions))
+
+	for _, i := range cas.Interactions {
+		if i.DiscardOnSave {
+			discarded = append(discarded, i.ID)
+		}
+	}
+
+	g.Expect(discarded).To(Equal([]int{2, 3}))
+}
+
+func TestRecorderHooks_RecordingEndsBeforeDeletionConfirmed_CollapsesPolls(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	cas := unconfirmedDeletion()
+	cleaner := New(slogt.New(t), ReduceDeleteMonitoring())
+
+	for _, i := range cas.Interactions {
+		g.Expect(cleaner.AfterCaptureHook(i)).To(Succeed())
+	}
+
+	for _, i := range cas.Interactions {
+		g.Expect(cleaner.BeforeSaveHook(i)).To(Succeed())
+	}
+
+	for _, i := range cas.Interactions {
+		g.Expect(i.DiscardOnSave).To(Equal(i.ID == 2 || i.ID == 3), "interaction %d", i.ID)
+	}
+}
+
+// BenchmarkCleanCassette measures cleaning a large recording with every polling strategy enabled.
+// Each strategy inspects the same response bodies, so this shows the benefit of parsing each body only once.
+func BenchmarkCleanCassette(b *testing.B) {
+	log := slog.New(slog.DiscardHandler)
+	fp := filepath.Join("testdata", "Test_Apimanagement_v1api20220801_CreationAndDeletion")
+
+	for b.Loop() {
+		b.StopTimer()
+
+		cas, err := cassette.Load(fp)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		cleaner := New(
+			log,
+			ReduceDeferredCreationMonitoring(),
+			ReduceDeleteMonitoring(),
+			ReduceAzureLongRunningOperationPolling(),
+			ReduceAzureAsynchronousOperationPolling(),
+			ReduceAzureResourceModificationMonitoring(),
+			ReduceAzureResourceDeletionMonitoring(),
+			ReduceAzureAsynchronousOperationMonitoring())
+
+		b.StartTimer()
+
+		if _, err := cleaner.CleanCassette(b.Context(), cas); err != nil {
+			b.Fatal(err)
+		}
+	}
 }
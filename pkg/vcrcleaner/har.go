@@ -0,0 +1,218 @@
+package vcrcleaner
+
+import (
+	"context"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/har"
+)
+
+// cleanHARFile processes a single HTTP Archive, removing unnecessary entries.
+// Returns true if the file was modified and saved, false if no changes were made.
+func (c *Cleaner) cleanHARFile(
+	ctx context.Context,
+	path string,
+) (bool, error) {
+	archive, ok := c.loadHAR(path)
+	if !ok {
+		return false, nil
+	}
+
+	c.log.Info("Checking HTTP archive", "path", path)
+
+	modified, err := c.cleanHAR(ctx, archive)
+	if err != nil {
+		return false, eris.Wrapf(err, "cleaning HTTP archive from %s", path)
+	}
+
+	if modified {
+		if err := ctx.Err(); err != nil {
+			return false, eris.Wrapf(err, "cancelled before saving cleaned HTTP archive to %s", path)
+		}
+
+		err = archive.Save(path)
+		if err != nil {
+			return false, eris.Wrapf(err, "saving cleaned HTTP archive to %s", path)
+		}
+
+		c.log.Info("Modified HTTP archive", "path", path)
+	} else {
+		c.log.Log(ctx, LevelVerbose, "No change to HTTP archive", "path", path)
+	}
+
+	return modified, nil
+}
+
+// loadHAR attempts to load an HTTP Archive from the specified path.
+// Returns false if the file can't be loaded, logging a warning.
+func (c *Cleaner) loadHAR(
+	path string,
+) (*har.HAR, bool) {
+	archive, err := har.Load(path)
+	if err != nil {
+		c.log.Warn("Skipping invalid HTTP archive", "path", path, "error", err)
+
+		return nil, false
+	}
+
+	c.log.Debug("Loaded HTTP archive", "path", path, "entries", len(archive.Log.Entries))
+
+	return archive, true
+}
+
+// cleanHAR processes an HTTP Archive, removing entries selected for removal.
+// Entries are identified by their index within the archive when analyzed.
+// Returns true if any entries were removed or otherwise changed, false otherwise, along with any error encountered.
+func (c *Cleaner) cleanHAR(ctx context.Context, archive *har.HAR) (bool, error) {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	// Scan all entries
+	for index, entry := range archive.Log.Entries {
+		if err := c.inspectEntry(ctx, index, entry); err != nil {
+			return false, eris.Wrapf(err, "inspecting entry %d", index)
+		}
+	}
+
+	if err := c.finish(ctx); err != nil {
+		return false, err
+	}
+
+	if err := c.validate(c.harSteps(archive.Log.Entries)); err != nil {
+		return false, err
+	}
+
+	// HAR has no equivalent of DiscardOnSave, so excluded entries are removed immediately
+	modified := false
+	retained := make([]*har.Entry, 0, len(archive.Log.Entries))
+
+	for index, entry := range archive.Log.Entries {
+		rec := c.mapping[index]
+		if c.excluded(index, rec) {
+			modified = true
+
+			continue
+		}
+
+		modified = modified || rec.modified
+		retained = append(retained, entry)
+	}
+
+	archive.Log.Entries = retained
+
+	return modified, nil
+}
+
+// inspectEntry processes a single HTTP Archive entry through the cleaner.
+func (c *Cleaner) inspectEntry(ctx context.Context, index int, entry *har.Entry) error {
+	hi, err := newHARInteraction(entry)
+	if err != nil {
+		return eris.Wrapf(err, "adapting entry %d", index)
+	}
+
+	c.mapping[index] = hi.record
+
+	err = c.core.Analyze(ctx, c.log, hi)
+	if err != nil {
+		return eris.Wrapf(err, "analyzing entry %d", index)
+	}
+
+	return nil
+}
+
+// planHARFile loads the HTTP Archive at the specified path and plans how it would be cleaned.
+func (c *Cleaner) planHARFile(
+	ctx context.Context,
+	path string,
+) (*Plan, error) {
+	archive, ok := c.loadHAR(path)
+	if !ok {
+		return &Plan{Path: path}, nil
+	}
+
+	c.log.Info("Planning HTTP archive", "path", path)
+
+	plan, err := c.planHAR(ctx, archive)
+	if err != nil {
+		return nil, eris.Wrapf(err, "planning HTTP archive from %s", path)
+	}
+
+	plan.Path = path
+
+	return plan, nil
+}
+
+// planHAR analyzes copies of the entries in an HTTP Archive, returning a plan describing which would be kept and
+// which removed.
+func (c *Cleaner) planHAR(ctx context.Context, archive *har.HAR) (*Plan, error) {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	copies := make([]*har.Entry, 0, len(archive.Log.Entries))
+
+	// Scan copies of all entries, as analyzers may modify headers and durations
+	for index, entry := range archive.Log.Entries {
+		copied := copyEntry(entry)
+		copies = append(copies, copied)
+
+		if err := c.inspectEntry(ctx, index, copied); err != nil {
+			return nil, eris.Wrapf(err, "inspecting entry %d", index)
+		}
+	}
+
+	if err := c.finish(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := c.validate(c.harSteps(copies)); err != nil {
+		return nil, err
+	}
+
+	result := &Plan{
+		Interactions: make([]PlannedInteraction, 0, len(copies)),
+	}
+
+	for index, entry := range copies {
+		planned := PlannedInteraction{
+			ID:         index,
+			Method:     entry.Request.Method,
+			URL:        entry.Request.URL,
+			StatusCode: entry.Response.Status,
+		}
+
+		rec := c.mapping[index]
+		planned.Provenance, planned.Remove = c.core.ProvenanceOf(rec.id)
+		planned.Rewrite = rec.modified && !planned.Remove
+
+		result.Interactions = append(result.Interactions, planned)
+	}
+
+	return result, nil
+}
+
+// copyEntry returns a copy of the entry that can be modified without affecting the original.
+func copyEntry(entry *har.Entry) *har.Entry {
+	result := *entry
+	result.Request.Headers = append([]har.NameValue(nil), entry.Request.Headers...)
+	result.Response.Headers = append([]har.NameValue(nil), entry.Response.Headers...)
+
+	// Durations may be compressed, which rescales the timings
+	result.Timings.Blocked = copyTiming(entry.Timings.Blocked)
+	result.Timings.DNS = copyTiming(entry.Timings.DNS)
+	result.Timings.Connect = copyTiming(entry.Timings.Connect)
+	result.Timings.SSL = copyTiming(entry.Timings.SSL)
+
+	return &result
+}
+
+// copyTiming returns a copy of an optional timing.
+func copyTiming(timing *float64) *float64 {
+	if timing == nil {
+		return nil
+	}
+
+	result := *timing
+
+	return &result
+}
@@ -0,0 +1,241 @@
+package vcrcleaner
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rotisserie/eris"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/har"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/jsondoc"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/urltool"
+)
+
+// harInteraction adapts an entry from an HTTP Archive so it can be analyzed like a cassette interaction.
+type harInteraction struct {
+	entry    *har.Entry
+	url      *url.URL // Parsed URL of the request
+	request  harRequest
+	response harResponse
+	record   *record // Outcome of analysis, tracked by the cleaner
+}
+
+var _ interaction.Interface = &harInteraction{}
+
+// newHARInteraction adapts the specified entry.
+// Returns an error if the request URL of the entry can't be parsed.
+func newHARInteraction(entry *har.Entry) (*harInteraction, error) {
+	u, err := url.Parse(entry.Request.URL)
+	if err != nil {
+		return nil, eris.Wrap(err, "parsing request URL")
+	}
+
+	result := &harInteraction{
+		entry:  entry,
+		url:    u,
+		record: newRecord(entry.Duration()),
+	}
+
+	result.request = harRequest{parent: result}
+	result.response = harResponse{parent: result}
+
+	return result, nil
+}
+
+// ID is a unique identifier for the interaction.
+func (h *harInteraction) ID() uuid.UUID {
+	return h.record.id
+}
+
+// Request returns the request portion of the interaction.
+func (h *harInteraction) Request() interaction.Request {
+	return &h.request
+}
+
+// Response returns the response portion of the interaction.
+func (h *harInteraction) Response() interaction.Response {
+	return &h.response
+}
+
+// markModified records that an analyzer has changed the entry.
+func (h *harInteraction) markModified() {
+	h.record.modified = true
+	h.record.duration = h.entry.Duration()
+}
+
+// harRequest represents the request portion of a HAR entry.
+type harRequest struct {
+	parent *harInteraction
+}
+
+// FullURL returns the full URL of the request.
+// A copy is returned, so callers can't change the URL parsed when the entry was adapted.
+func (r *harRequest) FullURL() *url.URL {
+	result := *r.parent.url
+
+	return &result
+}
+
+// BaseURL returns the base URL of the request (without query parameters or fragment).
+func (r *harRequest) BaseURL() *url.URL {
+	return urltool.BaseURL(r.FullURL())
+}
+
+// Method returns the HTTP method of the request.
+func (r *harRequest) Method() string {
+	return r.parent.entry.Request.Method
+}
+
+// Header returns the value of the specified request header.
+func (r *harRequest) Header(name string) (string, bool) {
+	return findHeader(r.parent.entry.Request.Headers, name)
+}
+
+// Headers returns a copy of all the request headers.
+func (r *harRequest) Headers() http.Header {
+	return r.parent.entry.Request.Header()
+}
+
+// Body returns the body of the request.
+func (r *harRequest) Body() []byte {
+	return []byte(r.parent.entry.Request.Body())
+}
+
+// harResponse represents the response portion of a HAR entry.
+type harResponse struct {
+	parent   *harInteraction
+	document *jsondoc.Document // Lazily parsed JSON body, shared by all analyzers
+}
+
+// StatusCode returns the HTTP status code of the response.
+func (r *harResponse) StatusCode() int {
+	return r.parent.entry.Response.Status
+}
+
+// Header returns the value of the specified response header.
+func (r *harResponse) Header(name string) (string, bool) {
+	return findHeader(r.parent.entry.Response.Headers, name)
+}
+
+// SetHeader sets the value of the specified response header, replacing any existing values.
+func (r *harResponse) SetHeader(name string, value string) {
+	headers := r.parent.entry.Response.Headers
+
+	matches := 0
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, name) {
+			matches++
+		}
+	}
+
+	if existing, ok := findHeader(headers, name); ok && matches == 1 && existing == value {
+		// No change needed
+		return
+	}
+
+	// Replace the first occurrence in place, to keep the order of headers stable
+	result := make([]har.NameValue, 0, len(headers)+1)
+	replaced := false
+
+	for _, h := range headers {
+		if !strings.EqualFold(h.Name, name) {
+			result = append(result, h)
+		} else if !replaced {
+			h.Value = value
+			result = append(result, h)
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		result = append(result, har.NameValue{Name: name, Value: value})
+	}
+
+	r.parent.entry.Response.Headers = result
+	r.parent.markModified()
+}
+
+// RemoveHeader removes the specified response header.
+func (r *harResponse) RemoveHeader(name string) {
+	headers := r.parent.entry.Response.Headers
+
+	result := make([]har.NameValue, 0, len(headers))
+	for _, h := range headers {
+		if !strings.EqualFold(h.Name, name) {
+			result = append(result, h)
+		}
+	}
+
+	if len(result) == len(headers) {
+		// No change needed
+		return
+	}
+
+	r.parent.entry.Response.Headers = result
+	r.parent.markModified()
+}
+
+// Body returns the body of the response.
+func (r *harResponse) Body() []byte {
+	return r.parent.entry.Response.Body()
+}
+
+// Duration returns the total time taken by the request when it was recorded.
+func (r *harResponse) Duration() time.Duration {
+	return r.parent.entry.Duration()
+}
+
+// SetDuration changes the recorded duration of the entry.
+func (r *harResponse) SetDuration(duration time.Duration) {
+	if r.parent.entry.Duration() == duration {
+		// No change needed
+		return
+	}
+
+	r.parent.entry.SetDuration(duration)
+	r.parent.markModified()
+}
+
+// Timestamp returns when the response was received, calculated from when the request started.
+// Falls back to the Date header if the entry has no start time.
+func (r *harResponse) Timestamp() (time.Time, bool) {
+	if started, ok := r.parent.entry.Started(); ok {
+		return started.Add(r.parent.entry.Duration()), true
+	}
+
+	value, ok := r.Header("Date")
+	if !ok {
+		return time.Time{}, false
+	}
+
+	result, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return result, true
+}
+
+// JSON returns the body of the response parsed as JSON, parsing it on first use.
+func (r *harResponse) JSON() *jsondoc.Document {
+	if r.document == nil {
+		r.document = jsondoc.Parse(r.Body())
+	}
+
+	return r.document
+}
+
+// findHeader returns the first value of the named header, matching names case-insensitively.
+func findHeader(headers []har.NameValue, name string) (string, bool) {
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value, true
+		}
+	}
+
+	return "", false
+}
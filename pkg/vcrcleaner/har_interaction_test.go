@@ -0,0 +1,84 @@
+package vcrcleaner
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/har"
+)
+
+func TestHARResponseSetHeader_WithDifferentCase_ReplacesInPlace(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	i, err := newHARInteraction(&har.Entry{
+		Response: har.Response{
+			Headers: []har.NameValue{
+				{Name: "content-type", Value: "application/json"},
+				{Name: "retry-after", Value: "10"},
+				{Name: "date", Value: "Tue, 04 Mar 2025 21:30:15 GMT"},
+			},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	i.Response().SetHeader("Retry-After", "0")
+
+	g.Expect(i.record.modified).To(BeTrue())
+	g.Expect(i.entry.Response.Headers).To(Equal([]har.NameValue{
+		{Name: "content-type", Value: "application/json"},
+		{Name: "retry-after", Value: "0"},
+		{Name: "date", Value: "Tue, 04 Mar 2025 21:30:15 GMT"},
+	}))
+}
+
+func TestHARResponseSetHeader_WithSameValue_LeavesInteractionUnmodified(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	i, err := newHARInteraction(&har.Entry{
+		Response: har.Response{
+			Headers: []har.NameValue{
+				{Name: "retry-after", Value: "0"},
+			},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	i.Response().SetHeader("Retry-After", "0")
+	i.Response().RemoveHeader("Location")
+
+	g.Expect(i.record.modified).To(BeFalse())
+}
+
+func TestHARResponseTimestamp_WithStartTime_ReturnsCompletion(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	i, err := newHARInteraction(&har.Entry{
+		StartedDateTime: "2025-03-04T21:30:15Z",
+		Time:            1500,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	timestamp, ok := i.Response().Timestamp()
+
+	g.Expect(ok).To(BeTrue())
+	expected := time.Date(2025, time.March, 4, 21, 30, 16, 500*int(time.Millisecond), time.UTC)
+	g.Expect(timestamp).To(BeTemporally("==", expected))
+}
+
+func TestNewHARInteraction_GivenInvalidURL_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	_, err := newHARInteraction(&har.Entry{
+		Request: har.Request{
+			URL: "https://example.com/%zz",
+		},
+	})
+
+	g.Expect(err).To(MatchError(ContainSubstring("parsing request URL")))
+}
@@ -0,0 +1,122 @@
+package vcrcleaner
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/har"
+)
+
+func TestCleanFile_GivenHAR_RemovesSameInteractionsAsCassette(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	recording := "Test_EventHub_Namespace_v20240101_CRUD"
+	path := saveAsHAR(t, g, recording)
+
+	cas, err := cassette.Load(filepath.Join("testdata", recording))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	_, err = New(slogt.New(t), ReduceAzureResourceModificationMonitoring()).CleanCassette(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var expected []string
+
+	for _, i := range cas.Interactions {
+		if !i.DiscardOnSave {
+			expected = append(expected, i.Request.Method+" "+i.Request.URL)
+		}
+	}
+
+	cleaner := New(slogt.New(t), ReduceAzureResourceModificationMonitoring())
+	modified, err := cleaner.CleanFile(t.Context(), path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	archive, err := har.Load(path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	actual := make([]string, 0, len(archive.Log.Entries))
+	for _, entry := range archive.Log.Entries {
+		actual = append(actual, entry.Request.Method+" "+entry.Request.URL)
+	}
+
+	g.Expect(actual).To(Equal(expected))
+
+	stats := cleaner.Statistics()
+	g.Expect(stats.Interactions).To(Equal(len(cas.Interactions)))
+	g.Expect(stats.Removed).To(Equal(len(cas.Interactions) - len(expected)))
+}
+
+func TestCleanFile_GivenHARWithCompressedDurations_RewritesTimings(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	path := saveAsHAR(t, g, "Test_EventHub_Namespace_v20240101_CRUD")
+
+	cleaner := New(slogt.New(t), CompressDurations(time.Millisecond))
+	modified, err := cleaner.CleanFile(t.Context(), path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	archive, err := har.Load(path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	for _, entry := range archive.Log.Entries {
+		g.Expect(entry.Duration()).To(BeNumerically("<=", time.Millisecond))
+		g.Expect(entry.Timings.Wait).To(BeNumerically("<=", entry.Time))
+	}
+}
+
+func TestPlanFile_GivenHAR_MatchesCleanFile(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	path := saveAsHAR(t, g, "Test_EventHub_Namespace_v20240101_CRUD")
+
+	plan, err := New(slogt.New(t), ReduceAzureResourceModificationMonitoring()).PlanFile(t.Context(), path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(plan.Path).To(Equal(path))
+
+	before, err := har.Load(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(plan.Interactions).To(HaveLen(len(before.Log.Entries)))
+
+	cleaner := New(slogt.New(t), ReduceAzureResourceModificationMonitoring())
+	_, err = cleaner.CleanFile(t.Context(), path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	after, err := har.Load(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(plan.Removals()).To(Equal(len(before.Log.Entries) - len(after.Log.Entries)))
+
+	for _, planned := range plan.Interactions {
+		provenance, ok := cleaner.Provenance(planned.ID)
+		g.Expect(ok).To(Equal(planned.Remove))
+		g.Expect(provenance).To(Equal(planned.Provenance))
+	}
+}
+
+func TestCleanFile_GivenHARWithInvalidURL_SkipsArchive(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	archive := har.New()
+	archive.Log.Entries = []*har.Entry{
+		{Request: har.Request{Method: "GET", URL: "https://management.azure.com/%zz"}},
+	}
+
+	path := filepath.Join(t.TempDir(), "invalid.har")
+	g.Expect(archive.Save(path)).To(Succeed())
+
+	modified, err := New(slogt.New(t), ReduceAzureResourceModificationMonitoring()).CleanFile(t.Context(), path)
+
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeFalse())
+}
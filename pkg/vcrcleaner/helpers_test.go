@@ -1,12 +1,19 @@
 package vcrcleaner
 
 import (
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
 
 	"github.com/sergi/go-diff/diffmatchpatch"
 	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
 
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/cassettefile"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/har"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/report"
 )
 
@@ -117,3 +124,44 @@ func withColumn(
 		fn:     fn,
 	}
 }
+
+// copyRecording copies the named test recording into a temporary directory, returning the path of the copy.
+func copyRecording(t *testing.T, g Gomega, recording string, name string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", recording+".yaml"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	path := filepath.Join(t.TempDir(), name)
+	g.Expect(os.WriteFile(path, content, 0o600)).To(Succeed())
+
+	return path
+}
+
+// copyLegacyRecording copies a recording into a temporary directory, rewritten in the format used by go-vcr v1 and v2.
+func copyLegacyRecording(t *testing.T, g Gomega, recording string, name string) string {
+	t.Helper()
+
+	path := copyRecording(t, g, recording, name)
+
+	file, err := cassettefile.Load(path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	file.Version = cassettefile.LegacyVersion
+	g.Expect(file.SaveAs(path, file.Encoding)).To(Succeed())
+
+	return path
+}
+
+// saveAsHAR converts the named test recording into an HTTP archive in a temporary directory, returning its path.
+func saveAsHAR(t *testing.T, g Gomega, recording string) string {
+	t.Helper()
+
+	cas, err := cassette.Load(filepath.Join("testdata", recording))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	path := filepath.Join(t.TempDir(), recording+".har")
+	g.Expect(har.FromCassette(cas).Save(path)).To(Succeed())
+
+	return path
+}
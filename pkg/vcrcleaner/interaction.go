@@ -8,18 +8,18 @@ import (
 )
 
 type vcrInteraction struct {
-	interactionID uuid.UUID
-	interaction   *cassette.Interaction
-	request       vcrRequest
-	response      vcrResponse
+	interaction *cassette.Interaction
+	request     vcrRequest
+	response    vcrResponse
+	record      *record // Outcome of analysis, tracked by the cleaner
 }
 
 var _ interaction.Interface = &vcrInteraction{}
 
 func newVCRInteraction(i *cassette.Interaction) *vcrInteraction {
 	result := &vcrInteraction{
-		interactionID: uuid.New(),
-		interaction:   i,
+		interaction: i,
+		record:      newRecord(i.Response.Duration),
 	}
 
 	result.request = vcrRequest{parent: result}
@@ -30,7 +30,7 @@ func newVCRInteraction(i *cassette.Interaction) *vcrInteraction {
 
 // ID is a unique identifier for the interaction.
 func (v *vcrInteraction) ID() uuid.UUID {
-	return v.interactionID
+	return v.record.id
 }
 
 // Request returns the request portion of the interaction.
@@ -40,3 +40,10 @@ func (v *vcrInteraction) Request() interaction.Request {
 
 // Response returns the response portion of the interaction.
 func (v *vcrInteraction) Response() interaction.Response { return &v.response }
+
+// markModified records that an analyzer has changed the interaction, capturing the changes made.
+func (v *vcrInteraction) markModified() {
+	v.record.modified = true
+	v.record.headers = v.interaction.Response.Headers
+	v.record.duration = v.interaction.Response.Duration
+}
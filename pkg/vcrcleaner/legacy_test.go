@@ -0,0 +1,83 @@
+package vcrcleaner
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/cassettefile"
+)
+
+func TestCleanFile_WithLegacyCassette_RemovesSameInteractions(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	recording := "Test_Apimanagement_v1api20220801_CreationAndDeletion"
+	current := copyRecording(t, g, recording, "current.yaml")
+	legacy := copyLegacyRecording(t, g, recording, "legacy.yaml")
+
+	options := []Option{
+		ReduceAzureLongRunningOperationPolling(),
+		ReduceAzureAsynchronousOperationPolling(),
+	}
+
+	expected := New(slogt.New(t), options...)
+	modified, err := expected.CleanFile(t.Context(), current)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	actual := New(slogt.New(t), options...)
+	modified, err = actual.CleanFile(t.Context(), legacy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	g.Expect(actual.Statistics()).To(Equal(expected.Statistics()))
+
+	// The cassette is saved in its original format
+	cleaned, err := cassettefile.Load(legacy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cleaned.Version).To(Equal(cassettefile.LegacyVersion))
+	g.Expect(cleaned.Cassette.Interactions).To(HaveLen(
+		expected.Statistics().Interactions - expected.Statistics().Removed))
+}
+
+func TestCleanFile_WithUpgradeFormat_RewritesLegacyCassette(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	path := copyLegacyRecording(t, g, "Test_EventHub_Namespace_v20240101_CRUD", "legacy.yaml")
+
+	// No strategies are needed for the cassette to be upgraded
+	cleaner := New(slogt.New(t), UpgradeFormat())
+	modified, err := cleaner.CleanFile(t.Context(), path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	upgraded, err := cassettefile.Load(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(upgraded.Version).To(Equal(cassettefile.CurrentVersion))
+	g.Expect(upgraded.Cassette.Interactions).To(HaveLen(cleaner.Statistics().Interactions))
+}
+
+func TestPlanFile_WithUpgradeFormat_ReportsUpgrade(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	recording := "Test_EventHub_Namespace_v20240101_CRUD"
+	legacy := copyLegacyRecording(t, g, recording, "legacy.yaml")
+	current := copyRecording(t, g, recording, "current.yaml")
+
+	plan, err := New(slogt.New(t), UpgradeFormat()).PlanFile(t.Context(), legacy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(plan.Upgrade).To(BeTrue())
+
+	plan, err = New(slogt.New(t), UpgradeFormat()).PlanFile(t.Context(), current)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(plan.Upgrade).To(BeFalse())
+
+	plan, err = New(slogt.New(t)).PlanFile(t.Context(), legacy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(plan.Upgrade).To(BeFalse())
+}
@@ -1,60 +1,161 @@
 package vcrcleaner
 
 import (
+	"time"
+
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/azure"
-	"github.com/theunrepentantgeek/go-vcr-tidy/internal/cleaner"
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/generic"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/retention"
 )
 
 // Option represents a configuration option for the Cleaner.
-type Option func(*cleaner.Cleaner)
+type Option func(*Cleaner)
+
+// RetentionPolicy determines which interactions of a collapsed polling sequence are retained: the First N, the
+// Last M, and (if positive) Every Kth interaction. The default policy retains the first and last interactions.
+type RetentionPolicy = retention.Policy
+
+// DefaultRetentionPolicy returns the retention policy used unless another is configured.
+func DefaultRetentionPolicy() RetentionPolicy {
+	return retention.DefaultPolicy()
+}
+
+// ParseRetentionPolicy reads a retention policy from a specification such as "first:2,last:1,every:5".
+// Any values not specified are taken from the default policy.
+func ParseRetentionPolicy(spec string) (RetentionPolicy, error) {
+	return retention.Parse(spec)
+}
+
+// Names of the cleaning strategies, as reported in the Provenance of each removed interaction.
+const (
+	StrategyDeferredCreation           = "deferred-creation"
+	StrategyDeletion                   = "deletion"
+	StrategyAzureLongRunningOperation  = "azure-long-running-operation"
+	StrategyAzureAsynchronousOperation = "azure-asynchronous-operation"
+	StrategyAzureResourceModification  = "azure-resource-modification"
+	StrategyAzureResourceDeletion      = "azure-resource-deletion"
+
+	// StrategyPollingRulePrefix prefixes the name of a declarative polling rule to give its strategy name.
+	StrategyPollingRulePrefix = "polling-rule:"
+)
 
 // ReduceDeferredCreationMonitoring adds an analyzer that reduces deferred creation monitoring noise.
 func ReduceDeferredCreationMonitoring() Option {
-	return func(c *cleaner.Cleaner) {
-		c.AddAnalyzers(generic.NewDetectDeferredCreation())
+	return func(c *Cleaner) {
+		c.core.AddStrategy(StrategyDeferredCreation, generic.NewDetectDeferredCreation())
 	}
 }
 
 // ReduceDeleteMonitoring adds an analyzer that reduces delete monitoring noise.
 func ReduceDeleteMonitoring() Option {
-	return func(c *cleaner.Cleaner) {
-		c.AddAnalyzers(generic.NewDetectDeletion())
+	return func(c *Cleaner) {
+		c.core.AddStrategy(StrategyDeletion, generic.NewDetectDeletion())
 	}
 }
 
 func ReduceAzureLongRunningOperationPolling() Option {
-	return func(c *cleaner.Cleaner) {
-		c.AddAnalyzers(azure.NewDetectAzureLongRunningOperation())
+	return func(c *Cleaner) {
+		c.core.AddStrategy(StrategyAzureLongRunningOperation, azure.NewDetectAzureLongRunningOperation())
 	}
 }
 
 func ReduceAzureAsynchronousOperationPolling() Option {
-	return func(c *cleaner.Cleaner) {
-		c.AddAnalyzers(azure.NewDetectAzureAsynchronousOperation())
+	return func(c *Cleaner) {
+		c.core.AddStrategy(StrategyAzureAsynchronousOperation, azure.NewDetectAzureAsynchronousOperation())
 	}
 }
 
 // ReduceAzureResourceModificationMonitoring adds an analyzer that reduces Azure resource modification monitoring.
 // This analyzer watches for PUT and PATCH requests and monitors subsequent GET requests for Creating/Updating states.
 func ReduceAzureResourceModificationMonitoring() Option {
-	return func(c *cleaner.Cleaner) {
-		c.AddAnalyzers(azure.NewDetectResourceModification())
+	return func(c *Cleaner) {
+		c.core.AddStrategy(StrategyAzureResourceModification, azure.NewDetectResourceModification())
 	}
 }
 
 // ReduceAzureResourceDeletionMonitoring adds an analyzer that reduces Azure resource deletion monitoring.
 // This analyzer watches for DELETE requests and monitors subsequent GET requests for Deleting state.
 func ReduceAzureResourceDeletionMonitoring() Option {
-	return func(c *cleaner.Cleaner) {
-		c.AddAnalyzers(azure.NewDetectResourceDeletion())
+	return func(c *Cleaner) {
+		c.core.AddStrategy(StrategyAzureResourceDeletion, azure.NewDetectResourceDeletion())
 	}
 }
 
 // ReduceAzureAsynchronousOperationMonitoring adds an analyzer that reduces Azure asynchronous operation monitoring.
 // This analyzer watches for asynchronous operation polling interactions.
 func ReduceAzureAsynchronousOperationMonitoring() Option {
-	return func(c *cleaner.Cleaner) {
-		c.AddAnalyzers(azure.NewDetectAzureAsynchronousOperation())
+	return func(c *Cleaner) {
+		c.core.AddStrategy(StrategyAzureAsynchronousOperation, azure.NewDetectAzureAsynchronousOperation())
+	}
+}
+
+// ReducePollingByRules adds an analyzer for each of the supplied declarative polling rules, reducing the polling
+// each describes. The strategy reported for each removal is "polling-rule:" followed by the name of the rule.
+func ReducePollingByRules(rules *PollingRules) Option {
+	return func(c *Cleaner) {
+		for _, detector := range rules.detectors {
+			c.core.AddStrategy(StrategyPollingRulePrefix+detector.Name(), detector)
+		}
+	}
+}
+
+// ZeroPollingDelays rewrites delay hints (Retry-After, Retry-After-Ms and x-ms-retry-after-ms) to zero on each
+// polling sequence cleaned: the request starting it (such as a PUT returning 202), the polls retained and the final
+// poll. Clients (such as the Azure SDK poller) replaying the recording then don't sleep before or between polls.
+// Location and Azure-AsyncOperation headers are still relinked as usual.
+func ZeroPollingDelays() Option {
+	return func(c *Cleaner) {
+		c.core.SetZeroDelays(true)
+	}
+}
+
+// CompressDurations caps the recorded duration of retained interactions at the specified limit, so that replays
+// using go-vcr latency simulation run faster. A limit of zero removes simulated latency entirely.
+func CompressDurations(limit time.Duration) Option {
+	return func(c *Cleaner) {
+		c.core.AddAnalyzers(generic.NewCompressDurations(limit))
+	}
+}
+
+// RenumberInteractions physically removes discarded interactions from the cassette when it is cleaned, rather than
+// just marking them DiscardOnSave, and renumbers the remaining interactions so their IDs are contiguous.
+// Provenance remains available for removed interactions, using the ID they had when analyzed.
+// This only matters to callers inspecting the cassette in memory after CleanCassette returns: saving a cassette
+// (with go-vcr or with Cleaner.CleanFile) already drops discarded interactions and renumbers the rest, so the saved
+// file is the same either way. For that reason, no command line flag enables it.
+func RenumberInteractions() Option {
+	return func(c *Cleaner) {
+		c.renumber = true
+	}
+}
+
+// UpgradeFormat rewrites cassettes recorded by go-vcr v1 and v2 (format version 1) in the current format when they're
+// cleaned, even if no interactions are removed. Without this option, such cassettes are saved in their original format.
+func UpgradeFormat() Option {
+	return func(c *Cleaner) {
+		c.upgrade = true
+	}
+}
+
+// Streaming processes YAML cassettes without loading them into memory, for very large cassettes.
+// Each interaction is decoded and analyzed in turn, keeping only a small record of the outcome, and the cassette is
+// then rewritten in a second pass. Cassettes stored in other formats are loaded as usual.
+func Streaming() Option {
+	return func(c *Cleaner) {
+		c.streaming = true
+	}
+}
+
+// WithRetention sets the retention policy used by all strategies that don't have a policy of their own.
+func WithRetention(policy RetentionPolicy) Option {
+	return func(c *Cleaner) {
+		c.core.SetRetention("", policy)
+	}
+}
+
+// WithStrategyRetention sets the retention policy used by the named strategy, e.g. StrategyDeletion.
+func WithStrategyRetention(strategy string, policy RetentionPolicy) Option {
+	return func(c *Cleaner) {
+		c.core.SetRetention(strategy, policy)
 	}
 }
@@ -0,0 +1,155 @@
+package vcrcleaner
+
+import (
+	"context"
+
+	"github.com/rotisserie/eris"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/cassettefile"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/har"
+)
+
+// Plan describes the changes that cleaning would make to a cassette, without making them.
+type Plan struct {
+	// Path is the path of the cassette file the plan applies to.
+	Path string
+	// Interactions lists every interaction in the cassette, in order, with the planned outcome for each.
+	Interactions []PlannedInteraction
+	// Upgrade is true if the cassette was recorded by an older version of go-vcr and would be rewritten in the
+	// current format.
+	Upgrade bool
+}
+
+// PlannedInteraction describes the planned outcome for a single interaction.
+type PlannedInteraction struct {
+	ID         int    // ID of the interaction within the cassette
+	Method     string // HTTP method of the request
+	URL        string // Full URL of the request
+	StatusCode int    // HTTP status code of the response
+	Remove     bool   // True if the interaction would be removed
+	Rewrite    bool   // True if the interaction would be kept, but changed (e.g. by zeroing delay hints)
+	// Provenance explains why the interaction would be removed; zero if it would be kept
+	Provenance Provenance
+}
+
+// Removals returns the number of interactions that would be removed.
+func (p *Plan) Removals() int {
+	result := 0
+
+	for _, i := range p.Interactions {
+		if i.Remove {
+			result++
+		}
+	}
+
+	return result
+}
+
+// Rewrites returns the number of interactions that would be kept, but changed.
+func (p *Plan) Rewrites() int {
+	result := 0
+
+	for _, i := range p.Interactions {
+		if i.Rewrite && !i.Remove {
+			result++
+		}
+	}
+
+	return result
+}
+
+// PlanFile loads the cassette file at the specified path and plans how it would be cleaned.
+// The file is never modified.
+// If the file can't be loaded as a cassette, an empty plan is returned.
+// HTTP Archives (.har) are also supported, with each entry identified by its index.
+// Returns an error if ctx is cancelled before planning is complete.
+func (c *Cleaner) PlanFile(
+	ctx context.Context,
+	path string,
+) (*Plan, error) {
+	if har.IsHAR(path) {
+		return c.planHARFile(ctx, path)
+	}
+
+	if c.streams(path) {
+		return c.planStream(ctx, path)
+	}
+
+	file, ok := c.loadCassette(path)
+	if !ok {
+		return &Plan{Path: path}, nil
+	}
+
+	c.log.Info("Planning cassette", "path", path)
+
+	plan, err := c.Plan(ctx, file.Cassette)
+	if err != nil {
+		return nil, eris.Wrapf(err, "planning cassette from %s", path)
+	}
+
+	plan.Path = path
+	plan.Upgrade = c.upgrade && file.Version != cassettefile.CurrentVersion
+
+	return plan, nil
+}
+
+// Plan analyzes a cassette and returns a plan describing which interactions would be kept and which removed.
+// Analysis happens on copies of the interactions, so the cassette itself is left untouched.
+// As with CleanCassette, each Cleaner should be used for a single cassette, and analysis stops if ctx is cancelled.
+func (c *Cleaner) Plan(ctx context.Context, cas *cassette.Cassette) (*Plan, error) {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	copies := make([]*cassette.Interaction, 0, len(cas.Interactions))
+
+	// Scan copies of all interactions, as analyzers may modify headers
+	for _, i := range cas.Interactions {
+		copied := copyInteraction(i)
+		copies = append(copies, copied)
+
+		if err := c.inspect(ctx, copied); err != nil {
+			return nil, eris.Wrapf(err, "inspecting interaction %d", i.ID)
+		}
+	}
+
+	if err := c.finish(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := c.validate(c.cassetteSteps(copies)); err != nil {
+		return nil, err
+	}
+
+	result := &Plan{
+		Path:         cas.File,
+		Interactions: make([]PlannedInteraction, 0, len(copies)),
+	}
+
+	for _, i := range copies {
+		planned := PlannedInteraction{
+			ID:         i.ID,
+			Method:     i.Request.Method,
+			URL:        i.Request.URL,
+			StatusCode: i.Response.Code,
+		}
+
+		if rec, ok := c.mapping[i.ID]; ok {
+			planned.Provenance, planned.Remove = c.core.ProvenanceOf(rec.id)
+			planned.Rewrite = rec.modified && !planned.Remove
+		}
+
+		result.Interactions = append(result.Interactions, planned)
+	}
+
+	return result, nil
+}
+
+// copyInteraction returns a copy of the interaction that can be modified without affecting the original.
+func copyInteraction(i *cassette.Interaction) *cassette.Interaction {
+	result := *i
+	result.Request.Headers = i.Request.Headers.Clone()
+	result.Response.Headers = i.Response.Headers.Clone()
+
+	return &result
+}
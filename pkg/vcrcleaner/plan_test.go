@@ -0,0 +1,116 @@
+package vcrcleaner
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+)
+
+func TestPlan_GivenRecording_MatchesCleanCassette(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	log := slogt.New(t)
+	fp := filepath.Join("testdata", "Test_SQL_Server_FailoverGroup_CRUD")
+
+	planned, err := cassette.Load(fp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	plan, err := New(log, ReduceAzureLongRunningOperationPolling()).Plan(t.Context(), planned)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cleaned, err := cassette.Load(fp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	_, err = New(log, ReduceAzureLongRunningOperationPolling()).CleanCassette(t.Context(), cleaned)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(plan.Interactions).To(HaveLen(len(cleaned.Interactions)))
+	g.Expect(plan.Removals()).To(BeNumerically(">", 0))
+
+	for index, step := range plan.Interactions {
+		g.Expect(step.ID).To(Equal(cleaned.Interactions[index].ID))
+		g.Expect(step.Remove).To(Equal(cleaned.Interactions[index].DiscardOnSave))
+
+		if step.Remove {
+			g.Expect(step.Provenance.Analyzer).To(Equal("azure.MonitorAzureLongRunningOperation"))
+			g.Expect(step.Provenance.Strategy).To(Equal(StrategyAzureLongRunningOperation))
+		} else {
+			g.Expect(step.Provenance).To(BeZero())
+		}
+	}
+}
+
+func TestPlan_GivenRecording_LeavesCassetteUntouched(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	log := slogt.New(t)
+	fp := filepath.Join("testdata", "Test_Apimanagement_v1api20220801_CreationAndDeletion")
+
+	cas, err := cassette.Load(fp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	original, err := cassette.Load(fp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	_, err = New(log, ReduceAzureAsynchronousOperationPolling()).Plan(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	for index, i := range cas.Interactions {
+		g.Expect(i.DiscardOnSave).To(BeFalse())
+		g.Expect(i.Response.Headers).To(Equal(original.Interactions[index].Response.Headers))
+	}
+}
+
+func TestPlanFile_GivenNonCassette_ReturnsEmptyPlan(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	log := slogt.New(t)
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+
+	plan, err := New(log, ReduceDeleteMonitoring()).PlanFile(t.Context(), path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(plan.Path).To(Equal(path))
+	g.Expect(plan.Interactions).To(BeEmpty())
+}
+
+func TestPlan_WithStrategyRetention_RetainsMoreInteractions(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	log := slogt.New(t)
+	fp := filepath.Join("testdata", "Test_SQL_Server_FailoverGroup_CRUD")
+
+	cas, err := cassette.Load(fp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	byDefault, err := New(log, ReduceAzureLongRunningOperationPolling()).Plan(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	policy, err := ParseRetentionPolicy("first:2,last:2")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// Options may be given in either order
+	retaining, err := New(
+		log,
+		WithStrategyRetention(StrategyAzureLongRunningOperation, policy),
+		ReduceAzureLongRunningOperationPolling(),
+	).Plan(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	unaffected, err := New(
+		log,
+		ReduceAzureLongRunningOperationPolling(),
+		WithStrategyRetention(StrategyDeletion, policy),
+	).Plan(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(retaining.Removals()).To(BeNumerically("<", byDefault.Removals()))
+	g.Expect(unaffected.Removals()).To(Equal(byDefault.Removals()))
+}
@@ -0,0 +1,52 @@
+package vcrcleaner
+
+import (
+	"github.com/rotisserie/eris"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/rules"
+)
+
+// PollingRules is a validated set of declarative polling rules, ready for use with ReducePollingByRules.
+// Each rule describes the interaction that triggers polling (method, URL pattern and status), the shape of the
+// polling requests, a JSON field path, and the values of that field meaning "still waiting" and "done":
+//
+//	rules:
+//	  - name: widget-provisioning
+//	    trigger:
+//	      methods: [PUT]
+//	      url: ^https://widgets\.example\.com/
+//	      statuses: [201]
+//	    poll:
+//	      field: status.phase
+//	      waiting: [Pending, Provisioning]
+//	      done: [Ready]
+type PollingRules struct {
+	detectors []*rules.Detector
+}
+
+// LoadPollingRules loads declarative polling rules from the YAML file at the specified path.
+func LoadPollingRules(path string) (*PollingRules, error) {
+	set, err := rules.Load(path)
+	if err != nil {
+		return nil, eris.Wrap(err, "loading polling rules")
+	}
+
+	return newPollingRules(set), nil
+}
+
+// ParsePollingRules parses declarative polling rules from YAML content.
+func ParsePollingRules(content []byte) (*PollingRules, error) {
+	set, err := rules.Parse(content)
+	if err != nil {
+		return nil, eris.Wrap(err, "parsing polling rules")
+	}
+
+	return newPollingRules(set), nil
+}
+
+// newPollingRules wraps the detectors compiled from the rules in the set.
+func newPollingRules(set *rules.RuleSet) *PollingRules {
+	return &PollingRules{
+		detectors: set.Detectors(),
+	}
+}
@@ -0,0 +1,110 @@
+package vcrcleaner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+)
+
+// azureProvisioningRules describes Azure resource provisioning as a declarative polling rule.
+const azureProvisioningRules = `
+rules:
+  - name: azure-provisioning
+    trigger:
+      methods: [PUT, PATCH]
+      url: ^https://management\.azure\.com/
+    poll:
+      field: properties.provisioningState
+      waiting: [Creating, Updating]
+      done: [Succeeded]
+`
+
+func TestReducePollingByRules_GivenRecording_RemovesPolling(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	rules, err := ParsePollingRules([]byte(azureProvisioningRules))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cas, err := cassette.Load(filepath.Join("testdata", "Test_EventHub_Namespace_v20240101_CRUD"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	plan, err := New(slogt.New(t), ReducePollingByRules(rules)).Plan(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(plan.Removals()).To(BeNumerically(">", 0))
+
+	for _, step := range plan.Interactions {
+		if step.Remove {
+			g.Expect(step.Method).To(Equal("GET"))
+			g.Expect(step.Provenance.Analyzer).To(Equal("rules.Monitor"))
+			g.Expect(step.Provenance.Strategy).To(Equal(StrategyPollingRulePrefix + "azure-provisioning"))
+		}
+	}
+}
+
+func TestLoadPollingRules_GivenInvalidRule_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	err := os.WriteFile(path, []byte("rules:\n  - name: incomplete\n"), 0o600)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	_, err = LoadPollingRules(path)
+
+	g.Expect(err).To(MatchError(ContainSubstring("incomplete")))
+}
+
+// azureOperationRules describes polling of Azure long running operations as a declarative polling rule, overlapping
+// with ReduceAzureLongRunningOperationPolling.
+const azureOperationRules = `
+rules:
+  - name: azure-operation
+    trigger:
+      methods: [PUT, POST, DELETE]
+      url: ^https://management\.azure\.com/
+    poll:
+      header: Azure-Asyncoperation
+      field: status
+      waiting: [InProgress]
+      done: [Succeeded]
+`
+
+func TestReducePollingByRules_OverlappingLongRunningOperation_KeepsPollsRetainedByMonitor(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	rules, err := ParsePollingRules([]byte(azureOperationRules))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cas, err := cassette.Load(filepath.Join("testdata", "Test_SQL_Server_FailoverGroup_CRUD"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// The rule keeps only the first poll, leaving the monitor to keep the last
+	keepFirst := WithStrategyRetention(StrategyPollingRulePrefix+"azure-operation", RetentionPolicy{First: 1})
+
+	byRule, err := New(slogt.New(t), ReducePollingByRules(rules), keepFirst).Plan(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	byBoth, err := New(
+		slogt.New(t),
+		ReducePollingByRules(rules),
+		keepFirst,
+		ReduceAzureLongRunningOperationPolling(),
+	).Plan(t.Context(), cas)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// Polls kept by the monitor are retained, even though the rule excludes them
+	g.Expect(byBoth.Removals()).To(BeNumerically("<", byRule.Removals()))
+
+	for index, step := range byBoth.Interactions {
+		if step.Remove {
+			g.Expect(byRule.Interactions[index].Remove).To(BeTrue())
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package vcrcleaner
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// record tracks the outcome of analyzing a single interaction, without retaining the interaction itself.
+// This keeps the cost of tracking each interaction small, allowing very large cassettes to be streamed.
+type record struct {
+	id       uuid.UUID     // Identifier given to the interaction for analysis
+	modified bool          // True if an analyzer has changed the interaction, e.g. by rewriting a header
+	recorded time.Duration // Duration of the interaction as originally recorded, before any compression
+	duration time.Duration // Duration of the interaction after any compression
+	headers  http.Header   // Response headers after any rewriting; only captured for changed cassette interactions
+}
+
+// newRecord creates a record for an interaction with the specified recorded duration.
+func newRecord(recorded time.Duration) *record {
+	return &record{
+		id:       uuid.New(),
+		recorded: recorded,
+		duration: recorded,
+	}
+}
@@ -1,6 +1,7 @@
 package vcrcleaner
 
 import (
+	"net/http"
 	"net/url"
 
 	"github.com/theunrepentantgeek/go-vcr-tidy/internal/urltool"
@@ -27,6 +28,39 @@ func (r *vcrRequest) BaseURL() *url.URL {
 	return urltool.BaseURL(r.FullURL())
 }
 
+// Method returns the HTTP method of the request.
 func (r *vcrRequest) Method() string {
 	return r.parent.interaction.Request.Method
 }
+
+// Header returns the value of the specified request header.
+func (r *vcrRequest) Header(name string) (string, bool) {
+	headers := r.parent.interaction.Request.Headers
+	if headers == nil {
+		return "", false
+	}
+
+	key := http.CanonicalHeaderKey(name)
+
+	values, ok := headers[key]
+	if !ok || len(values) == 0 {
+		return "", false
+	}
+
+	return values[0], true
+}
+
+// Headers returns a copy of all the request headers.
+func (r *vcrRequest) Headers() http.Header {
+	headers := r.parent.interaction.Request.Headers
+	if headers == nil {
+		return make(http.Header)
+	}
+
+	return headers.Clone()
+}
+
+// Body returns the body of the request.
+func (r *vcrRequest) Body() []byte {
+	return []byte(r.parent.interaction.Request.Body)
+}
@@ -1,10 +1,16 @@
 package vcrcleaner
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/jsondoc"
+)
 
 // vcrResponse represents the response portion of a VCR interaction.
 type vcrResponse struct {
-	parent *vcrInteraction
+	parent   *vcrInteraction
+	document *jsondoc.Document // Lazily parsed JSON body, shared by all analyzers
 }
 
 // StatusCode returns the HTTP status code of the response.
@@ -38,7 +44,13 @@ func (r *vcrResponse) SetHeader(name string, value string) {
 	}
 
 	key := http.CanonicalHeaderKey(name)
+	if values, ok := headers[key]; ok && len(values) == 1 && values[0] == value {
+		// No change needed
+		return
+	}
+
 	headers[key] = []string{value}
+	r.parent.markModified()
 }
 
 // RemoveHeader removes the specified response header.
@@ -49,9 +61,56 @@ func (r *vcrResponse) RemoveHeader(name string) {
 	}
 
 	key := http.CanonicalHeaderKey(name)
+	if _, ok := headers[key]; !ok {
+		// No change needed
+		return
+	}
+
 	delete(headers, key)
+	r.parent.markModified()
 }
 
+// Body returns the body of the response.
 func (r *vcrResponse) Body() []byte {
 	return []byte(r.parent.interaction.Response.Body)
 }
+
+// Duration returns how long the server took to respond when the interaction was recorded.
+func (r *vcrResponse) Duration() time.Duration {
+	return r.parent.interaction.Response.Duration
+}
+
+// SetDuration changes the recorded duration of the response.
+func (r *vcrResponse) SetDuration(duration time.Duration) {
+	if r.parent.interaction.Response.Duration == duration {
+		// No change needed
+		return
+	}
+
+	r.parent.interaction.Response.Duration = duration
+	r.parent.markModified()
+}
+
+// Timestamp returns when the response was sent, taken from its Date header, if present.
+func (r *vcrResponse) Timestamp() (time.Time, bool) {
+	value, ok := r.Header("Date")
+	if !ok {
+		return time.Time{}, false
+	}
+
+	result, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return result, true
+}
+
+// JSON returns the body of the response parsed as JSON, parsing it on first use.
+func (r *vcrResponse) JSON() *jsondoc.Document {
+	if r.document == nil {
+		r.document = jsondoc.Parse(r.Body())
+	}
+
+	return r.document
+}
@@ -0,0 +1,110 @@
+package vcrcleaner
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/jsondoc"
+)
+
+func TestVCRResponseJSON_CalledRepeatedly_ParsesOnce(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	i := newVCRInteraction(&cassette.Interaction{
+		Response: cassette.Response{
+			Body: `{"properties":{"provisioningState":"Creating"}}`,
+		},
+	})
+
+	first := i.Response().JSON()
+	second := i.Response().JSON()
+
+	g.Expect(second).To(BeIdenticalTo(first))
+
+	state, ok := first.Text("properties.provisioningState")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(state).To(Equal("Creating"))
+}
+
+// BenchmarkVCRResponseJSON compares the lookups made when several strategies inspect the same poll, sharing one parsed
+// document against parsing the body for every lookup.
+func BenchmarkVCRResponseJSON(b *testing.B) {
+	body := `{
+		"id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.Sql/servers/sql",
+		"name": "sql",
+		"type": "Microsoft.Sql/servers",
+		"location": "westus2",
+		"tags": {"environment": "test", "owner": "go-vcr-tidy"},
+		"properties": {
+			"administratorLogin": "admin",
+			"version": "12.0",
+			"state": "Ready",
+			"fullyQualifiedDomainName": "sql.database.windows.net",
+			"publicNetworkAccess": "Enabled",
+			"provisioningState": "Creating"
+		}
+	}`
+
+	// One lookup per strategy examining a poll of an Azure resource
+	paths := []string{"status", "properties.provisioningState", "properties.provisioningState", "status"}
+
+	b.Run("ParsedOnce", func(b *testing.B) {
+		for b.Loop() {
+			i := newVCRInteraction(&cassette.Interaction{Response: cassette.Response{Body: body}})
+			for _, path := range paths {
+				i.Response().JSON().Text(path)
+			}
+		}
+	})
+
+	b.Run("ParsedPerLookup", func(b *testing.B) {
+		for b.Loop() {
+			i := newVCRInteraction(&cassette.Interaction{Response: cassette.Response{Body: body}})
+			for _, path := range paths {
+				jsondoc.Parse(i.Response().Body()).Text(path)
+			}
+		}
+	})
+}
+
+func TestVCRResponseTimestamp_WithDateHeader_ReturnsTime(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	i := newVCRInteraction(&cassette.Interaction{
+		Response: cassette.Response{
+			Headers: http.Header{
+				"Date": []string{"Tue, 04 Mar 2025 21:30:15 GMT"},
+			},
+		},
+	})
+
+	timestamp, ok := i.Response().Timestamp()
+
+	g.Expect(ok).To(BeTrue())
+	g.Expect(timestamp).To(BeTemporally("==", time.Date(2025, time.March, 4, 21, 30, 15, 0, time.UTC)))
+}
+
+func TestVCRResponseSetDuration_WithNewDuration_MarksInteractionModified(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	i := newVCRInteraction(&cassette.Interaction{
+		Response: cassette.Response{
+			Duration: 2 * time.Second,
+		},
+	})
+
+	i.Response().SetDuration(2 * time.Second)
+	g.Expect(i.record.modified).To(BeFalse())
+
+	i.Response().SetDuration(time.Second)
+	g.Expect(i.record.modified).To(BeTrue())
+	g.Expect(i.interaction.Response.Duration).To(Equal(time.Second))
+	g.Expect(i.record.recorded).To(Equal(2 * time.Second))
+}
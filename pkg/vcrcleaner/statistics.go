@@ -0,0 +1,55 @@
+package vcrcleaner
+
+import "time"
+
+// Statistics summarizes the effect of cleaning a cassette.
+type Statistics struct {
+	// Interactions is the number of interactions analyzed.
+	Interactions int
+	// Removed is the number of interactions selected for removal.
+	Removed int
+	// RemovedByStrategy is the number of interactions selected for removal, keyed by cleaning strategy.
+	RemovedByStrategy map[string]int
+	// Duration is the total recorded duration of the interactions analyzed, as originally recorded.
+	Duration time.Duration
+	// RemovedDuration is the total recorded duration of the interactions selected for removal.
+	RemovedDuration time.Duration
+	// RemovedDurationByStrategy is the total recorded duration of the interactions selected for removal, keyed by
+	// cleaning strategy.
+	RemovedDurationByStrategy map[string]time.Duration
+	// CompressedDuration is the reduction in recorded duration of retained interactions, from compressing durations.
+	CompressedDuration time.Duration
+}
+
+// RemainingDuration is the total recorded duration of the interactions retained, after any compression.
+// This is the time a replay simulating latency would spend waiting for responses.
+func (s *Statistics) RemainingDuration() time.Duration {
+	return s.Duration - s.RemovedDuration - s.CompressedDuration
+}
+
+// Statistics returns a summary of the interactions analyzed by the cleaner, and those selected for removal.
+func (c *Cleaner) Statistics() Statistics {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	result := Statistics{
+		Interactions:              len(c.mapping) + len(c.discarded),
+		RemovedByStrategy:         make(map[string]int),
+		RemovedDurationByStrategy: make(map[string]time.Duration),
+	}
+
+	for rec := range c.interactions() {
+		result.Duration += rec.recorded
+
+		if provenance, ok := c.core.ProvenanceOf(rec.id); ok {
+			result.Removed++
+			result.RemovedByStrategy[provenance.Strategy]++
+			result.RemovedDuration += rec.recorded
+			result.RemovedDurationByStrategy[provenance.Strategy] += rec.recorded
+		} else {
+			result.CompressedDuration += rec.recorded - rec.duration
+		}
+	}
+
+	return result
+}
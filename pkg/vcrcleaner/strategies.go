@@ -0,0 +1,224 @@
+package vcrcleaner
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/rotisserie/eris"
+)
+
+// Families of cleaning strategies.
+// Strategies in the generic family apply to any HTTP API; strategies in other families are specific to a particular
+// platform, and can also be selected as a group.
+const (
+	FamilyGeneric = "generic"
+	FamilyAzure   = "azure"
+)
+
+// Family describes a family of related cleaning strategies.
+type Family struct {
+	// Name identifies the family in flags and configuration, in kebab-case, e.g. FamilyAzure.
+	Name string
+	// DisplayName is the name of the family as shown to people, e.g. in help text ("Azure").
+	DisplayName string
+}
+
+// Strategy describes a cleaning strategy available for selection by name, e.g. from the go-vcr-tidy command line.
+type Strategy struct {
+	// Name is the name of the strategy, as reported in the Provenance of each removed interaction.
+	Name string
+	// Family groups related strategies, e.g. FamilyAzure.
+	Family string
+	// Flag selects the strategy within its family, in kebab-case (e.g. "deletes" for the --clean-deletes flag).
+	Flag string
+	// Description explains what the strategy cleans, as a sentence suitable for help text.
+	Description string
+	// New returns the option that adds the strategy to a Cleaner.
+	New func() Option
+}
+
+// RegisterStrategy makes a cleaning strategy available for selection by name through Strategies, alongside those
+// built in. Strategies are listed (and applied) in the order registered; call this from an init function.
+// Strategies from other packages provide their own analyzers through WithAnalyzer. The go-vcr-tidy command generates
+// flags for every registered strategy, so offers those from any package compiled into it.
+// Panics if the strategy is incomplete, or if its name, or its flag within its family, is already registered.
+func RegisterStrategy(strategy Strategy) {
+	if err := registeredStrategies.register(strategy); err != nil {
+		panic(err)
+	}
+}
+
+// Strategies returns the registered cleaning strategies, in the order they were registered.
+func Strategies() []Strategy {
+	return registeredStrategies.all()
+}
+
+// RegisterFamily gives a family of strategies the name used when displaying it; call this from an init function.
+// Panics if the family is incomplete, or already registered.
+func RegisterFamily(family Family) {
+	if err := registeredStrategies.registerFamily(family); err != nil {
+		panic(err)
+	}
+}
+
+// FamilyDisplayName returns the name of the specified family as shown to people, or the family itself if it has no
+// display name registered.
+func FamilyDisplayName(family string) string {
+	return registeredStrategies.displayName(family)
+}
+
+// registeredStrategies holds the strategies available for selection, starting with those built in.
+var registeredStrategies = newStrategyRegistry(
+	Strategy{
+		Name:        StrategyDeferredCreation,
+		Family:      FamilyGeneric,
+		Flag:        "deferred-creations",
+		Description: "Clean deferred creation interactions.",
+		New:         ReduceDeferredCreationMonitoring,
+	},
+	Strategy{
+		Name:        StrategyDeletion,
+		Family:      FamilyGeneric,
+		Flag:        "deletes",
+		Description: "Clean delete interactions.",
+		New:         ReduceDeleteMonitoring,
+	},
+	Strategy{
+		Name:        StrategyAzureLongRunningOperation,
+		Family:      FamilyAzure,
+		Flag:        "long-running-operations",
+		Description: "Clean Azure long-running operation interactions.",
+		New:         ReduceAzureLongRunningOperationPolling,
+	},
+	Strategy{
+		Name:        StrategyAzureResourceModification,
+		Family:      FamilyAzure,
+		Flag:        "resource-modifications",
+		Description: "Clean Azure resource modification (PUT/PATCH) monitoring interactions.",
+		New:         ReduceAzureResourceModificationMonitoring,
+	},
+	Strategy{
+		Name:        StrategyAzureResourceDeletion,
+		Family:      FamilyAzure,
+		Flag:        "resource-deletions",
+		Description: "Clean Azure resource deletion monitoring interactions.",
+		New:         ReduceAzureResourceDeletionMonitoring,
+	},
+	Strategy{
+		Name:        StrategyAzureAsynchronousOperation,
+		Family:      FamilyAzure,
+		Flag:        "asynchronous-operations",
+		Description: "Clean Azure asynchronous operation monitoring interactions.",
+		New:         ReduceAzureAsynchronousOperationMonitoring,
+	},
+).withFamilies(
+	Family{Name: FamilyGeneric, DisplayName: "generic"},
+	Family{Name: FamilyAzure, DisplayName: "Azure"},
+)
+
+// strategyRegistry is a set of strategies, kept in the order they were registered.
+type strategyRegistry struct {
+	padlock    sync.Mutex // Used to make concurrent registration safe
+	strategies []Strategy
+	families   map[string]string // Display names of families, keyed by family name
+}
+
+// newStrategyRegistry creates a registry containing the specified strategies, panicking if any are invalid.
+func newStrategyRegistry(strategies ...Strategy) *strategyRegistry {
+	result := &strategyRegistry{
+		families: make(map[string]string),
+	}
+
+	for _, s := range strategies {
+		if err := result.register(s); err != nil {
+			panic(err)
+		}
+	}
+
+	return result
+}
+
+// withFamilies adds the specified families to the registry, panicking if any are invalid.
+func (r *strategyRegistry) withFamilies(families ...Family) *strategyRegistry {
+	for _, f := range families {
+		if err := r.registerFamily(f); err != nil {
+			panic(err)
+		}
+	}
+
+	return r
+}
+
+// registerFamily adds the display name of a family to the registry, returning an error if it's incomplete or
+// already registered.
+func (r *strategyRegistry) registerFamily(family Family) error {
+	r.padlock.Lock()
+	defer r.padlock.Unlock()
+
+	if family.Name == "" || family.DisplayName == "" {
+		return eris.Errorf("registering family %q: name and display name are both required", family.Name)
+	}
+
+	if _, ok := r.families[family.Name]; ok {
+		return eris.Errorf("registering family %q: already registered", family.Name)
+	}
+
+	r.families[family.Name] = family.DisplayName
+
+	return nil
+}
+
+// displayName returns the display name of the specified family, or the family itself if it has none.
+func (r *strategyRegistry) displayName(family string) string {
+	r.padlock.Lock()
+	defer r.padlock.Unlock()
+
+	if name, ok := r.families[family]; ok {
+		return name
+	}
+
+	return family
+}
+
+// register adds a strategy to the registry, returning an error if it's incomplete or clashes with one already
+// registered.
+func (r *strategyRegistry) register(strategy Strategy) error {
+	r.padlock.Lock()
+	defer r.padlock.Unlock()
+
+	switch {
+	case strategy.Name == "" || strategy.Family == "" || strategy.Flag == "":
+		return eris.Errorf("registering strategy %q: name, family and flag are all required", strategy.Name)
+	case strategy.New == nil:
+		return eris.Errorf("registering strategy %q: constructor is required", strategy.Name)
+	case strategy.Flag == "all":
+		return eris.Errorf("registering strategy %q: flag \"all\" is reserved for selecting whole families", strategy.Name)
+	}
+
+	for _, s := range r.strategies {
+		if s.Name == strategy.Name {
+			return eris.Errorf("registering strategy %q: name already registered", strategy.Name)
+		}
+
+		if s.Family == strategy.Family && s.Flag == strategy.Flag {
+			return eris.Errorf(
+				"registering strategy %q: flag %q already used by strategy %q in family %q",
+				strategy.Name,
+				strategy.Flag,
+				s.Name,
+				s.Family)
+		}
+	}
+
+	r.strategies = append(r.strategies, strategy)
+
+	return nil
+}
+
+// all returns the registered strategies, in order.
+func (r *strategyRegistry) all() []Strategy {
+	r.padlock.Lock()
+	defer r.padlock.Unlock()
+
+	return slices.Clone(r.strategies)
+}
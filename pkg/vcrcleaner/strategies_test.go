@@ -0,0 +1,172 @@
+package vcrcleaner
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// widgetStrategy returns a valid strategy for registration in tests.
+func widgetStrategy() Strategy {
+	return Strategy{
+		Name:        "widget-polling",
+		Family:      "widgets",
+		Flag:        "polling",
+		Description: "Clean widget polling interactions.",
+		New:         ReduceDeleteMonitoring,
+	}
+}
+
+func TestStrategies_ListsBuiltInStrategiesInOrder(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	names := make([]string, 0, len(Strategies()))
+	for _, s := range Strategies() {
+		names = append(names, s.Name)
+	}
+
+	g.Expect(names).To(HaveExactElements(
+		StrategyDeferredCreation,
+		StrategyDeletion,
+		StrategyAzureLongRunningOperation,
+		StrategyAzureResourceModification,
+		StrategyAzureResourceDeletion,
+		StrategyAzureAsynchronousOperation))
+}
+
+func TestStrategyRegistry_Register_AppendsStrategy(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	registry := newStrategyRegistry(Strategy{
+		Name:   StrategyDeletion,
+		Family: FamilyGeneric,
+		Flag:   "deletes",
+		New:    ReduceDeleteMonitoring,
+	})
+
+	g.Expect(registry.register(widgetStrategy())).To(Succeed())
+
+	strategies := registry.all()
+	g.Expect(strategies).To(HaveLen(2))
+	g.Expect(strategies[1].Name).To(Equal("widget-polling"))
+}
+
+func TestStrategyRegistry_Register_RejectsInvalidStrategies(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		modify   func(s *Strategy)
+		expected string
+	}{
+		"MissingName": {
+			modify:   func(s *Strategy) { s.Name = "" },
+			expected: "name, family and flag are all required",
+		},
+		"MissingConstructor": {
+			modify:   func(s *Strategy) { s.New = nil },
+			expected: "constructor is required",
+		},
+		"ReservedFlag": {
+			modify:   func(s *Strategy) { s.Flag = "all" },
+			expected: "reserved",
+		},
+		"DuplicateName": {
+			modify:   func(s *Strategy) { s.Flag = "other" },
+			expected: "name already registered",
+		},
+		"DuplicateFlagInFamily": {
+			modify:   func(s *Strategy) { s.Name = "other" },
+			expected: `flag "polling" already used by strategy "widget-polling"`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewGomegaWithT(t)
+
+			registry := newStrategyRegistry(widgetStrategy())
+
+			strategy := widgetStrategy()
+			c.modify(&strategy)
+
+			err := registry.register(strategy)
+
+			g.Expect(err).To(MatchError(ContainSubstring(c.expected)))
+			g.Expect(registry.all()).To(HaveLen(1))
+		})
+	}
+}
+
+func TestStrategyRegistry_Register_AllowsSameFlagInAnotherFamily(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	registry := newStrategyRegistry(widgetStrategy())
+
+	strategy := widgetStrategy()
+	strategy.Name = "gadget-polling"
+	strategy.Family = "gadgets"
+
+	g.Expect(registry.register(strategy)).To(Succeed())
+}
+
+func TestRegisterStrategy_WithDuplicateName_Panics(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	strategy := widgetStrategy()
+	strategy.Name = StrategyDeletion
+
+	g.Expect(func() { RegisterStrategy(strategy) }).To(Panic())
+}
+
+func TestFamilyDisplayName_BuiltInFamily_ReturnsDisplayName(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	g.Expect(FamilyDisplayName(FamilyAzure)).To(Equal("Azure"))
+}
+
+func TestStrategyRegistry_DisplayName_UnregisteredFamily_ReturnsFamily(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	registry := newStrategyRegistry(widgetStrategy())
+
+	g.Expect(registry.displayName("widgets")).To(Equal("widgets"))
+}
+
+func TestStrategyRegistry_RegisterFamily_RejectsInvalidFamilies(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		family   Family
+		expected string
+	}{
+		"MissingDisplayName": {
+			family:   Family{Name: "widgets"},
+			expected: "name and display name are both required",
+		},
+		"Duplicate": {
+			family:   Family{Name: FamilyAzure, DisplayName: "Microsoft Azure"},
+			expected: "already registered",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewGomegaWithT(t)
+
+			registry := newStrategyRegistry().withFamilies(Family{Name: FamilyAzure, DisplayName: "Azure"})
+
+			err := registry.registerFamily(c.family)
+
+			g.Expect(err).To(MatchError(ContainSubstring(c.expected)))
+			g.Expect(registry.displayName(FamilyAzure)).To(Equal("Azure"))
+		})
+	}
+}
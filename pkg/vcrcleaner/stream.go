@@ -0,0 +1,185 @@
+package vcrcleaner
+
+import (
+	"context"
+	"os"
+
+	"github.com/rotisserie/eris"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/cassettefile"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/validation"
+)
+
+// streams returns true if the cassette at path should be streamed rather than loaded into memory.
+func (c *Cleaner) streams(path string) bool {
+	if !c.streaming {
+		return false
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		// Let the usual path report the problem
+		return false
+	}
+
+	if !cassettefile.CanStream(path) {
+		c.log.Debug("Cassette can't be streamed, loading it instead", "path", path)
+
+		return false
+	}
+
+	return true
+}
+
+// cleanStream cleans the YAML cassette at path in two passes, without loading it into memory.
+// The first pass analyzes each interaction in turn, keeping only a record of the outcome; the second rewrites the
+// cassette, omitting interactions selected for removal and applying any changes made by analyzers.
+// Returns true if the file was modified and saved, false if no changes were made.
+// If ctx is cancelled during either pass, the original cassette is left untouched.
+func (c *Cleaner) cleanStream(
+	ctx context.Context,
+	path string,
+) (bool, error) {
+	c.log.Info("Checking cassette", "path", path, "streaming", true)
+
+	modified, err := c.analyzeStream(ctx, path)
+	if err != nil {
+		return false, err
+	}
+
+	if !modified {
+		c.log.Log(ctx, LevelVerbose, "No change to cassette", "path", path)
+
+		return false, nil
+	}
+
+	retained, err := cassettefile.Rewrite(path, func(i *cassette.Interaction) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, eris.Wrap(err, "rewrite cancelled")
+		}
+
+		return c.rewriteStreamed(i)
+	})
+	if err != nil {
+		return false, eris.Wrapf(err, "saving cleaned cassette to %s", path)
+	}
+
+	c.log.Info("Modified cassette", "path", path, "interactions", retained)
+
+	return true, nil
+}
+
+// analyzeStream analyzes each interaction of the cassette at path in turn, then validates the outcome.
+// Returns true if any interactions were selected for removal or changed.
+func (c *Cleaner) analyzeStream(ctx context.Context, path string) (bool, error) {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	// Only a summary of each interaction is kept for validation, as the cassette may be too large to hold in memory
+	var steps []validation.Step
+
+	err := cassettefile.EachInteraction(path, func(i *cassette.Interaction) error {
+		steps = append(steps, validation.Step{
+			ID:      i.ID,
+			Method:  i.Request.Method,
+			URL:     i.Request.URL,
+			Headers: linkHeadersOf(i.Response.Headers),
+		})
+
+		return c.inspect(ctx, i)
+	})
+	if err != nil {
+		return false, eris.Wrapf(err, "cleaning cassette from %s", path)
+	}
+
+	if err := c.finish(ctx); err != nil {
+		return false, eris.Wrapf(err, "cleaning cassette from %s", path)
+	}
+
+	for index, s := range steps {
+		rec := c.mapping[s.ID]
+		if rec != nil && rec.modified {
+			s.Headers = rec.headers
+		}
+
+		steps[index] = c.step(s.ID, s.Method, s.URL, s.Headers, rec)
+	}
+
+	if err := c.validate(steps); err != nil {
+		return false, eris.Wrapf(err, "cleaning cassette from %s", path)
+	}
+
+	if c.core.InteractionsToRemove() > 0 {
+		return true, nil
+	}
+
+	for rec := range c.interactions() {
+		if rec.modified {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// rewriteStreamed decides whether a streamed interaction is retained, applying any changes made to it during analysis.
+func (c *Cleaner) rewriteStreamed(i *cassette.Interaction) (bool, error) {
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	rec, ok := c.mapping[i.ID]
+	if !ok {
+		// Not an interaction we know about; keep it unchanged
+		return true, nil
+	}
+
+	if c.excluded(i.ID, rec) {
+		return false, nil
+	}
+
+	applyChanges(i, rec)
+
+	return true, nil
+}
+
+// planStream plans how the YAML cassette at path would be cleaned, without loading it into memory.
+func (c *Cleaner) planStream(
+	ctx context.Context,
+	path string,
+) (*Plan, error) {
+	c.log.Info("Planning cassette", "path", path, "streaming", true)
+
+	if _, err := c.analyzeStream(ctx, path); err != nil {
+		return nil, err
+	}
+
+	c.padlock.Lock()
+	defer c.padlock.Unlock()
+
+	result := &Plan{
+		Path: path,
+	}
+
+	err := cassettefile.EachInteraction(path, func(i *cassette.Interaction) error {
+		planned := PlannedInteraction{
+			ID:         i.ID,
+			Method:     i.Request.Method,
+			URL:        i.Request.URL,
+			StatusCode: i.Response.Code,
+		}
+
+		if rec, ok := c.mapping[i.ID]; ok {
+			planned.Provenance, planned.Remove = c.core.ProvenanceOf(rec.id)
+			planned.Rewrite = rec.modified && !planned.Remove
+		}
+
+		result.Interactions = append(result.Interactions, planned)
+
+		return nil
+	})
+	if err != nil {
+		return nil, eris.Wrapf(err, "planning cassette from %s", path)
+	}
+
+	return result, nil
+}
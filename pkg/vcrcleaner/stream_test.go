@@ -0,0 +1,66 @@
+package vcrcleaner
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+)
+
+func TestCleanFile_WithStreaming_MatchesInMemoryCleaning(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	recording := "Test_Apimanagement_v1api20220801_CreationAndDeletion"
+	loaded := copyRecording(t, g, recording, "loaded.yaml")
+	streamed := copyRecording(t, g, recording, "streamed.yaml")
+
+	options := []Option{
+		ReduceAzureLongRunningOperationPolling(),
+		ReduceAzureAsynchronousOperationPolling(),
+		ZeroPollingDelays(),
+	}
+
+	inMemory := New(slogt.New(t), options...)
+	modified, err := inMemory.CleanFile(t.Context(), loaded)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	streaming := New(slogt.New(t), append(options, Streaming())...)
+	modified, err = streaming.CleanFile(t.Context(), streamed)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	expected, err := os.ReadFile(loaded)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	actual, err := os.ReadFile(streamed)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(actual)).To(Equal(string(expected)))
+	g.Expect(streaming.Statistics()).To(Equal(inMemory.Statistics()))
+}
+
+func TestPlanFile_WithStreaming_MatchesInMemoryPlan(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	path := copyRecording(t, g, "Test_EventHub_Namespace_v20240101_CRUD", "cassette.yaml")
+
+	original, err := os.ReadFile(path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	expected, err := New(slogt.New(t), ReduceAzureResourceModificationMonitoring()).PlanFile(t.Context(), path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	actual, err := New(slogt.New(t), ReduceAzureResourceModificationMonitoring(), Streaming()).PlanFile(t.Context(), path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(actual).To(Equal(expected))
+	g.Expect(actual.Removals()).To(BeNumerically(">", 0))
+
+	unchanged, err := os.ReadFile(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(unchanged).To(Equal(original))
+}
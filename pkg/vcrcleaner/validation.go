@@ -0,0 +1,89 @@
+package vcrcleaner
+
+import (
+	"net/http"
+
+	"github.com/rotisserie/eris"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/har"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/validation"
+)
+
+// validate checks the cleaned sequence of interactions for violations that indicate a bug in an analyzer.
+// Called after analysis but before any interactions are removed, so on failure the cassette can be left untouched.
+func (c *Cleaner) validate(steps []validation.Step) error {
+	err := validation.Check(steps)
+	if err != nil {
+		return eris.Wrap(err, "validating cleaned interactions")
+	}
+
+	return nil
+}
+
+// step summarizes an analyzed interaction for validation.
+// rec is the record of analysis for the interaction, if known.
+func (c *Cleaner) step(
+	id int,
+	method string,
+	url string,
+	headers http.Header,
+	rec *record,
+) validation.Step {
+	result := validation.Step{
+		ID:      id,
+		Method:  method,
+		URL:     url,
+		Headers: headers,
+	}
+
+	if rec == nil {
+		// Not an interaction we know about; it's retained unchanged
+		return result
+	}
+
+	result.Provenance, result.Removed = c.core.ProvenanceOf(rec.id)
+	if result.Removed {
+		result.Retention = c.core.RetentionOf(result.Provenance.Strategy)
+	}
+
+	return result
+}
+
+// cassetteSteps summarizes the analyzed interactions of a cassette for validation.
+func (c *Cleaner) cassetteSteps(interactions []*cassette.Interaction) []validation.Step {
+	result := make([]validation.Step, 0, len(interactions))
+	for _, i := range interactions {
+		result = append(result, c.step(i.ID, i.Request.Method, i.Request.URL, i.Response.Headers, c.mapping[i.ID]))
+	}
+
+	return result
+}
+
+// linkHeadersOf returns a copy of just those response headers examined by validation, so that summaries of streamed
+// interactions stay small.
+func linkHeadersOf(headers http.Header) http.Header {
+	result := make(http.Header)
+	for _, name := range validation.LinkHeaders {
+		if values := headers.Values(name); len(values) > 0 {
+			result[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+
+	return result
+}
+
+// harSteps summarizes the analyzed entries of an HTTP Archive for validation.
+func (c *Cleaner) harSteps(entries []*har.Entry) []validation.Step {
+	result := make([]validation.Step, 0, len(entries))
+	for index, entry := range entries {
+		headers := make(http.Header, len(entry.Response.Headers))
+		for _, header := range entry.Response.Headers {
+			headers.Add(header.Name, header.Value)
+		}
+
+		result = append(result, c.step(index, entry.Request.Method, entry.Request.URL, headers, c.mapping[index]))
+	}
+
+	return result
+}
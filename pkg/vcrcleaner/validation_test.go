@@ -0,0 +1,139 @@
+package vcrcleaner
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/analyzer"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/interaction"
+)
+
+// excludeTriggers is a deliberately buggy analyzer that removes every PUT it sees.
+type excludeTriggers struct{}
+
+func (excludeTriggers) Analyze(_ context.Context, _ *slog.Logger, i interaction.Interface) (analyzer.Result, error) {
+	if !interaction.HasMethod(i, http.MethodPut) {
+		return analyzer.Result{}, nil
+	}
+
+	return analyzer.Result{
+		Excluded:   []interaction.Interface{i},
+		Provenance: analyzer.Because(i.Request().FullURL().String(), "buggy"),
+	}, nil
+}
+
+func TestCleanFile_AnalyzerRemovesTrigger_LeavesCassetteUntouched(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		options []Option
+	}{
+		"InMemory":  {},
+		"Streaming": {options: []Option{Streaming()}},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewGomegaWithT(t)
+
+			path := copyRecording(t, g, "Test_EventHub_Namespace_v20240101_CRUD", "cassette.yaml")
+			original, err := os.ReadFile(path)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			options := append([]Option{ReduceAzureResourceModificationMonitoring()}, c.options...)
+			cleaner := New(slogt.New(t), options...)
+			cleaner.core.AddStrategy("buggy", excludeTriggers{})
+
+			modified, err := cleaner.CleanFile(t.Context(), path)
+			g.Expect(err).To(MatchError(And(
+				ContainSubstring("failed validation"),
+				ContainSubstring("trigger PUT"),
+				ContainSubstring("strategy buggy"))))
+			g.Expect(modified).To(BeFalse())
+
+			content, err := os.ReadFile(path)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(content).To(Equal(original))
+		})
+	}
+}
+
+func TestCleanCassette_AnalyzerRemovesTrigger_MarksNothing(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	cas := unconfirmedDeletion()
+	cas.Interactions[0].Request.Method = http.MethodPut
+
+	cleaner := New(slogt.New(t), ReduceDeleteMonitoring())
+	cleaner.core.AddStrategy("buggy", excludeTriggers{})
+
+	_, err := cleaner.CleanCassette(t.Context(), cas)
+	g.Expect(err).To(MatchError(ContainSubstring("trigger PUT")))
+
+	for _, i := range cas.Interactions {
+		g.Expect(i.DiscardOnSave).To(BeFalse())
+	}
+}
+
+// unconfirmedDeletionThenPut is an unconfirmed deletion whose polls carry delay hints, followed by a PUT that
+// excludeTriggers removes.
+func unconfirmedDeletionThenPut() *cassette.Cassette {
+	cas := unconfirmedDeletion()
+	for _, i := range cas.Interactions[1:] {
+		i.Response.Headers = http.Header{"Retry-After": []string{"10"}}
+	}
+
+	cas.AddInteraction(&cassette.Interaction{
+		Request:  cassette.Request{Method: http.MethodPut, URL: "https://api.example.com/widgets/2"},
+		Response: cassette.Response{Code: http.StatusCreated},
+	})
+
+	return cas
+}
+
+func TestCleanCassette_AnalyzerRemovesTrigger_LeavesHeadersUnchanged(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	cas := unconfirmedDeletionThenPut()
+
+	cleaner := New(slogt.New(t), ReduceDeleteMonitoring(), ZeroPollingDelays())
+	cleaner.core.AddStrategy("buggy", excludeTriggers{})
+
+	_, err := cleaner.CleanCassette(t.Context(), cas)
+	g.Expect(err).To(MatchError(ContainSubstring("trigger PUT")))
+
+	for _, i := range cas.Interactions[1:5] {
+		g.Expect(i.Response.Headers.Get("Retry-After")).To(Equal("10"), "interaction %d", i.ID)
+		g.Expect(i.DiscardOnSave).To(BeFalse())
+	}
+}
+
+func TestRecorderHooks_AnalyzerRemovesTrigger_ReturnsErrorWithoutMarking(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	cas := unconfirmedDeletionThenPut()
+
+	cleaner := New(slogt.New(t), ReduceDeleteMonitoring())
+	cleaner.core.AddStrategy("buggy", excludeTriggers{})
+
+	for _, i := range cas.Interactions {
+		g.Expect(cleaner.AfterCaptureHook(i)).To(Succeed())
+	}
+
+	for _, i := range cas.Interactions {
+		g.Expect(cleaner.BeforeSaveHook(i)).To(MatchError(ContainSubstring("trigger PUT")))
+		g.Expect(i.DiscardOnSave).To(BeFalse(), "interaction %d", i.ID)
+	}
+}
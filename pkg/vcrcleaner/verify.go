@@ -0,0 +1,377 @@
+package vcrcleaner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rotisserie/eris"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/recorder"
+
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/azure"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/cassettefile"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/har"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/jsondoc"
+	"github.com/theunrepentantgeek/go-vcr-tidy/internal/urltool"
+)
+
+// Verification describes the outcome of replaying the requests of an original recording against a cleaned one.
+type Verification struct {
+	// Requests is the number of requests replayed against the cleaned recording.
+	Requests int
+	// Skipped is the number of requests not replayed, because the replay had already moved past them (e.g. once a
+	// poll found an operation complete, the intermediate polls that followed in the original are no longer made).
+	Skipped int
+	// Mismatches lists the requests that didn't replay as they were recorded.
+	Mismatches []Mismatch
+}
+
+// Mismatch describes a request from the original recording that didn't replay as recorded.
+type Mismatch struct {
+	// ID is the ID of the interaction within the original recording.
+	ID int
+	// Method is the HTTP method of the request.
+	Method string
+	// URL is the URL requested during replay.
+	URL string
+	// Reason is a human-readable explanation of the mismatch.
+	Reason string
+}
+
+// Passed returns true if every request replayed as recorded.
+func (v *Verification) Passed() bool {
+	return len(v.Mismatches) == 0
+}
+
+// VerifyFiles loads the original and cleaned recordings from the specified paths and verifies that the cleaned one
+// replays the requests of the original. Cassettes in any supported format, and HTTP Archives, can be verified.
+// See Verify for details.
+func VerifyFiles(
+	ctx context.Context,
+	log *slog.Logger,
+	originalPath string,
+	cleanedPath string,
+) (*Verification, error) {
+	original, err := loadRecording(originalPath)
+	if err != nil {
+		return nil, eris.Wrap(err, "loading original recording")
+	}
+
+	cleaned, err := loadRecording(cleanedPath)
+	if err != nil {
+		return nil, eris.Wrap(err, "loading cleaned recording")
+	}
+
+	return Verify(ctx, log, original, cleaned)
+}
+
+// Verify replays the requests of the original cassette, in order, through a go-vcr recorder in replay-only mode
+// backed by the cleaned cassette, entirely offline. Requests are matched on method, URL and body.
+// Clients are emulated the way a poller behaves: when a poll finds an operation or resource has already reached a
+// later state, the polls that followed in the original are skipped until the original reaches that state too. Where
+// the original client followed the Location header returned by a poll, the replay follows the header returned by the
+// cleaned cassette instead.
+// Any request that no longer finds a match is reported, as is any polling sequence that ends in a different state
+// (status code, operation status or provisioning state) than it did originally.
+// Interactions of the cleaned cassette marked DiscardOnSave are ignored.
+// Returns an error if ctx is cancelled before every request has been replayed.
+func Verify(
+	ctx context.Context,
+	log *slog.Logger,
+	original *cassette.Cassette,
+	cleaned *cassette.Cassette,
+) (*Verification, error) {
+	fs, err := cassettefile.ReplayFS(cleaned)
+	if err != nil {
+		return nil, eris.Wrap(err, "preparing cleaned cassette for replay")
+	}
+
+	rec, err := recorder.New(
+		"cleaned",
+		recorder.WithMode(recorder.ModeReplayOnly),
+		recorder.WithFS(fs),
+		recorder.WithMatcher(replayMatcher),
+		recorder.WithSkipRequestLatency(true),
+	)
+	if err != nil {
+		return nil, eris.Wrap(err, "creating replay recorder")
+	}
+
+	v := &verifier{
+		log:       log,
+		recorder:  rec,
+		runs:      make(map[string]*replayRun),
+		original:  original.Interactions,
+		nextInRun: nextInRuns(original.Interactions),
+		result:    &Verification{},
+	}
+
+	for index, i := range original.Interactions {
+		if err := ctx.Err(); err != nil {
+			return nil, eris.Wrapf(err, "verification cancelled before interaction %d", i.ID)
+		}
+
+		if err := v.replay(ctx, index, i); err != nil {
+			return nil, eris.Wrapf(err, "replaying interaction %d", i.ID)
+		}
+	}
+
+	return v.result, nil
+}
+
+// verifier tracks the progress of replaying an original recording.
+type verifier struct {
+	log       *slog.Logger
+	recorder  *recorder.Recorder
+	original  []*cassette.Interaction // Interactions of the original recording
+	runs      map[string]*replayRun   // Progress of the current run of requests to each base URL
+	nextInRun []int                   // Index of the next interaction in the same run as each original, or -1
+	result    *Verification
+}
+
+// replayRun tracks the progress of replaying a run of repeated requests to the same base URL with the same method.
+type replayRun struct {
+	method string // Method of the requests in the run
+	ahead  string // State reached by the replay ahead of the original, if any
+	next   string // URL to request next, if the last response directed the client elsewhere
+}
+
+// replay replays a single interaction of the original recording.
+// index is the position of the interaction within the original recording.
+func (v *verifier) replay(ctx context.Context, index int, i *cassette.Interaction) error {
+	req, err := i.GetHTTPRequest()
+	if err != nil {
+		return eris.Wrap(err, "creating request")
+	}
+
+	req = req.WithContext(ctx)
+
+	key := baseURLOf(i.Request.URL)
+
+	run, ok := v.runs[key]
+	if !ok || run.method != i.Request.Method {
+		run = &replayRun{method: i.Request.Method}
+		v.runs[key] = run
+	}
+
+	expected := stateOf(i.Response.Code, i.Response.Body)
+
+	if run.ahead != "" {
+		v.skip(index, i, run, expected)
+
+		return nil
+	}
+
+	if run.next != "" {
+		if next, err := url.Parse(run.next); err == nil {
+			req.URL = next
+		}
+	}
+
+	v.result.Requests++
+
+	resp, err := v.recorder.RoundTrip(req)
+	if errors.Is(err, cassette.ErrInteractionNotFound) {
+		v.mismatch(i, req.URL.String(), "no matching interaction in the cleaned cassette")
+
+		return nil
+	} else if err != nil {
+		return eris.Wrap(err, "replaying request")
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return eris.Wrap(err, "reading replayed response")
+	}
+
+	run.next = ""
+	if v.followsLocation(index) {
+		run.next = followed(req.URL, resp.Header)
+	}
+
+	actual := stateOf(resp.StatusCode, string(body))
+	switch {
+	case actual == expected:
+		// Replayed as recorded
+	case v.nextInRun[index] < 0:
+		v.mismatch(i, req.URL.String(), "expected terminal state "+expected+", but replay found "+actual)
+	default:
+		// The replay has moved ahead of the original; skip its requests until it catches up
+		v.log.Debug(
+			"Replay ahead of original",
+			"id", i.ID,
+			"url", req.URL.String(),
+			"expected", expected,
+			"actual", actual,
+		)
+
+		run.ahead = actual
+	}
+
+	return nil
+}
+
+// skip handles an interaction of the original recording made redundant by a replay that has already moved ahead.
+// expected is the state of the original response.
+func (v *verifier) skip(index int, i *cassette.Interaction, run *replayRun, expected string) {
+	v.result.Skipped++
+
+	switch {
+	case expected == run.ahead:
+		// The original has caught up with the replay
+		run.ahead = ""
+	case v.nextInRun[index] < 0:
+		// The original never reached the state found by the replay
+		v.mismatch(i, i.Request.URL, "expected terminal state "+expected+", but replay found "+run.ahead)
+		run.ahead = ""
+	}
+}
+
+// mismatch records a request that didn't replay as recorded.
+func (v *verifier) mismatch(i *cassette.Interaction, u string, reason string) {
+	v.log.Debug("Replay mismatch", "id", i.ID, "method", i.Request.Method, "url", u, "reason", reason)
+
+	v.result.Mismatches = append(v.result.Mismatches, Mismatch{
+		ID:     i.ID,
+		Method: i.Request.Method,
+		URL:    u,
+		Reason: reason,
+	})
+}
+
+// replayMatcher matches requests on method, URL and body, ignoring headers (such as request IDs) that differ
+// between otherwise identical polls.
+func replayMatcher(r *http.Request, i cassette.Request) bool {
+	if r.Method != i.Method || r.URL.String() != i.URL {
+		return false
+	}
+
+	if r.Body == nil || r.Body == http.NoBody {
+		return i.Body == ""
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return false
+	}
+
+	r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+	return string(body) == i.Body
+}
+
+// stateOf summarizes the state reported by a response: its status code, along with any operation status and
+// provisioning state found in the body.
+func stateOf(code int, body string) string {
+	parts := []string{strconv.Itoa(code)}
+
+	document := jsondoc.Parse([]byte(body))
+	for _, path := range []string{azure.OperationStatusPath, azure.ProvisioningStatePath} {
+		if value, ok := document.Text(path); ok {
+			parts = append(parts, value)
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// followsLocation returns true if, in the original recording, the client followed the Location header returned for
+// the interaction at index, making its next request in the same run to that URL.
+func (v *verifier) followsLocation(index int) bool {
+	next := v.nextInRun[index]
+	if next < 0 {
+		return false
+	}
+
+	i := v.original[index]
+	location := i.Response.Headers.Get("Location")
+	if location == "" {
+		return false
+	}
+
+	u, err := url.Parse(i.Request.URL)
+	if err != nil {
+		return false
+	}
+
+	target, err := u.Parse(location)
+
+	return err == nil && target.String() == v.original[next].Request.URL
+}
+
+// followed returns the URL a client polling requestURL would request next, if the response redirects it to another
+// URL with the same base, or "" if the client would repeat the request as recorded.
+func followed(requestURL *url.URL, headers http.Header) string {
+	location := headers.Get("Location")
+	if location == "" {
+		return ""
+	}
+
+	target, err := requestURL.Parse(location)
+	if err != nil || !urltool.SameBaseURL(requestURL, target) {
+		return ""
+	}
+
+	return target.String()
+}
+
+// nextInRuns returns, for each interaction, the index of the next interaction in the same run of repeated requests
+// (the next request to the same base URL, if it uses the same method), or -1 if the interaction ends its run.
+func nextInRuns(interactions []*cassette.Interaction) []int {
+	result := make([]int, len(interactions))
+
+	// next tracks the index of the next request to each base URL, working backwards
+	next := make(map[string]int)
+
+	for index := len(interactions) - 1; index >= 0; index-- {
+		i := interactions[index]
+		key := baseURLOf(i.Request.URL)
+
+		result[index] = -1
+		if n, ok := next[key]; ok && interactions[n].Request.Method == i.Request.Method {
+			result[index] = n
+		}
+
+		next[key] = index
+	}
+
+	return result
+}
+
+// baseURLOf returns the base URL of rawURL, or rawURL itself if it can't be parsed.
+func baseURLOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	return urltool.BaseURL(u).String()
+}
+
+// loadRecording loads the recording at path as a cassette, converting it from an HTTP Archive if needed.
+func loadRecording(path string) (*cassette.Cassette, error) {
+	if har.IsHAR(path) {
+		archive, err := har.Load(path)
+		if err != nil {
+			return nil, eris.Wrapf(err, "loading HTTP archive %s", path)
+		}
+
+		return archive.ToCassette(strings.TrimSuffix(path, filepath.Ext(path))), nil
+	}
+
+	file, err := cassettefile.Load(path)
+	if err != nil {
+		return nil, eris.Wrapf(err, "loading cassette %s", path)
+	}
+
+	return file.Cassette, nil
+}
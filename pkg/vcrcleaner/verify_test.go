@@ -0,0 +1,176 @@
+package vcrcleaner
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/neilotoole/slogt"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+)
+
+const widgetURL = "https://api.example.com/widgets/1"
+
+// exchange creates an interaction for a request to u, returning a response with the specified code and body.
+// location, if not empty, is returned in a Location header.
+func exchange(method string, u string, code int, body string, location string) *cassette.Interaction {
+	headers := http.Header{}
+	if location != "" {
+		headers.Set("Location", location)
+	}
+
+	return &cassette.Interaction{
+		Request: cassette.Request{
+			Method: method,
+			URL:    u,
+		},
+		Response: cassette.Response{
+			Code:    code,
+			Body:    body,
+			Headers: headers,
+		},
+	}
+}
+
+// cassetteOf creates an in-memory cassette containing the specified interactions, numbered in order.
+func cassetteOf(interactions ...*cassette.Interaction) *cassette.Cassette {
+	result := cassette.New("verify")
+	for _, i := range interactions {
+		result.AddInteraction(i)
+	}
+
+	return result
+}
+
+// provisioning returns a sequence where a PUT is followed by polls of the resource until it's provisioned.
+func provisioning(terminalState string) []*cassette.Interaction {
+	return []*cassette.Interaction{
+		exchange(http.MethodPut, widgetURL, 201, `{"properties":{"provisioningState":"Creating"}}`, ""),
+		exchange(http.MethodGet, widgetURL, 200, `{"properties":{"provisioningState":"Creating"}}`, ""),
+		exchange(http.MethodGet, widgetURL, 200, `{"properties":{"provisioningState":"Creating"}}`, ""),
+		exchange(http.MethodGet, widgetURL, 200, `{"properties":{"provisioningState":"Creating"}}`, ""),
+		exchange(http.MethodGet, widgetURL, 200, `{"properties":{"provisioningState":"`+terminalState+`"}}`, ""),
+	}
+}
+
+func TestVerify_CleanedWithLongRunningOperationPolling_ReplaysAsRecorded(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	log := slogt.New(t)
+	fp := filepath.Join("testdata", "Test_Sql_v1api20211101_CreationAndDeletion")
+
+	original, err := cassette.Load(fp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cleaned, err := cassette.Load(fp)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cleaner := New(log, ReduceAzureLongRunningOperationPolling(), ReduceAzureAsynchronousOperationPolling())
+	modified, err := cleaner.CleanCassette(t.Context(), cleaned)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(modified).To(BeTrue())
+
+	verification, err := Verify(t.Context(), log, original, cleaned)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(verification.Mismatches).To(BeEmpty())
+	g.Expect(verification.Passed()).To(BeTrue())
+	g.Expect(verification.Skipped).To(Equal(cleaner.Statistics().Removed))
+	g.Expect(verification.Requests + verification.Skipped).To(Equal(len(original.Interactions)))
+}
+
+func TestVerify_IntermediatePollsRemoved_SkipsThem(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	original := cassetteOf(provisioning("Succeeded")...)
+
+	sequence := provisioning("Succeeded")
+	cleaned := cassetteOf(sequence[0], sequence[1], sequence[3], sequence[4])
+
+	verification, err := Verify(t.Context(), slogt.New(t), original, cleaned)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(verification.Passed()).To(BeTrue())
+	g.Expect(verification.Requests).To(Equal(4))
+	g.Expect(verification.Skipped).To(Equal(1))
+}
+
+func TestVerify_DifferentTerminalState_ReportsMismatch(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	original := cassetteOf(provisioning("Succeeded")...)
+
+	sequence := provisioning("Failed")
+	cleaned := cassetteOf(sequence[0], sequence[1], sequence[4])
+
+	verification, err := Verify(t.Context(), slogt.New(t), original, cleaned)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(verification.Passed()).To(BeFalse())
+	g.Expect(verification.Mismatches).To(ConsistOf(Mismatch{
+		ID:     4,
+		Method: http.MethodGet,
+		URL:    widgetURL,
+		Reason: "expected terminal state 200/Succeeded, but replay found 200/Failed",
+	}))
+}
+
+func TestVerify_RequestRemoved_ReportsMismatch(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	original := cassetteOf(provisioning("Succeeded")...)
+
+	sequence := provisioning("Succeeded")
+	cleaned := cassetteOf(sequence[1], sequence[4])
+
+	verification, err := Verify(t.Context(), slogt.New(t), original, cleaned)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(verification.Mismatches).To(ConsistOf(Mismatch{
+		ID:     0,
+		Method: http.MethodPut,
+		URL:    widgetURL,
+		Reason: "no matching interaction in the cleaned cassette",
+	}))
+}
+
+func TestVerify_ClientFollowedLocation_FollowsRelinkedLocation(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	const operationURL = "https://api.example.com/operations/7"
+
+	original := cassetteOf(
+		exchange(http.MethodPut, widgetURL, 202, "", operationURL+"?t=1"),
+		exchange(http.MethodGet, operationURL+"?t=1", 202, "", operationURL+"?t=2"),
+		exchange(http.MethodGet, operationURL+"?t=2", 202, "", operationURL+"?t=3"),
+		exchange(http.MethodGet, operationURL+"?t=3", 200, "", ""))
+
+	// Cleaning removed the second poll, relinking the first to the last
+	cleaned := cassetteOf(
+		exchange(http.MethodPut, widgetURL, 202, "", operationURL+"?t=1"),
+		exchange(http.MethodGet, operationURL+"?t=1", 202, "", operationURL+"?t=3"),
+		exchange(http.MethodGet, operationURL+"?t=3", 200, "", ""))
+
+	verification, err := Verify(t.Context(), slogt.New(t), original, cleaned)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(verification.Mismatches).To(BeEmpty())
+	g.Expect(verification.Skipped).To(Equal(1))
+}
+
+func TestVerify_Cancelled_ReturnsError(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	original := cassetteOf(provisioning("Succeeded")...)
+	cleaned := cassetteOf(provisioning("Succeeded")...)
+
+	_, err := Verify(ctx, slogt.New(t), original, cleaned)
+	g.Expect(err).To(MatchError(context.Canceled))
+}